@@ -17,9 +17,14 @@ import (
 
 	"github.com/joho/godotenv"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/config_access"
+	dbaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/db_access"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/jwt_access"
+	mtlsaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/mtls_access"
+	oidcaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/oidc_access"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/db"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	managementsecurity "github.com/router-for-me/CLIProxyAPI/v6/internal/security"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
@@ -28,6 +33,7 @@ import (
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers/openai"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	log "github.com/sirupsen/logrus"
@@ -246,6 +252,29 @@ func main() {
 			return
 		}
 		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+		if responseStore, errResponseStore := store.NewPostgresResponseStore(ctx, pgStoreInst.GetRepo(), 0); errResponseStore != nil {
+			log.Warnf("postgres response store unavailable, keeping responses in memory: %v", errResponseStore)
+		} else {
+			openai.RegisterResponseStore(responseStore)
+		}
+		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+		if errKeys := pgStoreInst.GetRepo().Schema().EnsureAPIKeysTable(ctx); errKeys != nil {
+			log.Warnf("postgres client key store unavailable: %v", errKeys)
+		} else {
+			dbaccess.SetStore(pgStoreInst.GetRepo().Queries())
+		}
+		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+		if errUsage := pgStoreInst.GetRepo().Schema().EnsureUsageStatsTable(ctx); errUsage != nil {
+			log.Warnf("postgres usage persistence unavailable, keeping usage in memory only: %v", errUsage)
+		} else {
+			usagePlugin := db.RegisterUsagePlugin(pgStoreInst.GetRepo())
+			defer func() { _ = usagePlugin.Close() }()
+			usage.SetHistoryStore(pgStoreInst.GetRepo().Analytics())
+		}
+		cancel()
 		configFilePath = pgStoreInst.ConfigPath()
 		cfg, err = config.LoadConfigOptional(configFilePath, isCloudDeploy)
 		if err == nil {
@@ -404,6 +433,7 @@ func main() {
 		}
 	}
 	usage.SetStatisticsEnabled(cfg.UsageStatisticsEnabled)
+	usage.SetPricing(cfg.Pricing)
 	coreauth.SetQuotaCooldownDisabled(cfg.DisableCooling)
 
 	// Validate secrets at startup for security
@@ -447,6 +477,10 @@ func main() {
 
 	// Register built-in access providers before constructing services.
 	configaccess.Register()
+	dbaccess.Register()
+	jwtaccess.Register()
+	oidcaccess.Register()
+	mtlsaccess.Register()
 
 	// Handle different command modes based on the provided flags.
 
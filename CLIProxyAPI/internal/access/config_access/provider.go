@@ -21,20 +21,31 @@ func Register() {
 
 type provider struct {
 	name string
-	keys map[string]struct{}
+	// keys maps each accepted key to its policy; plain api-keys map to nil.
+	keys map[string]*sdkaccess.Policy
 }
 
-func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+func newProvider(cfg *sdkconfig.AccessProvider, root *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
 	name := cfg.Name
 	if name == "" {
 		name = sdkconfig.DefaultAccessProviderName
 	}
-	keys := make(map[string]struct{}, len(cfg.APIKeys))
+	keys := make(map[string]*sdkaccess.Policy, len(cfg.APIKeys))
 	for _, key := range cfg.APIKeys {
 		if key == "" {
 			continue
 		}
-		keys[key] = struct{}{}
+		keys[key] = nil
+	}
+	if root != nil {
+		for i := range root.ClientKeys {
+			clientKey := &root.ClientKeys[i]
+			key := strings.TrimSpace(clientKey.Key)
+			if _, ok := keys[key]; !ok {
+				continue
+			}
+			keys[key] = sdkaccess.PolicyFromClientKey(clientKey)
+		}
 	}
 	return &provider{name: name, keys: keys}, nil
 }
@@ -83,13 +94,18 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.
 		if candidate.value == "" {
 			continue
 		}
-		if _, ok := p.keys[candidate.value]; ok {
+		if policy, ok := p.keys[candidate.value]; ok {
+			metadata := map[string]string{
+				"source": candidate.source,
+			}
+			if policy != nil && policy.Name != "" {
+				metadata["name"] = policy.Name
+			}
 			return &sdkaccess.Result{
 				Provider:  p.Identifier(),
 				Principal: candidate.value,
-				Metadata: map[string]string{
-					"source": candidate.source,
-				},
+				Metadata:  metadata,
+				Policy:    policy,
 			}, nil
 		}
 	}
@@ -0,0 +1,212 @@
+// Package dbaccess provides the db-api-key access provider, which authenticates clients
+// with API keys stored hashed in the database api_keys table.
+package dbaccess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/db"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+const defaultCacheTTL = 30 * time.Second
+
+// KeyStore persists client API keys. *db.Queries implements it.
+type KeyStore interface {
+	ValidateAPIKey(ctx context.Context, plaintextKey string) (*db.APIKey, error)
+	InsertAPIKey(ctx context.Context, key *db.APIKey, plaintextKey string) error
+	ListAPIKeys(ctx context.Context) ([]*db.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	RotateAPIKey(ctx context.Context, id, plaintextKey string) (*db.APIKey, error)
+}
+
+var (
+	registerOnce sync.Once
+
+	storeMu sync.RWMutex
+	store   KeyStore
+
+	// cacheGeneration invalidates every provider cache when bumped.
+	cacheMu         sync.Mutex
+	cacheGeneration uint64
+)
+
+// Register ensures the db-api-key provider is available to the access manager.
+func Register() {
+	registerOnce.Do(func() {
+		sdkaccess.RegisterProvider(sdkconfig.AccessProviderTypeDBAPIKey, newProvider)
+	})
+}
+
+// SetStore installs the key store used by the provider and the management endpoints.
+func SetStore(s KeyStore) {
+	storeMu.Lock()
+	store = s
+	storeMu.Unlock()
+	InvalidateCache()
+}
+
+// Store returns the installed key store, or nil when none is configured.
+func Store() KeyStore {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// InvalidateCache drops every cached validation so revocations apply immediately in this process.
+func InvalidateCache() {
+	cacheMu.Lock()
+	cacheGeneration++
+	cacheMu.Unlock()
+}
+
+func currentGeneration() uint64 {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	return cacheGeneration
+}
+
+type provider struct {
+	name string
+	ttl  time.Duration
+
+	mu         sync.Mutex
+	generation uint64
+	cache      map[string]cachedKey
+}
+
+type cachedKey struct {
+	key       *db.APIKey
+	expiresAt time.Time
+}
+
+func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		name = sdkconfig.AccessProviderTypeDBAPIKey
+	}
+	ttl := defaultCacheTTL
+	if raw, ok := cfg.Config["cache-ttl-seconds"]; ok {
+		seconds, okInt := raw.(int)
+		if !okInt {
+			return nil, fmt.Errorf("db-api-key: cache-ttl-seconds must be an integer")
+		}
+		switch {
+		case seconds < 0:
+			ttl = 0
+		case seconds > 0:
+			ttl = time.Duration(seconds) * time.Second
+		}
+	}
+	return &provider{name: name, ttl: ttl, cache: make(map[string]cachedKey)}, nil
+}
+
+func (p *provider) Identifier() string {
+	if p == nil || p.name == "" {
+		return sdkconfig.AccessProviderTypeDBAPIKey
+	}
+	return p.name
+}
+
+func (p *provider) Authenticate(ctx context.Context, r *http.Request) (*sdkaccess.Result, error) {
+	keyStore := Store()
+	if p == nil || keyStore == nil {
+		return nil, sdkaccess.ErrNotHandled
+	}
+	candidate, source := credentialFromRequest(r)
+	if candidate == "" {
+		return nil, sdkaccess.ErrNoCredentials
+	}
+
+	key, err := p.lookup(ctx, keyStore, candidate)
+	switch {
+	case errors.Is(err, db.ErrAPIKeyNotFound):
+		return nil, sdkaccess.ErrInvalidCredential
+	case errors.Is(err, db.ErrAPIKeyExpired):
+		return nil, sdkaccess.ErrCredentialExpired
+	case err != nil:
+		return nil, fmt.Errorf("db-api-key: %w", err)
+	}
+
+	metadata := map[string]string{"source": source, "key_id": key.ID}
+	if key.Name != "" {
+		metadata["name"] = key.Name
+	}
+	policy := &sdkaccess.Policy{Name: key.Name, RequestsPerMinute: int(key.RateLimit)}
+	if key.ExpiresAt != nil {
+		policy.ExpiresAt = *key.ExpiresAt
+	}
+	return &sdkaccess.Result{
+		Provider:  p.Identifier(),
+		Principal: candidate,
+		Metadata:  metadata,
+		Policy:    policy,
+	}, nil
+}
+
+// lookup validates candidate against the store, serving recent results from the cache.
+func (p *provider) lookup(ctx context.Context, keyStore KeyStore, candidate string) (*db.APIKey, error) {
+	hash, _ := db.HashAPIKey(candidate)
+	now := time.Now()
+	generation := currentGeneration()
+
+	p.mu.Lock()
+	if p.generation != generation {
+		p.cache = make(map[string]cachedKey)
+		p.generation = generation
+	}
+	if entry, ok := p.cache[hash]; ok && now.Before(entry.expiresAt) {
+		p.mu.Unlock()
+		return entry.key, nil
+	}
+	p.mu.Unlock()
+
+	key, err := keyStore.ValidateAPIKey(ctx, candidate)
+	if err != nil {
+		return nil, err
+	}
+	if p.ttl > 0 {
+		p.mu.Lock()
+		if p.generation == generation {
+			p.cache[hash] = cachedKey{key: key, expiresAt: now.Add(p.ttl)}
+		}
+		p.mu.Unlock()
+	}
+	return key, nil
+}
+
+// credentialFromRequest returns the first API key presented by the client and where it came from.
+func credentialFromRequest(r *http.Request) (string, string) {
+	if r == nil {
+		return "", ""
+	}
+	if auth := strings.TrimSpace(r.Header.Get("Authorization")); auth != "" {
+		parts := strings.SplitN(auth, " ", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], "bearer") {
+			return strings.TrimSpace(parts[1]), "authorization"
+		}
+		return auth, "authorization"
+	}
+	if v := r.Header.Get("X-Goog-Api-Key"); v != "" {
+		return v, "x-goog-api-key"
+	}
+	if v := r.Header.Get("X-Api-Key"); v != "" {
+		return v, "x-api-key"
+	}
+	if r.URL != nil {
+		if v := r.URL.Query().Get("key"); v != "" {
+			return v, "query-key"
+		}
+		if v := r.URL.Query().Get("auth_token"); v != "" {
+			return v, "query-auth-token"
+		}
+	}
+	return "", ""
+}
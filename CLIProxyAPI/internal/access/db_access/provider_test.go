@@ -0,0 +1,78 @@
+package dbaccess
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/db"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// countingStore accepts a single key and counts validation round trips.
+type countingStore struct {
+	KeyStore
+	valid   string
+	revoked atomic.Bool
+	calls   atomic.Int32
+}
+
+func (s *countingStore) ValidateAPIKey(_ context.Context, plaintext string) (*db.APIKey, error) {
+	s.calls.Add(1)
+	if plaintext != s.valid || s.revoked.Load() {
+		return nil, db.ErrAPIKeyNotFound
+	}
+	expires := time.Now().Add(time.Hour)
+	return &db.APIKey{ID: "key-1", Name: "team-a", RateLimit: 5, ExpiresAt: &expires}, nil
+}
+
+func TestProviderAuthenticatesAndCaches(t *testing.T) {
+	store := &countingStore{valid: "cpk-good"}
+	SetStore(store)
+	t.Cleanup(func() { SetStore(nil) })
+
+	p, err := newProvider(sdkconfig.MakeDBAPIKeyProvider(sdkconfig.DBAPIKeyConfig{Enabled: true}), nil)
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer cpk-good")
+	for i := 0; i < 2; i++ {
+		res, errAuth := p.Authenticate(context.Background(), req)
+		if errAuth != nil {
+			t.Fatalf("Authenticate() error = %v", errAuth)
+		}
+		if res.Metadata["key_id"] != "key-1" || res.Policy == nil || res.Policy.RequestsPerMinute != 5 {
+			t.Fatalf("result = %+v", res)
+		}
+	}
+	if got := store.calls.Load(); got != 1 {
+		t.Fatalf("store calls = %d, want 1 (cached)", got)
+	}
+
+	store.revoked.Store(true)
+	InvalidateCache()
+	if _, err = p.Authenticate(context.Background(), req); !errors.Is(err, sdkaccess.ErrInvalidCredential) {
+		t.Fatalf("revoked key error = %v, want ErrInvalidCredential", err)
+	}
+
+	empty, _ := http.NewRequest(http.MethodGet, "/v1/models", nil)
+	if _, err = p.Authenticate(context.Background(), empty); !errors.Is(err, sdkaccess.ErrNoCredentials) {
+		t.Fatalf("missing key error = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestProviderWithoutStoreIsSkipped(t *testing.T) {
+	SetStore(nil)
+	p, _ := newProvider(&sdkconfig.AccessProvider{Type: sdkconfig.AccessProviderTypeDBAPIKey}, nil)
+	req, _ := http.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("X-Api-Key", "cpk-any")
+	if _, err := p.Authenticate(context.Background(), req); !errors.Is(err, sdkaccess.ErrNotHandled) {
+		t.Fatalf("error = %v, want ErrNotHandled", err)
+	}
+}
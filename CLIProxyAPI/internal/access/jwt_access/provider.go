@@ -0,0 +1,184 @@
+// Package jwtaccess provides the jwt access provider, which authenticates clients with
+// short-lived bearer tokens issued by the internal/auth/jwt manager.
+package jwtaccess
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/jwt"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// Claim names carried in the token metadata.
+const (
+	// ClaimScope marks tokens minted for API clients; session tokens issued by the production
+	// JWT middleware carry upstream API keys and are not accepted here.
+	ClaimScope            = "scope"
+	ClaimAllowedModels    = "allowed_models"
+	ClaimAllowedProviders = "allowed_providers"
+
+	// ScopeClient is the ClaimScope value of tokens accepted by the provider.
+	ScopeClient = "client"
+)
+
+var (
+	registerOnce sync.Once
+
+	managerMu sync.RWMutex
+	manager   *jwt.Manager
+)
+
+// Register ensures the jwt provider is available to the access manager.
+func Register() {
+	registerOnce.Do(func() {
+		sdkaccess.RegisterProvider(sdkconfig.AccessProviderTypeJWT, newProvider)
+	})
+}
+
+// SetManager installs the JWT manager used to validate and issue tokens. The server calls it
+// whenever jwt-secret changes; nil disables the provider.
+func SetManager(m *jwt.Manager) {
+	managerMu.Lock()
+	manager = m
+	managerMu.Unlock()
+}
+
+// Manager returns the installed JWT manager, or nil when jwt-secret is not configured.
+func Manager() *jwt.Manager {
+	managerMu.RLock()
+	defer managerMu.RUnlock()
+	return manager
+}
+
+type provider struct {
+	name string
+}
+
+func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		name = sdkconfig.AccessProviderTypeJWT
+	}
+	return &provider{name: name}, nil
+}
+
+func (p *provider) Identifier() string {
+	if p == nil || p.name == "" {
+		return sdkconfig.AccessProviderTypeJWT
+	}
+	return p.name
+}
+
+func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.Result, error) {
+	m := Manager()
+	if p == nil || m == nil || r == nil {
+		return nil, sdkaccess.ErrNotHandled
+	}
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		return nil, sdkaccess.ErrNoCredentials
+	}
+	if !looksLikeJWT(token) {
+		return nil, sdkaccess.ErrNotHandled
+	}
+
+	claims, err := m.ValidateToken(token)
+	switch {
+	case errors.Is(err, jwt.ErrExpiredToken):
+		return nil, sdkaccess.ErrCredentialExpired
+	case err != nil:
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+	// Refresh tokens and session tokens without the client scope never authorize API calls.
+	if claims.TokenType != "access" || claims.Metadata[ClaimScope] != ScopeClient {
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+
+	subject := claims.Subject
+	if subject == "" {
+		subject = claims.UserID
+	}
+	policy := &sdkaccess.Policy{
+		Name:             subject,
+		AllowedModels:    stringsClaim(claims.Metadata[ClaimAllowedModels]),
+		AllowedProviders: stringsClaim(claims.Metadata[ClaimAllowedProviders]),
+	}
+	metadata := map[string]string{
+		"source":  "jwt",
+		"subject": subject,
+	}
+	if claims.ExpiresAt != nil {
+		policy.ExpiresAt = claims.ExpiresAt.Time
+		metadata["expires_at"] = claims.ExpiresAt.Time.UTC().Format(time.RFC3339)
+	}
+	if len(policy.AllowedModels) > 0 {
+		metadata[ClaimAllowedModels] = strings.Join(policy.AllowedModels, ",")
+	}
+	if len(policy.AllowedProviders) > 0 {
+		metadata[ClaimAllowedProviders] = strings.Join(policy.AllowedProviders, ",")
+	}
+	return &sdkaccess.Result{
+		Provider:  p.Identifier(),
+		Principal: subject,
+		Metadata:  metadata,
+		Policy:    policy,
+	}, nil
+}
+
+func bearerToken(header string) string {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// looksLikeJWT reports whether token has the three-segment compact JWS shape, so that static
+// API keys fall through to the other providers.
+func looksLikeJWT(token string) bool {
+	return strings.HasPrefix(token, "eyJ") && strings.Count(token, ".") == 2
+}
+
+// stringsClaim converts a metadata claim to a string slice. Claims decoded from JSON arrive as
+// []interface{}; claims set in process arrive as []string.
+func stringsClaim(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		if v == "" {
+			return nil
+		}
+		return strings.Split(v, ",")
+	}
+	return nil
+}
+
+// IssueToken mints a client token for subject restricted to the given models and providers.
+func IssueToken(m *jwt.Manager, subject string, ttl time.Duration, allowedModels, allowedProviders []string) (string, time.Time, error) {
+	if m == nil {
+		return "", time.Time{}, errors.New("jwt manager not configured")
+	}
+	claims := map[string]interface{}{ClaimScope: ScopeClient}
+	if len(allowedModels) > 0 {
+		claims[ClaimAllowedModels] = allowedModels
+	}
+	if len(allowedProviders) > 0 {
+		claims[ClaimAllowedProviders] = allowedProviders
+	}
+	return m.GenerateScopedToken(subject, ttl, claims)
+}
@@ -0,0 +1,93 @@
+package jwtaccess
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/jwt"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func newTestProvider(t *testing.T) (sdkaccess.Provider, *jwt.Manager) {
+	t.Helper()
+	m, err := jwt.NewManager(jwt.Config{SecretKey: "test-secret"})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	SetManager(m)
+	t.Cleanup(func() { SetManager(nil) })
+
+	p, err := newProvider(&sdkconfig.AccessProvider{Type: sdkconfig.AccessProviderTypeJWT}, nil)
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+	return p, m
+}
+
+func bearerRequest(token string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestProviderMapsClaims(t *testing.T) {
+	p, m := newTestProvider(t)
+	token, expiresAt, err := IssueToken(m, "ci-job", 10*time.Minute, []string{"gpt-*"}, []string{"codex"})
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+
+	res, err := p.Authenticate(context.Background(), bearerRequest(token))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if res.Principal != "ci-job" || res.Metadata["subject"] != "ci-job" {
+		t.Fatalf("principal = %q, metadata = %v", res.Principal, res.Metadata)
+	}
+	if res.Metadata["allowed_models"] != "gpt-*" || res.Metadata["expires_at"] != expiresAt.UTC().Format(time.RFC3339) {
+		t.Fatalf("metadata = %v", res.Metadata)
+	}
+	if res.Policy == nil || !res.Policy.AllowsModel("gpt-5") || res.Policy.AllowsModel("claude-sonnet-4") {
+		t.Fatalf("policy = %+v", res.Policy)
+	}
+	if !res.Policy.AllowsProvider("codex") || res.Policy.AllowsProvider("claude") {
+		t.Fatalf("policy providers = %v", res.Policy.AllowedProviders)
+	}
+}
+
+func TestProviderRejectsInvalidTokens(t *testing.T) {
+	p, m := newTestProvider(t)
+
+	if _, err := p.Authenticate(context.Background(), bearerRequest("sk-static-key")); !errors.Is(err, sdkaccess.ErrNotHandled) {
+		t.Fatalf("static key error = %v, want ErrNotHandled", err)
+	}
+
+	expired, _, err := IssueToken(m, "ci-job", time.Nanosecond, nil, nil)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err = p.Authenticate(context.Background(), bearerRequest(expired)); !errors.Is(err, sdkaccess.ErrCredentialExpired) {
+		t.Fatalf("expired token error = %v, want ErrCredentialExpired", err)
+	}
+
+	session, err := m.GenerateAPISessionToken("sk-upstream", "auth-1", "codex")
+	if err != nil {
+		t.Fatalf("GenerateAPISessionToken() error = %v", err)
+	}
+	if _, err = p.Authenticate(context.Background(), bearerRequest(session)); !errors.Is(err, sdkaccess.ErrInvalidCredential) {
+		t.Fatalf("session token error = %v, want ErrInvalidCredential", err)
+	}
+
+	revoked, _, _ := IssueToken(m, "ci-job", time.Minute, nil, nil)
+	if err = m.RevokeToken(revoked); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if _, err = p.Authenticate(context.Background(), bearerRequest(revoked)); !errors.Is(err, sdkaccess.ErrInvalidCredential) {
+		t.Fatalf("revoked token error = %v, want ErrInvalidCredential", err)
+	}
+}
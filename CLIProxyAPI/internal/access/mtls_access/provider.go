@@ -0,0 +1,114 @@
+// Package mtlsaccess provides the mtls access provider, which authenticates clients by the
+// TLS client certificate the server verified against tls.client-ca.
+package mtlsaccess
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// Certificate fields that can be mapped to the principal.
+const (
+	PrincipalFromSubjectCN = "subject-cn"
+	PrincipalFromSANURI    = "san-uri"
+	PrincipalFromSANDNS    = "san-dns"
+	PrincipalFromSANEmail  = "san-email"
+)
+
+var registerOnce sync.Once
+
+// Register ensures the mtls provider is available to the access manager.
+func Register() {
+	registerOnce.Do(func() {
+		sdkaccess.RegisterProvider(sdkconfig.AccessProviderTypeMTLS, newProvider)
+	})
+}
+
+type provider struct {
+	name          string
+	principalFrom string
+}
+
+func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		name = sdkconfig.AccessProviderTypeMTLS
+	}
+	from, _ := cfg.Config["principal-from"].(string)
+	from = strings.ToLower(strings.TrimSpace(from))
+	switch from {
+	case "":
+		from = PrincipalFromSubjectCN
+	case PrincipalFromSubjectCN, PrincipalFromSANURI, PrincipalFromSANDNS, PrincipalFromSANEmail:
+	default:
+		return nil, fmt.Errorf("mtls: unsupported principal-from %q", from)
+	}
+	return &provider{name: name, principalFrom: from}, nil
+}
+
+func (p *provider) Identifier() string {
+	if p == nil || p.name == "" {
+		return sdkconfig.AccessProviderTypeMTLS
+	}
+	return p.name
+}
+
+func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.Result, error) {
+	if p == nil || r == nil || r.TLS == nil {
+		return nil, sdkaccess.ErrNotHandled
+	}
+	// Only chains verified against tls.client-ca count; PeerCertificates alone are unverified.
+	if len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, sdkaccess.ErrNoCredentials
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	principal := principalFromCertificate(cert, p.principalFrom)
+	if principal == "" {
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+
+	metadata := map[string]string{
+		"source":         "client-certificate",
+		"principal_from": p.principalFrom,
+		"subject":        cert.Subject.String(),
+		"issuer":         cert.Issuer.String(),
+		"serial":         cert.SerialNumber.String(),
+		"expires_at":     cert.NotAfter.UTC().Format(time.RFC3339),
+	}
+	return &sdkaccess.Result{
+		Provider:  p.Identifier(),
+		Principal: principal,
+		Metadata:  metadata,
+		Policy:    &sdkaccess.Policy{Name: principal},
+	}, nil
+}
+
+func principalFromCertificate(cert *x509.Certificate, from string) string {
+	switch from {
+	case PrincipalFromSANURI:
+		for _, uri := range cert.URIs {
+			if uri != nil {
+				return uri.String()
+			}
+		}
+	case PrincipalFromSANDNS:
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+	case PrincipalFromSANEmail:
+		if len(cert.EmailAddresses) > 0 {
+			return cert.EmailAddresses[0]
+		}
+	default:
+		return strings.TrimSpace(cert.Subject.CommonName)
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package mtlsaccess
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, tmpl *x509.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// serve starts a TLS server that verifies client certificates against ca and reports the
+// provider's authentication result.
+func serve(t *testing.T, ca *testCA, p sdkaccess.Provider) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := p.Authenticate(r.Context(), r)
+		switch {
+		case errors.Is(err, sdkaccess.ErrNoCredentials):
+			w.WriteHeader(http.StatusUnauthorized)
+		case err != nil:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_, _ = io.WriteString(w, res.Principal)
+		}
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	server.TLS = &tls.Config{ClientCAs: pool, ClientAuth: tls.VerifyClientCertIfGiven}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, server *httptest.Server, cert *tls.Certificate) (int, string) {
+	t.Helper()
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	if cert != nil {
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+	}
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func newTestProvider(t *testing.T, from string) sdkaccess.Provider {
+	t.Helper()
+	p, err := newProvider(&sdkconfig.AccessProvider{
+		Type:   sdkconfig.AccessProviderTypeMTLS,
+		Config: map[string]any{"principal-from": from},
+	}, nil)
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+	return p
+}
+
+func TestProviderMapsCertificateToPrincipal(t *testing.T) {
+	ca := newTestCA(t)
+	spiffe, _ := url.Parse("spiffe://mesh.local/ns/batch/sa/reporter")
+	cert := ca.issue(t, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "reporter"},
+		URIs:     []*url.URL{spiffe},
+		DNSNames: []string{"reporter.batch.svc"},
+	})
+
+	cases := map[string]string{
+		"":                     "reporter",
+		PrincipalFromSANURI:    "spiffe://mesh.local/ns/batch/sa/reporter",
+		PrincipalFromSANDNS:    "reporter.batch.svc",
+		PrincipalFromSubjectCN: "reporter",
+	}
+	for from, want := range cases {
+		server := serve(t, ca, newTestProvider(t, from))
+		if code, principal := get(t, server, &cert); code != http.StatusOK || principal != want {
+			t.Fatalf("principal-from %q = %d %q, want %q", from, code, principal, want)
+		}
+	}
+
+	server := serve(t, ca, newTestProvider(t, PrincipalFromSANEmail))
+	if code, _ := get(t, server, &cert); code != http.StatusForbidden {
+		t.Fatalf("missing san-email = %d, want 403", code)
+	}
+	if code, _ := get(t, server, nil); code != http.StatusUnauthorized {
+		t.Fatalf("no client certificate = %d, want 401", code)
+	}
+}
+
+func TestProviderRejectsUnknownPrincipalSource(t *testing.T) {
+	_, err := newProvider(&sdkconfig.AccessProvider{
+		Type:   sdkconfig.AccessProviderTypeMTLS,
+		Config: map[string]any{"principal-from": "serial"},
+	}, nil)
+	if err == nil {
+		t.Fatal("newProvider() error = nil, want unsupported principal-from")
+	}
+}
@@ -0,0 +1,249 @@
+package oidcaccess
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// errUnknownKey is returned when the key set has no key for the token's key ID.
+	errUnknownKey = errors.New("oidc: signing key not found in jwks")
+
+	// errKeySetUnavailable is returned when the key set cannot be fetched from the issuer.
+	errKeySetUnavailable = errors.New("oidc: jwks unavailable")
+)
+
+// keySet caches the issuer's JSON Web Key Set. Keys are re-fetched after refreshInterval, and
+// sooner when a token names an unknown key ID, which is how issuer key rotation shows up. Forced
+// refreshes are throttled by minRefresh so forged key IDs cannot hammer the issuer, and so are
+// retries after a failed refresh. Fetches run outside the lock and concurrent callers share one.
+type keySet struct {
+	client          *http.Client
+	issuer          string
+	jwksURL         string
+	refreshInterval time.Duration
+	minRefresh      time.Duration
+	now             func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]any
+	fetchedAt time.Time
+	failedAt  time.Time
+	lastErr   error
+	// inflight is closed when the running refresh completes; nil when none is running.
+	inflight chan struct{}
+}
+
+// key returns the public key for kid. An empty kid matches the only key of a single-key set.
+func (s *keySet) key(ctx context.Context, kid string) (any, error) {
+	s.mu.Lock()
+	now := s.now()
+	stale := s.keys == nil || now.Sub(s.fetchedAt) >= s.refreshInterval
+	if !stale {
+		if key, ok := s.lookup(kid); ok {
+			s.mu.Unlock()
+			return key, nil
+		}
+		if now.Sub(s.fetchedAt) < s.minRefresh {
+			s.mu.Unlock()
+			return nil, errUnknownKey
+		}
+	}
+	if s.lastErr != nil && now.Sub(s.failedAt) < s.minRefresh {
+		// The issuer failed recently: keep serving the previous keys without re-fetching.
+		key, err := s.resultLocked(kid)
+		s.mu.Unlock()
+		return key, err
+	}
+	done := s.inflight
+	if done == nil {
+		done = make(chan struct{})
+		s.inflight = done
+		go s.refresh(s.jwksURL, done)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resultLocked(kid)
+}
+
+// resultLocked looks kid up after a refresh attempt. The caller must hold s.mu.
+func (s *keySet) resultLocked(kid string) (any, error) {
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+	if s.lastErr != nil {
+		return nil, fmt.Errorf("%w: %v", errKeySetUnavailable, s.lastErr)
+	}
+	return nil, errUnknownKey
+}
+
+func (s *keySet) lookup(kid string) (any, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// refresh fetches the key set, records the outcome and closes done. It does not inherit the
+// request context, so one client hanging up does not fail the fetch for everyone waiting on it;
+// the HTTP client timeout bounds it instead.
+func (s *keySet) refresh(jwksURL string, done chan struct{}) {
+	keys, jwksURL, err := s.fetch(context.Background(), jwksURL)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.lastErr = err
+		s.failedAt = s.now()
+	} else {
+		s.keys = keys
+		s.jwksURL = jwksURL
+		s.fetchedAt = s.now()
+		s.lastErr = nil
+	}
+	s.inflight = nil
+	close(done)
+}
+
+// fetch downloads the key set, discovering its URL first when it is not known yet.
+func (s *keySet) fetch(ctx context.Context, jwksURL string) (map[string]any, string, error) {
+	if jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := s.getJSON(ctx, strings.TrimSuffix(s.issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, "", fmt.Errorf("oidc: discovery failed: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, "", errors.New("oidc: discovery document has no jwks_uri")
+		}
+		jwksURL = discovery.JWKSURI
+	}
+
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.getJSON(ctx, jwksURL, &doc); err != nil {
+		return nil, "", fmt.Errorf("oidc: fetch jwks failed: %w", err)
+	}
+	keys := make(map[string]any, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip key types we cannot use rather than rejecting the whole set.
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, "", errors.New("oidc: jwks contains no usable signing keys")
+	}
+	return keys, jwksURL, nil
+}
+
+func (s *keySet) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// jsonWebKey is the subset of RFC 7517 needed for RSA and EC signature keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,228 @@
+// Package oidcaccess provides the oidc access provider, which authenticates clients with bearer
+// tokens issued by an external OpenID Connect identity provider and verified against its JWKS.
+package oidcaccess
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPrincipalClaim  = "email"
+	defaultRefreshInterval = time.Hour
+	minKeyRefresh          = time.Minute
+	clockLeeway            = 30 * time.Second
+	fetchTimeout           = 10 * time.Second
+)
+
+// signingMethods lists the asymmetric algorithms accepted from the issuer. HMAC is excluded so a
+// public key can never be used as a shared secret.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+var registerOnce sync.Once
+
+// Register ensures the oidc provider is available to the access manager.
+func Register() {
+	registerOnce.Do(func() {
+		sdkaccess.RegisterProvider(sdkconfig.AccessProviderTypeOIDC, newProvider)
+	})
+}
+
+type provider struct {
+	name           string
+	issuer         string
+	audiences      []string
+	principalClaim string
+	keys           *keySet
+	parser         *jwt.Parser
+}
+
+func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		name = sdkconfig.AccessProviderTypeOIDC
+	}
+	issuer, _ := cfg.Config["issuer"].(string)
+	issuer = strings.TrimSpace(issuer)
+	if issuer == "" {
+		return nil, errors.New("oidc: issuer is required")
+	}
+	// Audiences arrive as []string from the oidc config block and as []any from auth.providers YAML.
+	audiences := claimStrings(cfg.Config["audiences"])
+	if len(audiences) == 0 {
+		return nil, errors.New("oidc: at least one audience is required")
+	}
+	jwksURL, _ := cfg.Config["jwks-url"].(string)
+	principalClaim, _ := cfg.Config["principal-claim"].(string)
+	if principalClaim = strings.TrimSpace(principalClaim); principalClaim == "" {
+		principalClaim = defaultPrincipalClaim
+	}
+	refresh := defaultRefreshInterval
+	if seconds, _ := cfg.Config["refresh-interval-seconds"].(int); seconds > 0 {
+		refresh = time.Duration(seconds) * time.Second
+	}
+
+	return &provider{
+		name:           name,
+		issuer:         issuer,
+		audiences:      audiences,
+		principalClaim: principalClaim,
+		keys: &keySet{
+			client:          &http.Client{Timeout: fetchTimeout},
+			issuer:          issuer,
+			jwksURL:         strings.TrimSpace(jwksURL),
+			refreshInterval: refresh,
+			minRefresh:      minKeyRefresh,
+			now:             time.Now,
+		},
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(signingMethods),
+			jwt.WithIssuer(issuer),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(clockLeeway),
+		),
+	}, nil
+}
+
+func (p *provider) Identifier() string {
+	if p == nil || p.name == "" {
+		return sdkconfig.AccessProviderTypeOIDC
+	}
+	return p.name
+}
+
+func (p *provider) Authenticate(ctx context.Context, r *http.Request) (*sdkaccess.Result, error) {
+	if p == nil || r == nil {
+		return nil, sdkaccess.ErrNotHandled
+	}
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		return nil, sdkaccess.ErrNoCredentials
+	}
+	// Leave static keys and tokens from other issuers to the other providers.
+	if !p.fromIssuer(token) {
+		return nil, sdkaccess.ErrNotHandled
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := p.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.keys.key(ctx, kid)
+	})
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return nil, sdkaccess.ErrCredentialExpired
+	case errors.Is(err, errKeySetUnavailable):
+		// The issuer could not be reached; this is an outage, not a bad credential.
+		log.Warnf("oidc access: %v", err)
+		return nil, err
+	case err != nil:
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+	if !p.audienceAllowed(claims) {
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+
+	values := claimStrings(claims[p.principalClaim])
+	if len(values) == 0 {
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+	principal := values[0]
+	subject, _ := claims.GetSubject()
+	metadata := map[string]string{
+		"source":          "oidc",
+		"issuer":          p.issuer,
+		"subject":         subject,
+		"principal_claim": p.principalClaim,
+	}
+	if len(values) > 1 {
+		metadata[p.principalClaim] = strings.Join(values, ",")
+	}
+	if email, ok := claims["email"].(string); ok && email != "" {
+		metadata["email"] = email
+	}
+	policy := &sdkaccess.Policy{Name: principal}
+	if exp, errExp := claims.GetExpirationTime(); errExp == nil && exp != nil {
+		policy.ExpiresAt = exp.Time
+		metadata["expires_at"] = exp.Time.UTC().Format(time.RFC3339)
+	}
+	return &sdkaccess.Result{
+		Provider:  p.Identifier(),
+		Principal: principal,
+		Metadata:  metadata,
+		Policy:    policy,
+	}, nil
+}
+
+// fromIssuer reports whether token is a JWT whose unverified "iss" claim names our issuer.
+func (p *provider) fromIssuer(token string) bool {
+	if strings.Count(token, ".") != 2 {
+		return false
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return false
+	}
+	iss, _ := claims.GetIssuer()
+	return iss == p.issuer
+}
+
+func (p *provider) audienceAllowed(claims jwt.MapClaims) bool {
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return false
+	}
+	for _, aud := range audiences {
+		for _, allowed := range p.audiences {
+			if aud == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func bearerToken(header string) string {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// claimStrings converts a string or list claim, or a list config value, to its non-empty string
+// values.
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return []string{v}
+		}
+	case []string:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if strings.TrimSpace(item) != "" {
+				out = append(out, strings.TrimSpace(item))
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package oidcaccess
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// fakeIssuer serves a discovery document and a JWKS whose keys can be rotated.
+type fakeIssuer struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PrivateKey
+	jwksFetches int
+	failing     bool
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	f := &fakeIssuer{keys: make(map[string]*rsa.PrivateKey)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": f.server.URL, "jwks_uri": f.server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.jwksFetches++
+		if f.failing {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		keys := make([]map[string]string, 0, len(f.keys))
+		for kid, key := range f.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeIssuer) addKey(t *testing.T, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	f.mu.Lock()
+	f.keys[kid] = key
+	f.mu.Unlock()
+}
+
+func (f *fakeIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	f.mu.Lock()
+	key := f.keys[kid]
+	f.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return signed
+}
+
+func (f *fakeIssuer) fetches() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.jwksFetches
+}
+
+func (f *fakeIssuer) claims(aud string, exp time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":    f.server.URL,
+		"sub":    "user-123",
+		"aud":    aud,
+		"exp":    exp.Unix(),
+		"email":  "dev@example.com",
+		"groups": []string{"platform", "eng"},
+	}
+}
+
+func newTestProvider(t *testing.T, issuer *fakeIssuer, principalClaim string) *provider {
+	t.Helper()
+	p, err := newProvider(sdkconfig.MakeOIDCAccessProvider(sdkconfig.OIDCAccessConfig{
+		Enabled:        true,
+		Issuer:         issuer.server.URL,
+		Audiences:      []string{"cliproxy"},
+		PrincipalClaim: principalClaim,
+	}), nil)
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+	return p.(*provider)
+}
+
+func authenticate(p *provider, token string) (*sdkaccess.Result, error) {
+	req, _ := http.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return p.Authenticate(context.Background(), req)
+}
+
+func TestProviderValidatesTokens(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.addKey(t, "k1")
+	p := newTestProvider(t, issuer, "")
+	exp := time.Now().Add(time.Hour)
+
+	res, err := authenticate(p, issuer.sign(t, "k1", issuer.claims("cliproxy", exp)))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if res.Principal != "dev@example.com" || res.Metadata["subject"] != "user-123" || res.Metadata["source"] != "oidc" {
+		t.Fatalf("result = %+v", res)
+	}
+	if res.Policy == nil || res.Policy.ExpiresAt.Unix() != exp.Unix() {
+		t.Fatalf("policy = %+v", res.Policy)
+	}
+
+	if _, err = authenticate(p, issuer.sign(t, "k1", issuer.claims("other-app", exp))); !errors.Is(err, sdkaccess.ErrInvalidCredential) {
+		t.Fatalf("wrong audience error = %v, want ErrInvalidCredential", err)
+	}
+	if _, err = authenticate(p, issuer.sign(t, "k1", issuer.claims("cliproxy", time.Now().Add(-time.Hour)))); !errors.Is(err, sdkaccess.ErrCredentialExpired) {
+		t.Fatalf("expired error = %v, want ErrCredentialExpired", err)
+	}
+
+	foreign := issuer.claims("cliproxy", exp)
+	foreign["iss"] = "https://other.example.com"
+	if _, err = authenticate(p, issuer.sign(t, "k1", foreign)); !errors.Is(err, sdkaccess.ErrNotHandled) {
+		t.Fatalf("foreign issuer error = %v, want ErrNotHandled", err)
+	}
+	if _, err = authenticate(p, "sk-static-key"); !errors.Is(err, sdkaccess.ErrNotHandled) {
+		t.Fatalf("static key error = %v, want ErrNotHandled", err)
+	}
+
+	// A token signed by a key outside the JWKS must not validate.
+	rogue := newFakeIssuer(t)
+	rogue.addKey(t, "k1")
+	forged := issuer.claims("cliproxy", exp)
+	if _, err = authenticate(p, rogue.sign(t, "k1", forged)); !errors.Is(err, sdkaccess.ErrInvalidCredential) {
+		t.Fatalf("forged token error = %v, want ErrInvalidCredential", err)
+	}
+}
+
+func TestProviderPrincipalClaim(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.addKey(t, "k1")
+	p := newTestProvider(t, issuer, "groups")
+
+	res, err := authenticate(p, issuer.sign(t, "k1", issuer.claims("cliproxy", time.Now().Add(time.Hour))))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if res.Principal != "platform" || res.Metadata["groups"] != "platform,eng" || res.Metadata["email"] != "dev@example.com" {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestProviderRotatesKeys(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.addKey(t, "k1")
+	p := newTestProvider(t, issuer, "")
+	now := time.Now()
+	p.keys.now = func() time.Time { return now }
+	exp := now.Add(time.Hour)
+
+	if _, err := authenticate(p, issuer.sign(t, "k1", issuer.claims("cliproxy", exp))); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if _, err := authenticate(p, issuer.sign(t, "k1", issuer.claims("cliproxy", exp))); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if got := issuer.fetches(); got != 1 {
+		t.Fatalf("jwks fetches = %d, want 1 (cached)", got)
+	}
+
+	issuer.addKey(t, "k2")
+	rotated := issuer.sign(t, "k2", issuer.claims("cliproxy", exp))
+	if _, err := authenticate(p, rotated); !errors.Is(err, sdkaccess.ErrInvalidCredential) {
+		t.Fatalf("unknown kid within throttle window error = %v, want ErrInvalidCredential", err)
+	}
+
+	now = now.Add(2 * minKeyRefresh)
+	if _, err := authenticate(p, rotated); err != nil {
+		t.Fatalf("rotated key error = %v", err)
+	}
+	if got := issuer.fetches(); got != 2 {
+		t.Fatalf("jwks fetches = %d, want 2", got)
+	}
+}
+
+func TestProviderThrottlesFailedRefreshes(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.addKey(t, "k1")
+	issuer.failing = true
+	p := newTestProvider(t, issuer, "")
+	now := time.Now()
+	p.keys.now = func() time.Time { return now }
+	token := issuer.sign(t, "k1", issuer.claims("cliproxy", now.Add(time.Hour)))
+
+	// Concurrent requests share one fetch, and the failure is not retried within minRefresh.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = authenticate(p, token)
+		}()
+	}
+	wg.Wait()
+	if _, err := authenticate(p, token); err == nil {
+		t.Fatal("Authenticate() succeeded while the issuer is down")
+	}
+	if got := issuer.fetches(); got != 1 {
+		t.Fatalf("jwks fetches = %d, want 1", got)
+	}
+
+	issuer.mu.Lock()
+	issuer.failing = false
+	issuer.mu.Unlock()
+	now = now.Add(2 * minKeyRefresh)
+	if _, err := authenticate(p, token); err != nil {
+		t.Fatalf("Authenticate() after recovery error = %v", err)
+	}
+}
+
+func TestProviderAcceptsYAMLAudiences(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.addKey(t, "k1")
+	// auth.providers entries decode list values as []any.
+	p, err := newProvider(&sdkconfig.AccessProvider{
+		Name:   "corp-sso",
+		Type:   sdkconfig.AccessProviderTypeOIDC,
+		Config: map[string]any{"issuer": issuer.server.URL, "audiences": []any{"cliproxy"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+	token := issuer.sign(t, "k1", issuer.claims("cliproxy", time.Now().Add(time.Hour)))
+	if _, err = authenticate(p.(*provider), token); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package access
+
+import (
+	"context"
+
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func init() {
+	coreusage.RegisterPlugin(quotaPlugin{quota: sdkaccess.DefaultQuota()})
+}
+
+// quotaPlugin charges the tokens of every upstream request to the client API key that made it,
+// feeding the tokens-per-day budgets of client key policies.
+type quotaPlugin struct {
+	quota *sdkaccess.QuotaTracker
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (p quotaPlugin) HandleUsage(_ context.Context, record coreusage.Record) {
+	tokens := record.Detail.TotalTokens
+	if tokens == 0 {
+		tokens = record.Detail.InputTokens + record.Detail.OutputTokens + record.Detail.ReasoningTokens
+	}
+	p.quota.AddTokens(record.APIKey, tokens)
+}
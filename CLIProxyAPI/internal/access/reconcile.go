@@ -81,11 +81,12 @@ func ReconcileProviders(oldCfg, newCfg *config.Config, existing []sdkaccess.Prov
 	}
 
 	if len(result) == 0 {
-		if inline := sdkConfig.MakeInlineAPIKeyProvider(newCfg.APIKeys); inline != nil {
+		if inline := sdkConfig.MakeInlineAPIKeyProvider(newCfg.InlineAPIKeys()); inline != nil {
 			key := providerIdentifier(inline)
 			if key != "" {
 				if oldCfgProvider, ok := oldCfgMap[key]; ok {
-					if providerConfigEqual(oldCfgProvider, inline) {
+					// Client key policies live outside the provider entry, so compare them as well.
+					if providerConfigEqual(oldCfgProvider, inline) && reflect.DeepEqual(oldCfg.ClientKeys, newCfg.ClientKeys) {
 						if existingProvider, okExisting := existingMap[key]; okExisting {
 							result = append(result, existingProvider)
 							finalIDs[key] = struct{}{}
@@ -176,13 +177,16 @@ func accessProviderMap(cfg *config.Config) map[string]*sdkConfig.AccessProvider
 		}
 		result[key] = providerCfg
 	}
-	if len(result) == 0 && len(cfg.APIKeys) > 0 {
-		if provider := sdkConfig.MakeInlineAPIKeyProvider(cfg.APIKeys); provider != nil {
+	if len(result) == 0 {
+		if provider := sdkConfig.MakeInlineAPIKeyProvider(cfg.InlineAPIKeys()); provider != nil {
 			if key := providerIdentifier(provider); key != "" {
 				result[key] = provider
 			}
 		}
 	}
+	for _, provider := range cfg.BuiltinAccessProviders() {
+		result[providerIdentifier(provider)] = provider
+	}
 	return result
 }
 
@@ -197,11 +201,12 @@ func collectProviderEntries(cfg *config.Config) []*sdkConfig.AccessProvider {
 			entries = append(entries, providerCfg)
 		}
 	}
-	if len(entries) == 0 && len(cfg.APIKeys) > 0 {
-		if inline := sdkConfig.MakeInlineAPIKeyProvider(cfg.APIKeys); inline != nil {
+	if len(entries) == 0 {
+		if inline := sdkConfig.MakeInlineAPIKeyProvider(cfg.InlineAPIKeys()); inline != nil {
 			entries = append(entries, inline)
 		}
 	}
+	entries = append(entries, cfg.BuiltinAccessProviders()...)
 	return entries
 }
 
@@ -389,6 +389,37 @@ func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 		"runtime_only":   runtimeOnly,
 		"source":         "memory",
 		"size":           int64(0),
+		"priority":       auth.Priority(),
+		"weight":         auth.Weight(),
+	}
+	if auth.Prefix != "" {
+		entry["prefix"] = auth.Prefix
+	}
+	if auth.ProxyURL != "" {
+		entry["proxy_url"] = auth.ProxyURL
+	}
+	if auth.Quarantined {
+		entry["quarantined"] = true
+	}
+	if schedule, err := auth.Schedule(); err != nil {
+		entry["schedule"] = auth.ScheduleRules()
+		entry["schedule_active"] = false
+		entry["schedule_error"] = err.Error()
+	} else if schedule != nil {
+		entry["schedule"] = auth.ScheduleRules()
+		entry["schedule_active"] = schedule.Active(time.Now())
+	}
+	if limit := auth.MaxConcurrency(); limit > 0 {
+		entry["max_concurrency"] = limit
+	}
+	if h.authManager != nil {
+		entry["in_flight"] = h.authManager.InFlight(auth.ID)
+	}
+	if auth.Quota.Exceeded || auth.Quota.RateLimit != nil {
+		entry["quota"] = auth.Quota
+	}
+	if quotas := modelQuotas(auth); len(quotas) > 0 {
+		entry["model_quota"] = quotas
 	}
 	if email := authEmail(auth); email != "" {
 		entry["email"] = email
@@ -430,6 +461,11 @@ func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 	if claims := extractCodexIDTokenClaims(auth); claims != nil {
 		entry["id_token"] = claims
 	}
+	if h.authManager != nil {
+		if breaker := h.authManager.CircuitBreakerStatus(auth); breaker != nil {
+			entry["circuit_breaker"] = breaker
+		}
+	}
 	return entry
 }
 
@@ -493,6 +529,21 @@ func authEmail(auth *coreauth.Auth) string {
 	return ""
 }
 
+// modelQuotas returns the quota state of models that hit a limit or reported rate limit headers.
+func modelQuotas(auth *coreauth.Auth) map[string]coreauth.QuotaState {
+	var out map[string]coreauth.QuotaState
+	for model, state := range auth.ModelStates {
+		if state == nil || (!state.Quota.Exceeded && state.Quota.RateLimit == nil) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]coreauth.QuotaState)
+		}
+		out[model] = state.Quota
+	}
+	return out
+}
+
 func authAttribute(auth *coreauth.Auth, key string) string {
 	if auth == nil || len(auth.Attributes) == 0 {
 		return ""
@@ -0,0 +1,222 @@
+package management
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// authFilePatch lists the operator editable fields of a credential. Omitted fields are unchanged.
+type authFilePatch struct {
+	Disabled       *bool    `json:"disabled"`
+	Label          *string  `json:"label"`
+	Prefix         *string  `json:"prefix"`
+	ProxyURL       *string  `json:"proxy-url"`
+	MaxConcurrency *int     `json:"max-concurrency"`
+	Schedule       []string `json:"schedule"`
+}
+
+// PatchAuthFile edits the credential with the given auth index: disabled (take it out of or
+// back into rotation), label, prefix, proxy-url, max-concurrency (0 restores the provider
+// default) and schedule (an empty list removes it). Changes are persisted through the token
+// store; for file backed credentials they are written to the auth file.
+func (h *Handler) PatchAuthFile(c *gin.Context) {
+	auth, ok := h.authByIndexParam(c)
+	if !ok {
+		return
+	}
+	var body authFilePatch
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	if body.Disabled == nil && body.Label == nil && body.Prefix == nil && body.ProxyURL == nil && body.MaxConcurrency == nil && body.Schedule == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
+		return
+	}
+	if body.Prefix != nil {
+		prefix := strings.Trim(strings.TrimSpace(*body.Prefix), "/")
+		if strings.Contains(prefix, "/") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "prefix must not contain '/'"})
+			return
+		}
+		body.Prefix = &prefix
+	}
+	if body.ProxyURL != nil {
+		proxyURL := strings.TrimSpace(*body.ProxyURL)
+		if err := validateAuthProxyURL(proxyURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		body.ProxyURL = &proxyURL
+	}
+	if body.MaxConcurrency != nil && *body.MaxConcurrency < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max-concurrency must not be negative"})
+		return
+	}
+	if body.Schedule != nil {
+		if _, err := coreauth.ParseSchedule(body.Schedule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if body.Label != nil {
+		label := strings.TrimSpace(*body.Label)
+		body.Label = &label
+	}
+
+	updated, err := h.authManager.Modify(c.Request.Context(), auth.ID, func(a *coreauth.Auth) {
+		if body.Disabled != nil {
+			// Only a state change touches the status, so enabling a credential that was never
+			// disabled keeps its current status and error message.
+			if *body.Disabled != a.Disabled {
+				a.Disabled = *body.Disabled
+				if a.Disabled {
+					a.Status = coreauth.StatusDisabled
+					a.StatusMessage = "disabled via management API"
+				} else {
+					a.Status = coreauth.StatusActive
+					a.StatusMessage = ""
+				}
+			}
+			setAuthMetadata(a, coreauth.MetadataDisabledKey, a.Disabled, !a.Disabled)
+		}
+		if body.Label != nil {
+			a.Label = *body.Label
+			setAuthMetadata(a, coreauth.MetadataLabelKey, a.Label, a.Label == "")
+		}
+		if body.Prefix != nil {
+			a.Prefix = *body.Prefix
+			setAuthMetadata(a, coreauth.MetadataPrefixKey, a.Prefix, a.Prefix == "")
+		}
+		if body.ProxyURL != nil {
+			a.ProxyURL = *body.ProxyURL
+			setAuthMetadata(a, coreauth.MetadataProxyURLKey, a.ProxyURL, a.ProxyURL == "")
+		}
+		if body.MaxConcurrency != nil {
+			limit := *body.MaxConcurrency
+			// Config-defined credentials carry the cap in attributes, which take precedence.
+			if _, ok := a.Attributes[coreauth.MetadataMaxConcurrencyKey]; ok {
+				if limit > 0 {
+					a.Attributes[coreauth.MetadataMaxConcurrencyKey] = strconv.Itoa(limit)
+				} else {
+					delete(a.Attributes, coreauth.MetadataMaxConcurrencyKey)
+				}
+			}
+			setAuthMetadata(a, coreauth.MetadataMaxConcurrencyKey, limit, limit == 0)
+		}
+		if body.Schedule != nil {
+			rules := coreauth.SplitScheduleRules(strings.Join(body.Schedule, ";"))
+			if _, ok := a.Attributes[coreauth.MetadataScheduleKey]; ok {
+				if len(rules) > 0 {
+					a.Attributes[coreauth.MetadataScheduleKey] = strings.Join(rules, "; ")
+				} else {
+					delete(a.Attributes, coreauth.MetadataScheduleKey)
+				}
+			}
+			setAuthMetadata(a, coreauth.MetadataScheduleKey, rules, len(rules) == 0)
+		}
+	})
+	h.respondAuthLifecycle(c, updated, err)
+}
+
+// ResetAuthCooldown clears the quota and retry cooldowns of the credential with the given auth
+// index, including those of its models, so it is eligible for selection again immediately.
+func (h *Handler) ResetAuthCooldown(c *gin.Context) {
+	auth, ok := h.authByIndexParam(c)
+	if !ok {
+		return
+	}
+	updated, err := h.authManager.ResetCooldown(c.Request.Context(), auth.ID)
+	h.respondAuthLifecycle(c, updated, err)
+}
+
+// RefreshAuth refreshes the tokens of the credential with the given auth index immediately.
+func (h *Handler) RefreshAuth(c *gin.Context) {
+	auth, ok := h.authByIndexParam(c)
+	if !ok {
+		return
+	}
+	updated, err := h.authManager.RefreshNow(c.Request.Context(), auth.ID)
+	if err != nil && updated != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "refresh failed: " + err.Error()})
+		return
+	}
+	h.respondAuthLifecycle(c, updated, err)
+}
+
+// authByIndexParam resolves the :index route parameter to a registered auth, writing the error
+// response when it cannot.
+func (h *Handler) authByIndexParam(c *gin.Context) (*coreauth.Auth, bool) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return nil, false
+	}
+	index := strings.TrimSpace(c.Param("index"))
+	if index == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "auth index is required"})
+		return nil, false
+	}
+	for _, auth := range h.authManager.List() {
+		if auth.EnsureIndex() == index {
+			return auth, true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+	return nil, false
+}
+
+func (h *Handler) respondAuthLifecycle(c *gin.Context, updated *coreauth.Auth, err error) {
+	if err != nil {
+		var authErr *coreauth.Error
+		if errors.As(err, &authErr) && authErr.HTTPStatus == http.StatusNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "auth not found"})
+			return
+		}
+		if updated == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// The in-memory change took effect; only writing it to the store failed.
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to persist auth: " + err.Error()})
+		return
+	}
+	resp := gin.H{"status": "ok"}
+	if entry := h.buildAuthFileEntry(updated); entry != nil {
+		resp["file"] = entry
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// setAuthMetadata mirrors an operator edit into the auth metadata persisted by the token store.
+// Credentials without metadata, such as those defined in the config file, are only changed in memory.
+func setAuthMetadata(a *coreauth.Auth, key string, value any, clear bool) {
+	if a.Metadata == nil {
+		return
+	}
+	if clear {
+		delete(a.Metadata, key)
+		return
+	}
+	a.Metadata[key] = value
+}
+
+func validateAuthProxyURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return errors.New("invalid proxy-url")
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "socks5":
+		return nil
+	}
+	return errors.New("proxy-url scheme must be http, https or socks5")
+}
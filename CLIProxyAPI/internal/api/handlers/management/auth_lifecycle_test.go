@@ -0,0 +1,101 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+func TestPatchAuthFilePersistsOperatorEdits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "claude-user.json")
+	if err := os.WriteFile(path, []byte(`{"type":"claude","email":"user@example.com"}`), 0o600); err != nil {
+		t.Fatalf("write auth file: %v", err)
+	}
+	store := sdkauth.NewFileTokenStore()
+	store.SetBaseDir(dir)
+	manager := coreauth.NewManager(store, nil, nil)
+	auth, err := manager.Register(context.Background(), &coreauth.Auth{
+		ID:         "claude-user.json",
+		Provider:   "claude",
+		FileName:   "claude-user.json",
+		Status:     coreauth.StatusActive,
+		Attributes: map[string]string{"path": path},
+		Metadata:   map[string]any{"type": "claude", "email": "user@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	h := &Handler{cfg: &config.Config{AuthDir: dir}, authManager: manager}
+	router := gin.New()
+	router.PATCH("/auth-files/:index", h.PatchAuthFile)
+	router.POST("/auth-files/:index/reset-cooldown", h.ResetAuthCooldown)
+	send := func(method, target, body string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, target, bytes.NewBufferString(body)))
+		var out map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	code, out := send(http.MethodPatch, "/auth-files/"+auth.Index, `{"disabled":true,"label":"team-a","prefix":"/team/","proxy-url":"socks5://127.0.0.1:1080","max-concurrency":3,"schedule":["never daily"]}`)
+	if code != http.StatusOK {
+		t.Fatalf("patch = %d %v", code, out)
+	}
+	file, _ := out["file"].(map[string]any)
+	if file["disabled"] != true || file["label"] != "team-a" || file["prefix"] != "team" || file["max_concurrency"] != float64(3) || file["schedule_active"] != false {
+		t.Fatalf("patched entry = %v", file)
+	}
+	data, _ := os.ReadFile(path)
+	var persisted map[string]any
+	_ = json.Unmarshal(data, &persisted)
+	if persisted["disabled"] != true || persisted["label"] != "team-a" || persisted["prefix"] != "team" || persisted["proxy_url"] != "socks5://127.0.0.1:1080" || persisted["max_concurrency"] != float64(3) {
+		t.Fatalf("persisted metadata = %v", persisted)
+	}
+
+	if code, _ = send(http.MethodPatch, "/auth-files/"+auth.Index, `{"disabled":false,"prefix":""}`); code != http.StatusOK {
+		t.Fatalf("re-enable = %d", code)
+	}
+	current, _ := manager.GetByID(auth.ID)
+	if current.Disabled || current.Status != coreauth.StatusActive || current.Prefix != "" {
+		t.Fatalf("re-enabled auth = %+v", current)
+	}
+
+	// Enabling a credential that is not disabled leaves its error state alone.
+	if _, err = manager.Modify(context.Background(), auth.ID, func(a *coreauth.Auth) {
+		a.Status = coreauth.StatusError
+		a.StatusMessage = "quota exhausted"
+	}); err != nil {
+		t.Fatalf("Modify: %v", err)
+	}
+	if code, _ = send(http.MethodPatch, "/auth-files/"+auth.Index, `{"disabled":false}`); code != http.StatusOK {
+		t.Fatalf("enable active auth = %d", code)
+	}
+	if current, _ = manager.GetByID(auth.ID); current.Status != coreauth.StatusError || current.StatusMessage != "quota exhausted" {
+		t.Fatalf("enabled auth that was never disabled = %+v", current)
+	}
+
+	for _, bad := range []string{`{}`, `{"prefix":"a/b"}`, `{"proxy-url":"ftp://host"}`, `{"max-concurrency":-1}`, `{"schedule":["someday"]}`} {
+		if code, _ = send(http.MethodPatch, "/auth-files/"+auth.Index, bad); code != http.StatusBadRequest {
+			t.Fatalf("patch %s = %d, want 400", bad, code)
+		}
+	}
+	if code, _ = send(http.MethodPatch, "/auth-files/unknown", `{"disabled":true}`); code != http.StatusNotFound {
+		t.Fatalf("unknown index = %d, want 404", code)
+	}
+	if code, _ = send(http.MethodPost, "/auth-files/"+auth.Index+"/reset-cooldown", ""); code != http.StatusOK {
+		t.Fatalf("reset cooldown = %d", code)
+	}
+}
@@ -0,0 +1,166 @@
+package management
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	dbaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/db_access"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/db"
+)
+
+// clientKeyPrefix marks keys minted by the management API so they are easy to recognise.
+const clientKeyPrefix = "cpk-"
+
+// clientKeyView is the management representation of a database client key. The hash and
+// plaintext are never returned; the plaintext is shown once when a key is minted or rotated.
+type clientKeyView struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name,omitempty"`
+	Description string     `json:"description,omitempty"`
+	KeyPrefix   string     `json:"key-prefix"`
+	RateLimit   int32      `json:"rate-limit"`
+	ExpiresAt   *time.Time `json:"expires-at,omitempty"`
+	LastUsedAt  *time.Time `json:"last-used-at,omitempty"`
+	CreatedAt   time.Time  `json:"created-at"`
+}
+
+func newClientKeyView(key *db.APIKey) clientKeyView {
+	return clientKeyView{
+		ID:          key.ID,
+		Name:        key.Name,
+		Description: key.Description,
+		KeyPrefix:   key.KeyPrefix,
+		RateLimit:   key.RateLimit,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		CreatedAt:   key.CreatedAt,
+	}
+}
+
+func (h *Handler) clientKeyStore(c *gin.Context) dbaccess.KeyStore {
+	store := h.clientKeys
+	if store == nil {
+		store = dbaccess.Store()
+	}
+	if store == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "client key store unavailable; configure the postgres store"})
+	}
+	return store
+}
+
+// ListClientKeys returns every active database client key.
+func (h *Handler) ListClientKeys(c *gin.Context) {
+	store := h.clientKeyStore(c)
+	if store == nil {
+		return
+	}
+	keys, err := store.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list client keys: %v", err)})
+		return
+	}
+	views := make([]clientKeyView, 0, len(keys))
+	for _, key := range keys {
+		views = append(views, newClientKeyView(key))
+	}
+	c.JSON(http.StatusOK, gin.H{"client-keys": views})
+}
+
+// CreateClientKey mints a new database client key and returns its plaintext once.
+func (h *Handler) CreateClientKey(c *gin.Context) {
+	store := h.clientKeyStore(c)
+	if store == nil {
+		return
+	}
+	var body struct {
+		Name        string     `json:"name"`
+		Description string     `json:"description"`
+		RateLimit   int32      `json:"rate-limit"`
+		ExpiresAt   *time.Time `json:"expires-at"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	if body.RateLimit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rate-limit must not be negative"})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires-at must be in the future"})
+		return
+	}
+	plaintext, err := generateClientKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	key := &db.APIKey{
+		Name:        strings.TrimSpace(body.Name),
+		Description: strings.TrimSpace(body.Description),
+		RateLimit:   body.RateLimit,
+		ExpiresAt:   body.ExpiresAt,
+	}
+	if err = store.InsertAPIKey(c.Request.Context(), key, plaintext); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to create client key: %v", err)})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"key": plaintext, "client-key": newClientKeyView(key)})
+}
+
+// RevokeClientKey deactivates a database client key.
+func (h *Handler) RevokeClientKey(c *gin.Context) {
+	store := h.clientKeyStore(c)
+	if store == nil {
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	if err := store.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		writeClientKeyError(c, "revoke", err)
+		return
+	}
+	dbaccess.InvalidateCache()
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// RotateClientKey replaces the secret of a database client key and returns the new plaintext once.
+func (h *Handler) RotateClientKey(c *gin.Context) {
+	store := h.clientKeyStore(c)
+	if store == nil {
+		return
+	}
+	plaintext, err := generateClientKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	key, err := store.RotateAPIKey(c.Request.Context(), strings.TrimSpace(c.Param("id")), plaintext)
+	if err != nil {
+		writeClientKeyError(c, "rotate", err)
+		return
+	}
+	dbaccess.InvalidateCache()
+	c.JSON(http.StatusOK, gin.H{"key": plaintext, "client-key": newClientKeyView(key)})
+}
+
+func writeClientKeyError(c *gin.Context, action string, err error) {
+	if errors.Is(err, db.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client key not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to %s client key: %v", action, err)})
+}
+
+func generateClientKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate client key: %w", err)
+	}
+	return clientKeyPrefix + hex.EncodeToString(buf), nil
+}
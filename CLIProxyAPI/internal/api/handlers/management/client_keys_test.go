@@ -0,0 +1,138 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/db"
+)
+
+type memoryClientKeyStore struct {
+	mu     sync.Mutex
+	keys   map[string]*db.APIKey
+	byHash map[string]string
+}
+
+func newMemoryClientKeyStore() *memoryClientKeyStore {
+	return &memoryClientKeyStore{keys: make(map[string]*db.APIKey), byHash: make(map[string]string)}
+}
+
+func (s *memoryClientKeyStore) ValidateAPIKey(_ context.Context, plaintext string) (*db.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash, _ := db.HashAPIKey(plaintext)
+	if key, ok := s.keys[s.byHash[hash]]; ok && key.IsActive {
+		return key, nil
+	}
+	return nil, db.ErrAPIKeyNotFound
+}
+
+func (s *memoryClientKeyStore) InsertAPIKey(_ context.Context, key *db.APIKey, plaintext string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key.KeyHash, key.KeyPrefix = db.HashAPIKey(plaintext)
+	key.ID = "key-" + key.KeyPrefix
+	key.IsActive = true
+	s.keys[key.ID] = key
+	s.byHash[key.KeyHash] = key.ID
+	return nil
+}
+
+func (s *memoryClientKeyStore) ListAPIKeys(context.Context) ([]*db.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*db.APIKey
+	for _, key := range s.keys {
+		if key.IsActive {
+			out = append(out, key)
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryClientKeyStore) RevokeAPIKey(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[id]
+	if !ok || !key.IsActive {
+		return db.ErrAPIKeyNotFound
+	}
+	key.IsActive = false
+	return nil
+}
+
+func (s *memoryClientKeyStore) RotateAPIKey(_ context.Context, id, plaintext string) (*db.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[id]
+	if !ok || !key.IsActive {
+		return nil, db.ErrAPIKeyNotFound
+	}
+	delete(s.byHash, key.KeyHash)
+	key.KeyHash, key.KeyPrefix = db.HashAPIKey(plaintext)
+	s.byHash[key.KeyHash] = id
+	return key, nil
+}
+
+func TestClientKeyLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newMemoryClientKeyStore()
+	h := &Handler{clientKeys: store}
+	router := gin.New()
+	router.GET("/client-keys", h.ListClientKeys)
+	router.POST("/client-keys", h.CreateClientKey)
+	router.DELETE("/client-keys/:id", h.RevokeClientKey)
+	router.POST("/client-keys/:id/rotate", h.RotateClientKey)
+
+	do := func(method, path, body string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var out map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	code, created := do(http.MethodPost, "/client-keys", `{"name":"team-a","rate-limit":10}`)
+	if code != http.StatusCreated {
+		t.Fatalf("create = %d %v", code, created)
+	}
+	plaintext, _ := created["key"].(string)
+	view, _ := created["client-key"].(map[string]any)
+	id, _ := view["id"].(string)
+	if !strings.HasPrefix(plaintext, clientKeyPrefix) || id == "" || view["key_hash"] != nil {
+		t.Fatalf("create response = %v", created)
+	}
+	if _, err := store.ValidateAPIKey(context.Background(), plaintext); err != nil {
+		t.Fatalf("minted key does not validate: %v", err)
+	}
+
+	code, listed := do(http.MethodGet, "/client-keys", "")
+	if keys, _ := listed["client-keys"].([]any); code != http.StatusOK || len(keys) != 1 {
+		t.Fatalf("list = %d %v", code, listed)
+	}
+
+	code, rotated := do(http.MethodPost, "/client-keys/"+id+"/rotate", "")
+	newPlaintext, _ := rotated["key"].(string)
+	if code != http.StatusOK || newPlaintext == "" || newPlaintext == plaintext {
+		t.Fatalf("rotate = %d %v", code, rotated)
+	}
+	if _, err := store.ValidateAPIKey(context.Background(), plaintext); err == nil {
+		t.Fatal("old key still validates after rotation")
+	}
+
+	if code, _ = do(http.MethodDelete, "/client-keys/"+id, ""); code != http.StatusOK {
+		t.Fatalf("revoke = %d", code)
+	}
+	if code, _ = do(http.MethodDelete, "/client-keys/"+id, ""); code != http.StatusNotFound {
+		t.Fatalf("second revoke = %d, want 404", code)
+	}
+	if _, err := store.ValidateAPIKey(context.Background(), newPlaintext); err == nil {
+		t.Fatal("revoked key still validates")
+	}
+}
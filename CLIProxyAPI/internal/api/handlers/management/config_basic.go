@@ -267,6 +267,10 @@ func normalizeRoutingStrategy(strategy string) (string, bool) {
 		return "round-robin", true
 	case "fill-first", "fillfirst", "ff":
 		return "fill-first", true
+	case "least-loaded", "leastloaded", "least-load", "ll":
+		return "least-loaded", true
+	case "weighted", "weighted-random", "wr":
+		return "weighted", true
 	default:
 		return "", false
 	}
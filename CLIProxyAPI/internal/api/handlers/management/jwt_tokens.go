@@ -0,0 +1,109 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/jwt_access"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/jwt"
+)
+
+const (
+	defaultJWTAccessTTL = 15 * time.Minute
+	maxJWTAccessTTL     = 24 * time.Hour
+)
+
+func (h *Handler) jwtAccessManager(c *gin.Context) *jwt.Manager {
+	if h.cfg == nil || !h.cfg.JWTAccess.Enabled {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "jwt access is disabled; enable jwt-access in the config"})
+		return nil
+	}
+	m := jwtaccess.Manager()
+	if m == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "jwt-secret is not configured"})
+	}
+	return m
+}
+
+// IssueJWTToken mints a short-lived client token for a principal, e.g. a CI job.
+func (h *Handler) IssueJWTToken(c *gin.Context) {
+	m := h.jwtAccessManager(c)
+	if m == nil {
+		return
+	}
+	var body struct {
+		Subject          string   `json:"subject"`
+		TTLSeconds       int      `json:"ttl-seconds"`
+		AllowedModels    []string `json:"allowed-models"`
+		AllowedProviders []string `json:"allowed-providers"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	subject := strings.TrimSpace(body.Subject)
+	if subject == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subject is required"})
+		return
+	}
+	if body.TTLSeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ttl-seconds must not be negative"})
+		return
+	}
+
+	ttl := defaultJWTAccessTTL
+	if seconds := h.cfg.JWTAccess.DefaultTTLSeconds; seconds > 0 {
+		ttl = time.Duration(seconds) * time.Second
+	}
+	if body.TTLSeconds > 0 {
+		ttl = time.Duration(body.TTLSeconds) * time.Second
+	}
+	maxTTL := maxJWTAccessTTL
+	if seconds := h.cfg.JWTAccess.MaxTTLSeconds; seconds > 0 {
+		maxTTL = time.Duration(seconds) * time.Second
+	}
+	if ttl > maxTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ttl-seconds must not exceed %d", int(maxTTL/time.Second))})
+		return
+	}
+
+	token, expiresAt, err := jwtaccess.IssueToken(m, subject, ttl, trimStrings(body.AllowedModels), trimStrings(body.AllowedProviders))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to issue token: %v", err)})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"token": token, "subject": subject, "expires-at": expiresAt.UTC()})
+}
+
+// RevokeJWTToken revokes a previously issued client token before it expires.
+func (h *Handler) RevokeJWTToken(c *gin.Context) {
+	m := h.jwtAccessManager(c)
+	if m == nil {
+		return
+	}
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || strings.TrimSpace(body.Token) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+	if err := m.RevokeToken(strings.TrimSpace(body.Token)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to revoke token: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func trimStrings(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
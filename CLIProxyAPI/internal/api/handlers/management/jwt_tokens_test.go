@@ -0,0 +1,62 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/jwt_access"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/jwt"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestIssueJWTToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m, err := jwt.NewManager(jwt.Config{SecretKey: "test-secret"})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	jwtaccess.SetManager(m)
+	t.Cleanup(func() { jwtaccess.SetManager(nil) })
+
+	cfg := &config.Config{}
+	h := &Handler{cfg: cfg}
+	router := gin.New()
+	router.POST("/jwt-tokens", h.IssueJWTToken)
+	do := func(body string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/jwt-tokens", strings.NewReader(body)))
+		var out map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	if code, _ := do(`{"subject":"ci"}`); code != http.StatusServiceUnavailable {
+		t.Fatalf("disabled = %d, want 503", code)
+	}
+
+	cfg.JWTAccess.Enabled = true
+	cfg.JWTAccess.MaxTTLSeconds = 600
+	if code, _ := do(`{"subject":"ci","ttl-seconds":601}`); code != http.StatusBadRequest {
+		t.Fatalf("ttl over max = %d, want 400", code)
+	}
+	if code, _ := do(`{"ttl-seconds":60}`); code != http.StatusBadRequest {
+		t.Fatalf("missing subject = %d, want 400", code)
+	}
+
+	code, out := do(`{"subject":"ci","ttl-seconds":300,"allowed-models":["gpt-5"]}`)
+	token, _ := out["token"].(string)
+	if code != http.StatusCreated || token == "" || out["expires-at"] == nil {
+		t.Fatalf("issue = %d %v", code, out)
+	}
+	claims, err := m.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.Subject != "ci" || claims.Metadata[jwtaccess.ClaimScope] != jwtaccess.ScopeClient {
+		t.Fatalf("claims = %+v", claims)
+	}
+}
@@ -2,6 +2,7 @@ package management
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -221,33 +222,39 @@ func (h *Handler) GetRequestLogByID(c *gin.Context) {
 		return
 	}
 
-	dir := h.logDirectory()
-	if strings.TrimSpace(dir) == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "log directory not configured"})
-		return
-	}
-
 	requestID := strings.TrimSpace(c.Param("id"))
 	if requestID == "" {
 		requestID = strings.TrimSpace(c.Query("id"))
 	}
-	if requestID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing request ID"})
+	fullPath, matchedFile, status, err := h.findRequestLog(requestID)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
+
+	c.FileAttachment(fullPath, matchedFile)
+}
+
+// findRequestLog locates the request log file written for requestID. On failure it returns the
+// HTTP status describing the error.
+func (h *Handler) findRequestLog(requestID string) (string, string, int, error) {
+	dir := h.logDirectory()
+	if strings.TrimSpace(dir) == "" {
+		return "", "", http.StatusInternalServerError, errors.New("log directory not configured")
+	}
+	if requestID == "" {
+		return "", "", http.StatusBadRequest, errors.New("missing request ID")
+	}
 	if strings.ContainsAny(requestID, "/\\") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request ID"})
-		return
+		return "", "", http.StatusBadRequest, errors.New("invalid request ID")
 	}
 
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "log directory not found"})
-			return
+			return "", "", http.StatusNotFound, errors.New("log directory not found")
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to list log directory: %v", err)})
-		return
+		return "", "", http.StatusInternalServerError, fmt.Errorf("failed to list log directory: %v", err)
 	}
 
 	suffix := "-" + requestID + ".log"
@@ -264,37 +271,30 @@ func (h *Handler) GetRequestLogByID(c *gin.Context) {
 	}
 
 	if matchedFile == "" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "log file not found for the given request ID"})
-		return
+		return "", "", http.StatusNotFound, errors.New("log file not found for the given request ID")
 	}
 
 	dirAbs, errAbs := filepath.Abs(dir)
 	if errAbs != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to resolve log directory: %v", errAbs)})
-		return
+		return "", "", http.StatusInternalServerError, fmt.Errorf("failed to resolve log directory: %v", errAbs)
 	}
 	fullPath := filepath.Clean(filepath.Join(dirAbs, matchedFile))
 	prefix := dirAbs + string(os.PathSeparator)
 	if !strings.HasPrefix(fullPath, prefix) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log file path"})
-		return
+		return "", "", http.StatusBadRequest, errors.New("invalid log file path")
 	}
 
 	info, errStat := os.Stat(fullPath)
 	if errStat != nil {
 		if os.IsNotExist(errStat) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "log file not found"})
-			return
+			return "", "", http.StatusNotFound, errors.New("log file not found")
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to read log file: %v", errStat)})
-		return
+		return "", "", http.StatusInternalServerError, fmt.Errorf("failed to read log file: %v", errStat)
 	}
 	if info.IsDir() {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log file"})
-		return
+		return "", "", http.StatusBadRequest, errors.New("invalid log file")
 	}
-
-	c.FileAttachment(fullPath, matchedFile)
+	return fullPath, matchedFile, http.StatusOK, nil
 }
 
 // DownloadRequestErrorLog downloads a specific error request log file by name.
@@ -0,0 +1,125 @@
+package management
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+)
+
+const (
+	defaultRequestIndexLimit = 50
+	maxRequestIndexLimit     = 500
+)
+
+// SearchRequestIndex lists structured request entries, newest first.
+//
+// Filters: request-id, client-key (plaintext or masked), format, model, provider, auth-id,
+// auth-index, status (a code, or 2xx/4xx/5xx/error), min-latency-ms, from and to (RFC 3339).
+// Pagination: offset and limit (default 50, at most 500).
+func (h *Handler) SearchRequestIndex(c *gin.Context) {
+	index := h.requestIndex
+	if index == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "request index unavailable"})
+		return
+	}
+
+	filter := logging.RequestIndexFilter{
+		RequestID:     strings.TrimSpace(c.Query("request-id")),
+		ClientKey:     strings.TrimSpace(c.Query("client-key")),
+		InboundFormat: strings.TrimSpace(c.Query("format")),
+		Model:         strings.TrimSpace(c.Query("model")),
+		Provider:      strings.TrimSpace(c.Query("provider")),
+		AuthID:        strings.TrimSpace(c.Query("auth-id")),
+		AuthIndex:     strings.TrimSpace(c.Query("auth-index")),
+	}
+	if raw := strings.ToLower(strings.TrimSpace(c.Query("status"))); raw != "" {
+		switch raw {
+		case "2xx", "3xx", "4xx", "5xx", "error":
+			filter.StatusClass = raw
+		default:
+			code, err := strconv.Atoi(raw)
+			if err != nil || code < 100 || code > 599 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+				return
+			}
+			filter.Status = code
+		}
+	}
+
+	var ok bool
+	if filter.MinLatencyMs, ok = queryInt64(c, "min-latency-ms"); !ok {
+		return
+	}
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := strings.TrimSpace(c.Query(param.name))
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param.name + ": expected RFC 3339 timestamp"})
+			return
+		}
+		*param.dst = parsed
+	}
+
+	offset, ok := queryInt64(c, "offset")
+	if !ok {
+		return
+	}
+	limit, ok := queryInt64(c, "limit")
+	if !ok {
+		return
+	}
+	if limit == 0 {
+		limit = defaultRequestIndexLimit
+	}
+	if limit > maxRequestIndexLimit {
+		limit = maxRequestIndexLimit
+	}
+
+	entries, total := index.Search(filter, int(offset), int(limit))
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"total":   total,
+		"offset":  offset,
+		"limit":   limit,
+	})
+}
+
+// GetRequestIndexEntry returns the structured entry for one request ID.
+func (h *Handler) GetRequestIndexEntry(c *gin.Context) {
+	index := h.requestIndex
+	if index == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "request index unavailable"})
+		return
+	}
+	entry, found := index.Get(strings.TrimSpace(c.Param("id")))
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "request not found in index"})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// queryInt64 parses a non-negative integer query parameter, writing a 400 response when it is
+// malformed. A missing parameter yields zero.
+func queryInt64(c *gin.Context, name string) (int64, bool) {
+	raw := strings.TrimSpace(c.Query(name))
+	if raw == "" {
+		return 0, true
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,43 @@
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+)
+
+func TestSearchRequestIndexValidatesParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{requestIndex: logging.NewRequestIndex(10)}
+	router := gin.New()
+	router.GET("/request-index", h.SearchRequestIndex)
+	router.GET("/request-index/:id", h.GetRequestIndexEntry)
+
+	get := func(target string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var out map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	code, body := get("/request-index?status=5xx&limit=1000&provider=codex")
+	if code != http.StatusOK || body["limit"] != float64(maxRequestIndexLimit) || body["total"] != float64(0) {
+		t.Fatalf("search = %d %v", code, body)
+	}
+	if entries, ok := body["entries"].([]any); !ok || len(entries) != 0 {
+		t.Fatalf("entries = %v", body["entries"])
+	}
+	for _, bad := range []string{"status=teapot", "status=999", "limit=-1", "offset=x", "from=yesterday", "min-latency-ms=fast"} {
+		if code, _ = get("/request-index?" + bad); code != http.StatusBadRequest {
+			t.Fatalf("%s = %d, want 400", bad, code)
+		}
+	}
+	if code, _ = get("/request-index/unknown"); code != http.StatusNotFound {
+		t.Fatalf("unknown id = %d, want 404", code)
+	}
+}
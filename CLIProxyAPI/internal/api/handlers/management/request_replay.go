@@ -0,0 +1,179 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// requestReplayRequest names the logged request to replay and optional routing overrides.
+type requestReplayRequest struct {
+	RequestID string `json:"request-id"`
+	Model     string `json:"model"`
+	AuthIndex string `json:"auth-index"`
+	Provider  string `json:"provider"`
+}
+
+// replayTarget is the handler route a logged request is executed through.
+type replayTarget struct {
+	handlerType string
+	model       string
+	body        []byte
+}
+
+// ReplayRequest re-executes a logged inbound request through the API handlers and returns the
+// original and replayed responses side by side. The replay is always non-streaming and may
+// override the model, pin one credential by auth index, or restrict routing to one provider.
+// Model fallback is disabled so the replay always reports the model that actually served it.
+func (h *Handler) ReplayRequest(c *gin.Context) {
+	var body requestReplayRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	requestID := strings.TrimSpace(body.RequestID)
+	if requestID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "request-id is required"})
+		return
+	}
+	if h.cfg == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+
+	fullPath, _, status, err := h.findRequestLog(requestID)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read log file: " + err.Error()})
+		return
+	}
+	record, err := logging.ParseRequestLog(data)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "failed to parse log file: " + err.Error()})
+		return
+	}
+	target, err := replayTargetFor(record)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	originalModel := target.model
+	if model := strings.TrimSpace(body.Model); model != "" {
+		if target.handlerType != constant.Gemini {
+			target.body, _ = sjson.SetBytes(target.body, "model", model)
+		}
+		target.model = model
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(body.Provider))
+	if provider != "" {
+		c.Set("accessPolicy", &sdkaccess.Policy{AllowedProviders: []string{provider}})
+	}
+	ctx := context.WithValue(c.Request.Context(), "gin", c)
+	ctx = handlers.WithPinnedAuthIndex(ctx, body.AuthIndex)
+	ctx = handlers.WithoutModelFallback(ctx)
+
+	start := time.Now()
+	resp, errMsg := handlers.NewBaseAPIHandlers(&h.cfg.SDKConfig, h.authManager).
+		ExecuteWithAuthManager(ctx, target.handlerType, target.model, target.body, "")
+	latency := time.Since(start)
+
+	original := gin.H{"model": originalModel, "body": replayBody(record.ResponseBody)}
+	if record.Status != 0 {
+		original["status"] = record.Status
+	}
+	replay := gin.H{"model": target.model, "latency-ms": latency.Milliseconds()}
+	if provider != "" {
+		replay["provider"] = provider
+	}
+	if authIndex := strings.TrimSpace(body.AuthIndex); authIndex != "" {
+		replay["auth-index"] = authIndex
+	}
+	if errMsg != nil {
+		replay["status"] = errMsg.StatusCode
+		if errMsg.Error != nil {
+			replay["body"] = replayBody([]byte(errMsg.Error.Error()))
+		}
+	} else {
+		replay["status"] = http.StatusOK
+		replay["body"] = replayBody(resp)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"request-id": requestID,
+		"path":       record.URL,
+		"original":   original,
+		"replay":     replay,
+	})
+}
+
+// replayTargetFor maps the logged request path to its handler format and model, forcing a
+// non-streaming request.
+func replayTargetFor(record *logging.RequestLogRecord) (replayTarget, error) {
+	path, _, _ := strings.Cut(record.URL, "?")
+	if rest, ok := strings.CutPrefix(path, "/api/provider/"); ok {
+		if idx := strings.Index(rest, "/"); idx >= 0 {
+			path = rest[idx:]
+		}
+	}
+	if record.Method != http.MethodPost || len(record.Body) == 0 {
+		return replayTarget{}, errors.New("logged request has no replayable body")
+	}
+
+	target := replayTarget{body: record.Body}
+	switch path {
+	case "/v1/chat/completions":
+		target.handlerType = constant.OpenAI
+	case "/v1/responses":
+		target.handlerType = constant.OpenaiResponse
+	case "/v1/messages":
+		target.handlerType = constant.Claude
+	default:
+		action, ok := strings.CutPrefix(path, "/v1beta/models/")
+		if !ok {
+			return replayTarget{}, errors.New("replay is not supported for " + path)
+		}
+		model, method, _ := strings.Cut(action, ":")
+		if model == "" || (method != "generateContent" && method != "streamGenerateContent") {
+			return replayTarget{}, errors.New("replay is not supported for " + path)
+		}
+		target.handlerType = constant.Gemini
+		target.model = model
+		return target, nil
+	}
+	if !gjson.ValidBytes(record.Body) {
+		return replayTarget{}, errors.New("logged request body is not valid JSON")
+	}
+	target.model = gjson.GetBytes(record.Body, "model").String()
+	if gjson.GetBytes(record.Body, "stream").Exists() {
+		target.body, _ = sjson.SetBytes(target.body, "stream", false)
+	}
+	return target, nil
+}
+
+// replayBody embeds a JSON body as-is and anything else, such as an SSE transcript, as a string.
+func replayBody(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	return string(data)
+}
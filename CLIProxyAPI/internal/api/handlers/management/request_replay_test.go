@@ -0,0 +1,123 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+// replayExecutor answers with the auth and model that served each request.
+type replayExecutor struct {
+	mu       sync.Mutex
+	payloads []string
+}
+
+func (e *replayExecutor) Identifier() string { return "claude" }
+
+func (e *replayExecutor) Execute(_ context.Context, auth *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.mu.Lock()
+	e.payloads = append(e.payloads, string(req.Payload))
+	e.mu.Unlock()
+	return coreexecutor.Response{Payload: []byte(`{"auth":"` + auth.ID + `","model":"` + req.Model + `"}`)}, nil
+}
+
+func (e *replayExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "ExecuteStream not implemented"}
+}
+
+func (e *replayExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *replayExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *replayExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented"}
+}
+
+func TestReplayRequestPinsAuthAndOverridesModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	executor := &replayExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	var pinned string
+	for _, id := range []string{"replay-a", "replay-b"} {
+		auth, err := manager.Register(context.Background(), &coreauth.Auth{ID: id, Provider: "claude", Status: coreauth.StatusActive})
+		if err != nil {
+			t.Fatalf("Register(%s): %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: "replay-old"}, {ID: "replay-new"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+		if id == "replay-b" {
+			pinned = auth.EnsureIndex()
+		}
+	}
+
+	dir := t.TempDir()
+	logger := logging.NewFileRequestLogger(true, dir, "")
+	err := logger.LogRequest("/v1/messages", http.MethodPost, nil,
+		[]byte(`{"model":"replay-old","stream":true,"messages":[]}`), http.StatusTooManyRequests, nil,
+		[]byte(`{"error":"rate limited"}`), nil, nil, nil, "req42")
+	if err != nil {
+		t.Fatalf("LogRequest: %v", err)
+	}
+
+	h := &Handler{cfg: &config.Config{}, authManager: manager, logDir: dir}
+	router := gin.New()
+	router.POST("/request-replay", h.ReplayRequest)
+	post := func(body string) (int, []byte) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/request-replay", bytes.NewBufferString(body)))
+		return rec.Code, rec.Body.Bytes()
+	}
+
+	for i := 0; i < 3; i++ {
+		code, out := post(`{"request-id":"req42","model":"replay-new","auth-index":"` + pinned + `"}`)
+		if code != http.StatusOK {
+			t.Fatalf("replay = %d %s", code, out)
+		}
+		result := gjson.ParseBytes(out)
+		if result.Get("original.status").Int() != http.StatusTooManyRequests || result.Get("original.body.error").String() != "rate limited" {
+			t.Fatalf("original = %s", result.Get("original").Raw)
+		}
+		if result.Get("replay.status").Int() != http.StatusOK || result.Get("replay.body.auth").String() != "replay-b" || result.Get("replay.body.model").String() != "replay-new" {
+			t.Fatalf("replay = %s", result.Get("replay").Raw)
+		}
+	}
+	for _, payload := range executor.payloads {
+		if gjson.Get(payload, "stream").Bool() || gjson.Get(payload, "model").String() != "replay-new" {
+			t.Fatalf("executed payload = %s", payload)
+		}
+	}
+
+	// The fallback chain is not followed, so a replay never silently reports a different model.
+	h.cfg.ModelFallbacks = []config.ModelFallback{{Model: "replay-missing", Fallbacks: []string{"replay-new"}}}
+	code, out := post(`{"request-id":"req42","model":"replay-missing"}`)
+	if code != http.StatusOK || gjson.GetBytes(out, "replay.status").Int() < http.StatusBadRequest || gjson.GetBytes(out, "replay.model").String() != "replay-missing" {
+		t.Fatalf("replay with fallback = %d %s", code, out)
+	}
+
+	code, out = post(`{"request-id":"req42","provider":"gemini"}`)
+	if code != http.StatusOK || gjson.GetBytes(out, "replay.status").Int() < http.StatusBadRequest {
+		t.Fatalf("provider override = %d %s", code, out)
+	}
+	if code, _ = post(`{"request-id":"missing"}`); code != http.StatusNotFound {
+		t.Fatalf("missing log = %d, want 404", code)
+	}
+	if code, _ = post(`{}`); code != http.StatusBadRequest {
+		t.Fatalf("empty body = %d, want 400", code)
+	}
+}
@@ -2,7 +2,9 @@ package management
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -77,3 +79,84 @@ func (h *Handler) ImportUsageStatistics(c *gin.Context) {
 		"failed_requests": snapshot.FailureCount,
 	})
 }
+
+// defaultUsageHistoryRange is the range queried when from is omitted.
+const defaultUsageHistoryRange = 24 * time.Hour
+
+// QueryUsageHistory aggregates usage persisted in the database over a time range.
+//
+// Query parameters: from and to (RFC 3339 or YYYY-MM-DD, default the last 24 hours), group-by
+// (comma separated: api-key, model, provider, auth) and bucket (hour or day).
+func (h *Handler) QueryUsageHistory(c *gin.Context) {
+	store := h.usageHistory
+	if store == nil {
+		store = usage.GetHistoryStore()
+	}
+	if store == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "usage history unavailable; configure the postgres store"})
+		return
+	}
+
+	to := time.Now().UTC()
+	if raw := strings.TrimSpace(c.Query("to")); raw != "" {
+		parsed, err := parseUsageTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultUsageHistoryRange)
+	if raw := strings.TrimSpace(c.Query("from")); raw != "" {
+		parsed, err := parseUsageTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+			return
+		}
+		from = parsed
+	}
+
+	groupBy := make([]string, 0, 4)
+	for _, value := range c.QueryArray("group-by") {
+		for _, dim := range strings.Split(value, ",") {
+			if dim = strings.ToLower(strings.TrimSpace(dim)); dim != "" {
+				groupBy = append(groupBy, dim)
+			}
+		}
+	}
+	query := usage.HistoryQuery{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		Bucket:  strings.ToLower(strings.TrimSpace(c.Query("bucket"))),
+	}
+	if err := query.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := store.QueryUsageHistory(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query usage history: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":     query.From,
+		"to":       query.To,
+		"group_by": query.GroupBy,
+		"bucket":   query.Bucket,
+		"rows":     rows,
+	})
+}
+
+// parseUsageTime accepts RFC 3339 timestamps and UTC calendar dates.
+func parseUsageTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, errors.New("expected RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	return t, nil
+}
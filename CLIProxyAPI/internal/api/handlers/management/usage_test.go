@@ -0,0 +1,88 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+)
+
+type recordingHistoryStore struct {
+	queries []usage.HistoryQuery
+}
+
+func (s *recordingHistoryStore) QueryUsageHistory(_ context.Context, q usage.HistoryQuery) ([]usage.HistoryRow, error) {
+	s.queries = append(s.queries, q)
+	return []usage.HistoryRow{{Model: "gpt-5", TotalRequests: 3, TotalCostUSD: 0.5}}, nil
+}
+
+func TestQueryUsageHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &recordingHistoryStore{}
+	h := &Handler{usageHistory: store}
+	router := gin.New()
+	router.GET("/usage/query", h.QueryUsageHistory)
+
+	get := func(query string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/usage/query?"+query, nil))
+		var out map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	code, body := get("from=2026-01-01&to=2026-01-02T12:00:00Z&group-by=model,api-key&group-by=provider&bucket=hour")
+	if code != http.StatusOK {
+		t.Fatalf("query = %d %v", code, body)
+	}
+	if rows, _ := body["rows"].([]any); len(rows) != 1 {
+		t.Fatalf("rows = %v", body["rows"])
+	}
+	q := store.queries[0]
+	wantFrom := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	wantTo := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	if !q.From.Equal(wantFrom) || !q.To.Equal(wantTo) || q.Bucket != usage.HistoryBucketHour {
+		t.Fatalf("query = %+v", q)
+	}
+	if len(q.GroupBy) != 3 || q.GroupBy[0] != "model" || q.GroupBy[1] != "api-key" || q.GroupBy[2] != "provider" {
+		t.Fatalf("group-by = %v", q.GroupBy)
+	}
+
+	if code, _ = get(""); code != http.StatusOK {
+		t.Fatalf("default range = %d", code)
+	}
+	if q = store.queries[1]; q.To.Sub(q.From) != defaultUsageHistoryRange {
+		t.Fatalf("default range = %v..%v", q.From, q.To)
+	}
+
+	for _, bad := range []string{
+		"group-by=region",
+		"group-by=model,model",
+		"bucket=week",
+		"from=2026-01-02&to=2026-01-01",
+		"from=yesterday",
+	} {
+		if code, _ = get(bad); code != http.StatusBadRequest {
+			t.Fatalf("%s = %d, want 400", bad, code)
+		}
+	}
+	if len(store.queries) != 2 {
+		t.Fatalf("invalid queries reached the store: %d", len(store.queries))
+	}
+}
+
+func TestQueryUsageHistoryWithoutStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/usage/query", (&Handler{}).QueryUsageHistory)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/usage/query", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+}
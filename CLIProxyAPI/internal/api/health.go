@@ -24,6 +24,7 @@ type HealthChecker struct {
 	startTime    time.Time
 	mu           sync.RWMutex
 	providers    map[string]ProviderHealthChecker
+	prometheus   func() http.Handler
 }
 
 // ProviderHealthChecker defines an interface for checking upstream provider health
@@ -51,6 +52,14 @@ func (h *HealthChecker) SetDatabase(repo *db.Repo) {
 	h.dbRepo = repo
 }
 
+// SetPrometheusHandler sets the source of the Prometheus handler served on /metrics.
+// When it returns nil the legacy JSON metrics are served instead.
+func (h *HealthChecker) SetPrometheusHandler(fn func() http.Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prometheus = fn
+}
+
 // RegisterProvider registers a provider health checker
 func (h *HealthChecker) RegisterProvider(checker ProviderHealthChecker) {
 	h.mu.Lock()
@@ -315,9 +324,20 @@ func (h *HealthChecker) checkMemory() gin.H {
 	}
 }
 
-// Metrics returns basic server metrics
-// GET /metrics (deprecated - use Prometheus metrics instead)
+// Metrics serves the Prometheus registry when metrics are enabled and falls back to
+// basic JSON server metrics otherwise.
+// GET /metrics
 func (h *HealthChecker) Metrics(c *gin.Context) {
+	h.mu.RLock()
+	prometheus := h.prometheus
+	h.mu.RUnlock()
+	if prometheus != nil {
+		if handler := prometheus(); handler != nil {
+			handler.ServeHTTP(c.Writer, c.Request)
+			return
+		}
+	}
+
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
@@ -6,10 +6,10 @@ import (
 	"strings"
 )
 
-// IsHealthCheckPath checks if a path is a health check endpoint
+// IsHealthCheckPath checks if a path is a health check or metrics endpoint
 func IsHealthCheckPath(path string) bool {
 	return path == "/health" || path == "/healthz" || path == "/ready" || path == "/" ||
-		path == "/health/detail" || path == "/health/upstream"
+		path == "/health/detail" || path == "/health/upstream" || path == "/metrics"
 }
 
 // isHealthCheckPath is an internal alias for IsHealthCheckPath
@@ -39,6 +39,9 @@ type RateLimiter struct {
 	config RateLimiterConfig
 	// Map of client identifier -> tracking data
 	clients map[string]*clientTrack
+	// stop ends the cleanup goroutine
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 // NewRateLimiter creates a new rate limiter with the given configuration
@@ -56,6 +59,7 @@ func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
 	rl := &RateLimiter{
 		config: config,
 		clients: make(map[string]*clientTrack),
+		stop:    make(chan struct{}),
 	}
 
 	// Start cleanup goroutine
@@ -153,11 +157,21 @@ func (rl *RateLimiter) cleanupLoop() {
 	ticker := time.NewTicker(rl.config.CleanupInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		rl.cleanup()
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+			rl.cleanup()
+		}
 	}
 }
 
+// Stop ends the background cleanup goroutine. The limiter must not be used afterwards.
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+}
+
 // cleanup removes clients that haven't been seen recently
 func (rl *RateLimiter) cleanup() {
 	rl.mu.Lock()
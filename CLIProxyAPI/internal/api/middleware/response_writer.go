@@ -294,10 +294,15 @@ func (w *ResponseWriterWrapper) Finalize(c *gin.Context) error {
 			return err
 		}
 		w.streamWriter = nil
+		logging.MarkRequestLogWritten(c)
 		return nil
 	}
 
-	return w.logRequest(finalStatusCode, w.cloneHeaders(), w.body.Bytes(), w.extractAPIRequest(c), w.extractAPIResponse(c), slicesAPIResponseError, forceLog)
+	if err := w.logRequest(finalStatusCode, w.cloneHeaders(), w.body.Bytes(), w.extractAPIRequest(c), w.extractAPIResponse(c), slicesAPIResponseError, forceLog); err != nil {
+		return err
+	}
+	logging.MarkRequestLogWritten(c)
+	return nil
 }
 
 func (w *ResponseWriterWrapper) cloneHeaders() map[string][]string {
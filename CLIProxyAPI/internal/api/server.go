@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,15 +20,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/access"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/jwt_access"
 	managementHandlers "github.com/router-for-me/CLIProxyAPI/v6/internal/api/handlers/management"
 	apimiddleware "github.com/router-for-me/CLIProxyAPI/v6/internal/api/middleware"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules"
 	ampmodule "github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules/amp"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules/proxygrid"
+	jwtauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/jwt"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/production"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -41,6 +45,9 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// jwtRevocationsFile names the file that persists revoked JWT access tokens.
+const jwtRevocationsFile = "jwt-revocations.json"
+
 const oauthCallbackSuccessHTML = `<html><head><meta charset="utf-8"><title>Authentication successful</title><script>setTimeout(function(){window.close();},5000);</script></head><body><h1>Authentication successful!</h1><p>You can close this window.</p><p>This window will close automatically in 5 seconds.</p></body></html>`
 
 type serverOptionConfig struct {
@@ -173,6 +180,12 @@ type Server struct {
 	keepAliveStop      chan struct{}
 
 	allowedOrigins []string
+
+	// production holds the config-driven rate limiter, metrics collector and JWT manager.
+	production *atomic.Pointer[production.Components]
+
+	// jwtRevocations is shared by the JWT managers built on config reload.
+	jwtRevocations *jwtauth.Revocations
 }
 
 // Engine exposes the underlying Gin engine for tests and integrations.
@@ -214,9 +227,16 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	// Add middleware
 	engine.Use(logging.GinLogrusLogger())
 	engine.Use(logging.GinLogrusRecovery())
+	engine.Use(logging.RequestIndexMiddleware(logging.DefaultRequestIndex()))
 	for _, mw := range optionState.extraMiddleware {
 		engine.Use(mw)
 	}
+	// Validation, metrics, rate limiting and JWT middleware resolve the active production
+	// components per request so config reloads take effect without rebuilding the engine.
+	productionComponents := new(atomic.Pointer[production.Components])
+	for _, mw := range production.DynamicMiddleware(productionComponents.Load) {
+		engine.Use(mw)
+	}
 
 	// Add request logging middleware (positioned after recovery, before auth)
 	// Resolve logs directory relative to the configuration file directory.
@@ -255,8 +275,10 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 		currentPath:         wd,
 		envManagementSecret: envManagementSecret,
 		wsRoutes:            make(map[string]struct{}),
+		production:          productionComponents,
 	}
 	s.wsAuthEnabled.Store(cfg.WebsocketAuth)
+	s.applyProductionConfig(nil, cfg)
 	// Save initial YAML snapshot
 	s.oldConfigYaml, _ = yaml.Marshal(cfg)
 	s.applyAccessConfig(nil, cfg)
@@ -358,6 +380,7 @@ func (s *Server) setupRoutes() {
 
 	// Register health check routes (after middleware so headers apply)
 	healthChecker := NewHealthChecker(s.cfg, s.accessManager)
+	healthChecker.SetPrometheusHandler(s.prometheusHandler)
 	healthChecker.RegisterRoutes(s.engine)
 
 	s.engine.GET("/management.html", s.serveManagementControlPanel)
@@ -374,9 +397,12 @@ func (s *Server) setupRoutes() {
 		v1.GET("/models", s.unifiedModelsHandler(openaiHandlers, claudeCodeHandlers))
 		v1.POST("/chat/completions", openaiHandlers.ChatCompletions)
 		v1.POST("/completions", openaiHandlers.Completions)
+		v1.POST("/embeddings", openaiHandlers.Embeddings)
 		v1.POST("/messages", claudeCodeHandlers.ClaudeMessages)
 		v1.POST("/messages/count_tokens", claudeCodeHandlers.ClaudeCountTokens)
 		v1.POST("/responses", openaiResponsesHandlers.Responses)
+		v1.GET("/responses/:id", openaiResponsesHandlers.GetResponse)
+		v1.DELETE("/responses/:id", openaiResponsesHandlers.DeleteResponse)
 	}
 
 	// Gemini compatible API routes
@@ -399,6 +425,7 @@ func (s *Server) setupRoutes() {
 				"GET /metrics",
 				"POST /v1/chat/completions",
 				"POST /v1/completions",
+				"POST /v1/embeddings",
 				"GET /v1/models",
 			},
 		})
@@ -534,6 +561,7 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
 		mgmt.GET("/usage/export", s.mgmt.ExportUsageStatistics)
 		mgmt.POST("/usage/import", s.mgmt.ImportUsageStatistics)
+		mgmt.GET("/usage/query", s.mgmt.QueryUsageHistory)
 		mgmt.GET("/config", s.mgmt.GetConfig)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
@@ -575,6 +603,14 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.PATCH("/api-keys", s.mgmt.PatchAPIKeys)
 		mgmt.DELETE("/api-keys", s.mgmt.DeleteAPIKeys)
 
+		mgmt.GET("/client-keys", s.mgmt.ListClientKeys)
+		mgmt.POST("/client-keys", s.mgmt.CreateClientKey)
+		mgmt.DELETE("/client-keys/:id", s.mgmt.RevokeClientKey)
+		mgmt.POST("/client-keys/:id/rotate", s.mgmt.RotateClientKey)
+
+		mgmt.POST("/jwt-tokens", s.mgmt.IssueJWTToken)
+		mgmt.POST("/jwt-tokens/revoke", s.mgmt.RevokeJWTToken)
+
 		mgmt.GET("/gemini-api-key", s.mgmt.GetGeminiKeys)
 		mgmt.PUT("/gemini-api-key", s.mgmt.PutGeminiKeys)
 		mgmt.PATCH("/gemini-api-key", s.mgmt.PatchGeminiKey)
@@ -585,6 +621,9 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/request-error-logs", s.mgmt.GetRequestErrorLogs)
 		mgmt.GET("/request-error-logs/:name", s.mgmt.DownloadRequestErrorLog)
 		mgmt.GET("/request-log-by-id/:id", s.mgmt.GetRequestLogByID)
+		mgmt.GET("/request-index", s.mgmt.SearchRequestIndex)
+		mgmt.GET("/request-index/:id", s.mgmt.GetRequestIndexEntry)
+		mgmt.POST("/request-replay", s.mgmt.ReplayRequest)
 		mgmt.GET("/request-log", s.mgmt.GetRequestLog)
 		mgmt.PUT("/request-log", s.mgmt.PutRequestLog)
 		mgmt.PATCH("/request-log", s.mgmt.PutRequestLog)
@@ -666,6 +705,9 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
 		mgmt.DELETE("/auth-files", s.mgmt.DeleteAuthFile)
+		mgmt.PATCH("/auth-files/:index", s.mgmt.PatchAuthFile)
+		mgmt.POST("/auth-files/:index/reset-cooldown", s.mgmt.ResetAuthCooldown)
+		mgmt.POST("/auth-files/:index/refresh", s.mgmt.RefreshAuth)
 		mgmt.POST("/vertex/import", s.mgmt.ImportVertexCredential)
 
 		mgmt.GET("/anthropic-auth-url", s.mgmt.RequestAnthropicToken)
@@ -831,6 +873,14 @@ func (s *Server) Start() error {
 		if cert == "" || key == "" {
 			return fmt.Errorf("failed to start HTTPS server: tls.cert or tls.key is empty")
 		}
+		tlsConfig, errTLS := clientAuthTLSConfig(s.cfg.TLS)
+		if errTLS != nil {
+			return fmt.Errorf("failed to start HTTPS server: %v", errTLS)
+		}
+		if tlsConfig != nil {
+			s.server.TLSConfig = tlsConfig
+			log.Infof("TLS client certificate verification enabled (mode: %s)", tlsConfig.ClientAuth)
+		}
 		log.Debugf("Starting API server on %s with TLS", s.server.Addr)
 		if errServeTLS := s.server.ListenAndServeTLS(cert, key); errServeTLS != nil && !errors.Is(errServeTLS, http.ErrServerClosed) {
 			return fmt.Errorf("failed to start HTTPS server: %v", errServeTLS)
@@ -868,11 +918,80 @@ func (s *Server) Stop(ctx context.Context) error {
 	if err := s.server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown HTTP server: %v", err)
 	}
+	if components := s.production.Swap(nil); components != nil {
+		_ = components.Shutdown(ctx)
+	}
 
 	log.Debug("API server stopped")
 	return nil
 }
 
+// applyProductionConfig rebuilds the production components when their settings change and
+// retires the previous set. A nil oldCfg always builds them.
+func (s *Server) applyProductionConfig(oldCfg, newCfg *config.Config) {
+	if s == nil || s.production == nil || newCfg == nil {
+		return
+	}
+	if oldCfg != nil &&
+		oldCfg.RateLimit == newCfg.RateLimit &&
+		oldCfg.Metrics == newCfg.Metrics &&
+		oldCfg.Validation == newCfg.Validation &&
+		oldCfg.JWTSecret == newCfg.JWTSecret {
+		return
+	}
+	var opts []production.Option
+	if previous := s.production.Load(); oldCfg != nil && oldCfg.JWTSecret == newCfg.JWTSecret && previous != nil {
+		opts = append(opts, production.WithJWTManager(previous.JWTManager))
+	} else if newCfg.JWTSecret != "" {
+		opts = append(opts, production.WithRevocations(s.jwtRevocationList()))
+	}
+	components, err := production.SetupComponents(newCfg, opts...)
+	if err != nil {
+		log.Errorf("failed to set up production components: %v", err)
+		return
+	}
+	components.Start(context.Background())
+	// The jwt access provider validates and issues tokens with the same manager, so revocations
+	// are shared with the production JWT middleware.
+	jwtaccess.SetManager(components.JWTManager)
+	if previous := s.production.Swap(components); previous != nil {
+		_ = previous.Shutdown(context.Background())
+	}
+	if oldCfg != nil {
+		log.Infof("production middleware updated (rate-limit=%t, metrics=%t, jwt=%t)",
+			newCfg.RateLimit.Enabled, newCfg.Metrics.Enabled, newCfg.JWTSecret != "")
+	}
+}
+
+// jwtRevocationList returns the revocation list shared by every JWT manager the server builds.
+// It is stored next to the config file, or under WRITABLE_PATH when set, so revoked tokens stay
+// revoked across restarts and jwt-secret rotations.
+func (s *Server) jwtRevocationList() *jwtauth.Revocations {
+	if s.jwtRevocations != nil {
+		return s.jwtRevocations
+	}
+	var path string
+	if base := util.WritablePath(); base != "" {
+		path = filepath.Join(base, jwtRevocationsFile)
+	} else if s.configFilePath != "" {
+		path = filepath.Join(filepath.Dir(s.configFilePath), jwtRevocationsFile)
+	}
+	revocations, err := jwtauth.NewRevocations(path)
+	if err != nil {
+		log.Warnf("failed to load jwt revocations: %v", err)
+	}
+	s.jwtRevocations = revocations
+	return revocations
+}
+
+// prometheusHandler returns the Prometheus handler when metrics are enabled.
+func (s *Server) prometheusHandler() http.Handler {
+	if s == nil || s.production == nil {
+		return nil
+	}
+	return s.production.Load().MetricsHandler()
+}
+
 // corsMiddleware returns a Gin middleware handler that adds CORS headers
 // and security headers to every response.
 //
@@ -997,6 +1116,13 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	if oldCfg == nil || !reflect.DeepEqual(oldCfg.Pricing, cfg.Pricing) {
+		usage.SetPricing(cfg.Pricing)
+		if oldCfg != nil {
+			log.Debugf("pricing table updated (%d overrides)", len(cfg.Pricing))
+		}
+	}
+
 	if oldCfg == nil || oldCfg.UsageStatisticsEnabled != cfg.UsageStatisticsEnabled {
 		usage.SetStatisticsEnabled(cfg.UsageStatisticsEnabled)
 		if oldCfg != nil {
@@ -1061,6 +1187,7 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	}
 
 	s.applyAccessConfig(oldCfg, cfg)
+	s.applyProductionConfig(oldCfg, cfg)
 	s.cfg = cfg
 	s.wsAuthEnabled.Store(cfg.WebsocketAuth)
 	if oldCfg != nil && s.wsAuthChanged != nil && oldCfg.WebsocketAuth != cfg.WebsocketAuth {
@@ -1151,6 +1278,9 @@ func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 				if len(result.Metadata) > 0 {
 					c.Set("accessMetadata", result.Metadata)
 				}
+				if result.Policy != nil {
+					c.Set("accessPolicy", result.Policy)
+				}
 			}
 			c.Next()
 			return
@@ -1161,6 +1291,11 @@ func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing API key"})
 		case errors.Is(err, sdkaccess.ErrInvalidCredential):
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		case errors.Is(err, sdkaccess.ErrCredentialExpired):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key expired"})
+		case errors.Is(err, sdkaccess.ErrRateLimited):
+			c.Header("Retry-After", "60")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "API key rate limit exceeded"})
 		default:
 			log.Errorf("authentication middleware error: %v", err)
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Authentication service error"})
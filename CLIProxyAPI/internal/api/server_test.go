@@ -7,9 +7,11 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	gin "github.com/gin-gonic/gin"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/config_access"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/jwt_access"
 	proxyconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
@@ -111,3 +113,87 @@ func TestAmpProviderModelRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestProductionMiddlewareFollowsConfigReload(t *testing.T) {
+	server := newTestServer(t)
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-key")
+		rec := httptest.NewRecorder()
+		server.engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/v1/models"); rec.Header().Get("X-RateLimit-Limit") != "" {
+		t.Fatalf("rate limit header present while rate-limit is disabled")
+	}
+	if rec := get("/metrics"); !strings.Contains(rec.Body.String(), "uptime_seconds") {
+		t.Fatalf("/metrics without metrics enabled = %s, want legacy JSON", rec.Body.String())
+	}
+
+	cfg := *server.cfg
+	cfg.RemoteManagement.DisableControlPanel = true
+	cfg.RateLimit = proxyconfig.RateLimitConfig{Enabled: true, RequestsPerMinute: 1, Burst: 1}
+	cfg.Metrics = proxyconfig.MetricsConfig{Enabled: true}
+	server.UpdateClients(&cfg)
+
+	if rec := get("/v1/models"); rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Limit") != "1" {
+		t.Fatalf("first request = %d (limit %q), want 200 with rate limit headers", rec.Code, rec.Header().Get("X-RateLimit-Limit"))
+	}
+	if rec := get("/v1/models"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request = %d, want 429", rec.Code)
+	}
+	if rec := get("/metrics"); !strings.Contains(rec.Body.String(), "cliproxy_http_requests_total") {
+		t.Fatalf("/metrics did not serve the Prometheus registry: %s", rec.Body.String())
+	}
+	if rec := get("/metrics"); !strings.Contains(rec.Body.String(), `status="429"`) {
+		t.Fatalf("rate limited request missing from metrics: %s", rec.Body.String())
+	}
+
+	// A custom metrics path set on reload is served without re-registering routes.
+	if rec := get("/internal/prom"); rec.Code == http.StatusOK {
+		t.Fatal("/internal/prom served before it was configured")
+	}
+	cfg.RateLimit = proxyconfig.RateLimitConfig{}
+	cfg.Metrics.Path = "/internal/prom"
+	server.UpdateClients(&cfg)
+	if rec := get("/internal/prom"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "cliproxy_http_requests_total") {
+		t.Fatalf("/internal/prom after reload = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestJWTRevocationsSurviveReloadAndRestart(t *testing.T) {
+	server := newTestServer(t)
+	cfg := *server.cfg
+	cfg.JWTSecret = "test-secret"
+	server.UpdateClients(&cfg)
+
+	m := jwtaccess.Manager()
+	if m == nil {
+		t.Fatal("jwt manager not installed")
+	}
+	token, _, err := jwtaccess.IssueToken(m, "ci-job", time.Minute, nil, nil)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if err = m.RevokeToken(token); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+
+	reloaded := cfg
+	reloaded.RateLimit = proxyconfig.RateLimitConfig{Enabled: true, RequestsPerMinute: 100}
+	server.UpdateClients(&reloaded)
+	if jwtaccess.Manager() != m {
+		t.Fatal("jwt manager rebuilt although jwt-secret did not change")
+	}
+
+	// A new server reading the same config directory stands in for a restart.
+	restarted := NewServer(&reloaded, auth.NewManager(nil, nil, nil), sdkaccess.NewManager(), server.configFilePath)
+	defer jwtaccess.SetManager(nil)
+	if restarted.production.Load().JWTManager == m {
+		t.Fatal("restarted server reused the previous manager")
+	}
+	if _, err = jwtaccess.Manager().ValidateToken(token); err == nil {
+		t.Fatal("revoked token valid again after restart")
+	}
+}
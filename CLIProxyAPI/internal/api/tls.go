@@ -0,0 +1,42 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// clientAuthTLSConfig builds the server TLS settings for client certificate verification.
+// It returns nil when no client CA is configured.
+func clientAuthTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	caPath := strings.TrimSpace(cfg.ClientCA)
+	if caPath == "" {
+		return nil, nil
+	}
+	var mode tls.ClientAuthType
+	switch strings.ToLower(strings.TrimSpace(cfg.ClientAuth)) {
+	case "", config.TLSClientAuthOptional:
+		mode = tls.VerifyClientCertIfGiven
+	case config.TLSClientAuthRequire:
+		mode = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("tls.client-auth must be %q or %q, got %q", config.TLSClientAuthOptional, config.TLSClientAuthRequire, cfg.ClientAuth)
+	}
+	pemData, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("read tls.client-ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("tls.client-ca %s contains no PEM certificates", caPath)
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientCAs:  pool,
+		ClientAuth: mode,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestClientAuthTLSConfig(t *testing.T) {
+	if cfg, err := clientAuthTLSConfig(config.TLSConfig{}); cfg != nil || err != nil {
+		t.Fatalf("no client-ca = %v, %v; want nil, nil", cfg, err)
+	}
+
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientAuthTLSConfig(config.TLSConfig{ClientCA: empty}); err == nil {
+		t.Fatal("expected error for bundle without certificates")
+	}
+	if _, err := clientAuthTLSConfig(config.TLSConfig{ClientCA: empty, ClientAuth: "always"}); err == nil {
+		t.Fatal("expected error for unknown client-auth mode")
+	}
+
+	bundle := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(bundle, selfSignedCAPEM(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := clientAuthTLSConfig(config.TLSConfig{ClientCA: bundle, ClientAuth: "require"})
+	if err != nil {
+		t.Fatalf("clientAuthTLSConfig() error = %v", err)
+	}
+	if cfg.ClientAuth != tls.RequireAndVerifyClientCert || cfg.ClientCAs == nil {
+		t.Fatalf("tls config = %+v", cfg)
+	}
+}
+
+func selfSignedCAPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
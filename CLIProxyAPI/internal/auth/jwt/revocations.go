@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Revocations records revoked tokens until they expire. It lives outside Manager so that
+// revocations survive a manager rebuild, and it is persisted to a file when a path is set so
+// that they also survive a restart. Tokens are keyed by their SHA-256 digest.
+type Revocations struct {
+	mu      sync.RWMutex
+	path    string
+	entries map[string]time.Time
+}
+
+// NewRevocations returns a revocation list backed by path. An empty path keeps the list in
+// memory only. Entries already on disk are loaded; a missing file is not an error.
+func NewRevocations(path string) (*Revocations, error) {
+	r := &Revocations{path: path, entries: make(map[string]time.Time)}
+	if path == "" {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, fmt.Errorf("read jwt revocations: %w", err)
+	}
+	if err = json.Unmarshal(data, &r.entries); err != nil {
+		r.entries = make(map[string]time.Time)
+		return r, fmt.Errorf("parse jwt revocations: %w", err)
+	}
+	r.pruneLocked(time.Now())
+	return r, nil
+}
+
+// Add revokes token until expiry.
+func (r *Revocations) Add(token string, expiry time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[tokenDigest(token)] = expiry
+	return r.saveLocked()
+}
+
+// Revoked reports whether token was revoked and has not expired yet.
+func (r *Revocations) Revoked(token string) bool {
+	r.mu.RLock()
+	expiry, ok := r.entries[tokenDigest(token)]
+	r.mu.RUnlock()
+	return ok && time.Now().Before(expiry)
+}
+
+// Clean drops entries whose tokens have expired.
+func (r *Revocations) Clean() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.pruneLocked(time.Now()) {
+		return nil
+	}
+	return r.saveLocked()
+}
+
+func (r *Revocations) pruneLocked(now time.Time) bool {
+	pruned := false
+	for digest, expiry := range r.entries {
+		if now.After(expiry) {
+			delete(r.entries, digest)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// saveLocked writes the entries through a temporary file so a crash never leaves a torn list.
+func (r *Revocations) saveLocked() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(r.entries)
+	if err != nil {
+		return fmt.Errorf("encode jwt revocations: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return fmt.Errorf("write jwt revocations: %w", err)
+	}
+	tmp := r.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write jwt revocations: %w", err)
+	}
+	if err = os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("write jwt revocations: %w", err)
+	}
+	return nil
+}
+
+func tokenDigest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -33,7 +33,7 @@ type Manager struct {
 	accessDuration  time.Duration
 	refreshDuration time.Duration
 	mu              sync.RWMutex
-	blacklist       map[string]time.Time
+	revocations     *Revocations
 }
 
 // Config holds JWT configuration
@@ -43,6 +43,8 @@ type Config struct {
 	AccessDuration   time.Duration
 	RefreshDuration  time.Duration
 	SigningAlgorithm string
+	// Revocations holds revoked tokens; nil keeps them in a list private to the manager.
+	Revocations *Revocations
 }
 
 // DefaultConfig returns sensible defaults for JWT configuration
@@ -78,23 +80,30 @@ func NewManager(cfg Config) (*Manager, error) {
 		cfg.RefreshDuration = 7 * 24 * time.Hour
 	}
 
+	revocations := cfg.Revocations
+	if revocations == nil {
+		revocations, _ = NewRevocations("")
+	}
+
 	return &Manager{
 		secretKey:       []byte(cfg.SecretKey),
 		issuer:          cfg.Issuer,
 		accessDuration:  cfg.AccessDuration,
 		refreshDuration: cfg.RefreshDuration,
-		blacklist:       make(map[string]time.Time),
+		revocations:     revocations,
 	}, nil
 }
 
-// NewManagerFromConfig creates a JWT manager from application config
-func NewManagerFromConfig(cfg *config.Config) (*Manager, error) {
+// NewManagerFromConfig creates a JWT manager from application config. revocations is shared
+// across managers so rebuilding one keeps revoked tokens revoked; nil uses a private list.
+func NewManagerFromConfig(cfg *config.Config, revocations *Revocations) (*Manager, error) {
 	jwtCfg := Config{
 		SecretKey:        cfg.JWTSecret,
 		Issuer:           "cliproxy-api",
 		AccessDuration:   time.Hour,
 		RefreshDuration:  7 * 24 * time.Hour,
 		SigningAlgorithm: "HS256",
+		Revocations:      revocations,
 	}
 	return NewManager(jwtCfg)
 }
@@ -148,6 +157,12 @@ func (m *Manager) GenerateTokenPair(userID, apiKey string, additionalClaims map[
 
 // generateToken generates a JWT token with the given parameters
 func (m *Manager) generateToken(userID, apiKey, tokenType string, duration time.Duration, additionalClaims map[string]interface{}) (string, error) {
+	tokenString, _, err := m.generateTokenWithExpiry(userID, apiKey, tokenType, duration, additionalClaims)
+	return tokenString, err
+}
+
+// generateTokenWithExpiry generates a JWT token and also returns its expiry time
+func (m *Manager) generateTokenWithExpiry(userID, apiKey, tokenType string, duration time.Duration, additionalClaims map[string]interface{}) (string, time.Time, error) {
 	now := time.Now()
 	expiryTime := now.Add(duration)
 
@@ -188,10 +203,10 @@ func (m *Manager) generateToken(userID, apiKey, tokenType string, duration time.
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(m.secretKey)
 	if err != nil {
-		return "", fmt.Errorf("sign token: %w", err)
+		return "", time.Time{}, fmt.Errorf("sign token: %w", err)
 	}
 
-	return tokenString, nil
+	return tokenString, claims.ExpiresAt.Time, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
@@ -216,15 +231,9 @@ func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	// Check if token is blacklisted
-	m.mu.RLock()
-	if expiry, blacklisted := m.blacklist[tokenString]; blacklisted {
-		m.mu.RUnlock()
-		if time.Now().Before(expiry) {
-			return nil, ErrInvalidToken
-		}
-	} else {
-		m.mu.RUnlock()
+	// Check if token is revoked
+	if m.revocations.Revoked(tokenString) {
+		return nil, ErrInvalidToken
 	}
 
 	// Verify issuer
@@ -270,18 +279,17 @@ func (m *Manager) RefreshAccessToken(refreshToken string) (string, error) {
 	return m.GenerateAccessToken(claims.UserID, claims.APIKey, additionalClaims)
 }
 
-// RevokeToken adds a token to the blacklist
+// RevokeToken adds a token to the revocation list
 func (m *Manager) RevokeToken(tokenString string) error {
 	claims, err := m.ValidateToken(tokenString)
 	if err != nil {
 		return err
 	}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	// Blacklist until the token expires naturally
-	m.blacklist[tokenString] = claims.ExpiresAt.Time
+	// Revoke until the token expires naturally. A failed write still revokes the token in memory.
+	if err := m.revocations.Add(tokenString, claims.ExpiresAt.Time); err != nil {
+		log.WithError(err).Warn("Failed to persist JWT revocation")
+	}
 
 	log.WithFields(log.Fields{
 		"user_id":    claims.UserID,
@@ -292,16 +300,10 @@ func (m *Manager) RevokeToken(tokenString string) error {
 	return nil
 }
 
-// CleanExpiredTokens removes expired tokens from the blacklist
+// CleanExpiredTokens removes expired tokens from the revocation list
 func (m *Manager) CleanExpiredTokens() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	now := time.Now()
-	for token, expiry := range m.blacklist {
-		if now.After(expiry) {
-			delete(m.blacklist, token)
-		}
+	if err := m.revocations.Clean(); err != nil {
+		log.WithError(err).Warn("Failed to persist JWT revocations")
 	}
 }
 
@@ -427,6 +429,18 @@ func (m *Manager) GenerateAPISessionToken(apiKey string, authID, provider string
 	return m.GenerateAccessToken(apiKey, apiKey, additionalClaims)
 }
 
+// GenerateScopedToken issues an access token for subject that expires after ttl. Extra claims are
+// stored in the token metadata.
+func (m *Manager) GenerateScopedToken(subject string, ttl time.Duration, claims map[string]interface{}) (string, time.Time, error) {
+	if subject == "" {
+		return "", time.Time{}, errors.New("subject is required")
+	}
+	if ttl <= 0 {
+		ttl = m.accessDuration
+	}
+	return m.generateTokenWithExpiry(subject, "", "access", ttl, claims)
+}
+
 // ValidateAPISessionToken validates an API session token
 func (m *Manager) ValidateAPISessionToken(tokenString string) (apiKey, authID, provider string, err error) {
 	claims, err := m.ValidateToken(tokenString)
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -53,7 +54,8 @@ type Config struct {
 	Interval time.Duration
 	// Timeout is the duration to wait after opening before trying to recover
 	Timeout time.Duration
-	// ReadyToTrip is called when a request fails and determines if the breaker should trip
+	// ReadyToTrip is called when a request fails and determines if the breaker should trip.
+	// When nil, the breaker trips after FailureThreshold consecutive failures.
 	ReadyToTrip func(counts Counts) bool
 	// OnStateChange is called whenever the state changes
 	OnStateChange func(name string, from State, to State)
@@ -70,21 +72,43 @@ type Config struct {
 // DefaultConfig returns sensible defaults for circuit breaker configuration
 func DefaultConfig() Config {
 	return Config{
-		MaxRequests:       1,
-		Interval:          0, // Disabled
-		Timeout:           60 * time.Second,
-		ReadyToTrip:       defaultReadyToTrip,
-		OnStateChange:     defaultOnStateChange,
-		IsSuccessful:      defaultIsSuccessful,
-		FailureThreshold:  5,
-		SuccessThreshold:  2,
-		MonitoringPeriod:  60 * time.Second,
+		MaxRequests:      1,
+		Interval:         0, // Disabled
+		Timeout:          60 * time.Second,
+		OnStateChange:    defaultOnStateChange,
+		IsSuccessful:     defaultIsSuccessful,
+		FailureThreshold: 5,
+		SuccessThreshold: 2,
+		MonitoringPeriod: 60 * time.Second,
+	}
+}
+
+// ConfigFromSettings converts the circuit-breaker section of config.yaml into a breaker Config.
+// Zero or unparsable values keep the defaults from DefaultConfig.
+func ConfigFromSettings(settings config.CircuitBreakerConfig) Config {
+	cfg := DefaultConfig()
+	if settings.FailureThreshold > 0 {
+		cfg.FailureThreshold = settings.FailureThreshold
+	}
+	if settings.SuccessThreshold > 0 {
+		cfg.SuccessThreshold = settings.SuccessThreshold
+	}
+	if settings.Timeout != "" {
+		if duration, err := time.ParseDuration(settings.Timeout); err == nil && duration > 0 {
+			cfg.Timeout = duration
+		}
+	}
+	if settings.HalfOpenMaxRequests > 0 {
+		cfg.MaxRequests = uint32(settings.HalfOpenMaxRequests)
 	}
+	return cfg
 }
 
-// defaultReadyToTrip trips the breaker when there are more failures than successes
-func defaultReadyToTrip(counts Counts) bool {
-	return counts.ConsecutiveFailures > 5
+// thresholdReadyToTrip trips the breaker once consecutive failures reach the threshold
+func thresholdReadyToTrip(threshold int) func(counts Counts) bool {
+	return func(counts Counts) bool {
+		return int(counts.ConsecutiveFailures) >= threshold
+	}
 }
 
 // defaultOnStateChange logs state changes
@@ -112,38 +136,36 @@ type Counts struct {
 
 // CircuitBreaker implements the circuit breaker pattern
 type CircuitBreaker struct {
-	name          string
-	cfg           Config
-	state         State
-	generation    uint64
-	counts        Counts
-	expiry        time.Time
-	mu            sync.Mutex
-	lastFailure   time.Time
-	lastSuccess   time.Time
-	requestCount  uint32
-	requests      map[string]*RequestMetrics
+	name         string
+	cfg          Config
+	state        State
+	generation   uint64
+	counts       Counts
+	expiry       time.Time
+	mu           sync.Mutex
+	lastFailure  time.Time
+	lastSuccess  time.Time
+	requestCount uint32
+	inFlight     uint32
+	requests     map[string]*RequestMetrics
 }
 
 // RequestMetrics tracks metrics for specific upstream endpoints
 type RequestMetrics struct {
-	TotalRequests     uint64
-	FailedRequests    uint64
-	SuccessRequests   uint64
-	LastError         error
-	LastErrorTime     time.Time
-	LastSuccessTime   time.Time
-	AverageLatency    time.Duration
-	TotalLatency      time.Duration
-	FailureRate       float64
-	mu                sync.RWMutex
+	TotalRequests   uint64
+	FailedRequests  uint64
+	SuccessRequests uint64
+	LastError       error
+	LastErrorTime   time.Time
+	LastSuccessTime time.Time
+	AverageLatency  time.Duration
+	TotalLatency    time.Duration
+	FailureRate     float64
+	mu              sync.RWMutex
 }
 
 // NewCircuitBreaker creates a new circuit breaker with the given configuration
 func NewCircuitBreaker(name string, cfg Config) *CircuitBreaker {
-	if cfg.ReadyToTrip == nil {
-		cfg.ReadyToTrip = defaultReadyToTrip
-	}
 	if cfg.OnStateChange == nil {
 		cfg.OnStateChange = defaultOnStateChange
 	}
@@ -162,6 +184,9 @@ func NewCircuitBreaker(name string, cfg Config) *CircuitBreaker {
 	if cfg.Timeout <= 0 {
 		cfg.Timeout = 60 * time.Second
 	}
+	if cfg.ReadyToTrip == nil {
+		cfg.ReadyToTrip = thresholdReadyToTrip(cfg.FailureThreshold)
+	}
 
 	return &CircuitBreaker{
 		name:     name,
@@ -191,8 +216,9 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, req func() error) error {
 	}
 
 	// Execute the request
-	defer cb.onDone(generation, &err)
-	return req()
+	err = req()
+	cb.onDone(generation, &err)
+	return err
 }
 
 // ExecuteWithResult runs the given function and returns its result
@@ -225,14 +251,72 @@ func (cb *CircuitBreaker) allow() (uint64, error) {
 		cb.setState(now, StateHalfOpen)
 	}
 
-	if cb.cfg.MaxRequests > 0 && cb.counts.Requests >= cb.cfg.MaxRequests {
-		return 0, ErrTooManyRequests
+	if cb.state == StateHalfOpen {
+		if cb.cfg.MaxRequests > 0 && cb.inFlight >= cb.cfg.MaxRequests {
+			return 0, ErrTooManyRequests
+		}
+		cb.inFlight++
 	}
 
 	cb.counts.Requests++
 	return cb.generation, nil
 }
 
+// Ready reports whether the breaker would currently admit a request.
+// Unlike Allow it does not change state or counters, so callers can use it to filter candidates.
+func (cb *CircuitBreaker) Ready() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	state, expiry := cb.currentState(now)
+	switch state {
+	case StateOpen:
+		return !now.Before(expiry)
+	case StateHalfOpen:
+		return cb.cfg.MaxRequests == 0 || cb.inFlight < cb.cfg.MaxRequests
+	default:
+		return true
+	}
+}
+
+// Allow admits a single request, moving an expired open breaker to half-open.
+// Every successful Allow must be followed by either Record or Release.
+func (cb *CircuitBreaker) Allow() error {
+	_, err := cb.allow()
+	return err
+}
+
+// Release returns an admission obtained from Allow without recording an outcome.
+func (cb *CircuitBreaker) Release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.releaseSlot()
+}
+
+// Record reports the outcome of a request admitted through Allow.
+func (cb *CircuitBreaker) Record(success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	state, _ := cb.currentState(now)
+	cb.releaseSlot()
+	if success {
+		cb.onSuccess(state, now)
+	} else {
+		cb.onFailure(state, now, nil)
+	}
+}
+
+// releaseSlot frees a half-open admission slot; callers must hold cb.mu
+func (cb *CircuitBreaker) releaseSlot() {
+	if cb.inFlight > 0 {
+		cb.inFlight--
+	}
+}
+
 // onDone updates the circuit breaker state after request completion
 func (cb *CircuitBreaker) onDone(before uint64, err *error) {
 	cb.mu.Lock()
@@ -244,6 +328,7 @@ func (cb *CircuitBreaker) onDone(before uint64, err *error) {
 	if before != cb.generation {
 		return
 	}
+	cb.releaseSlot()
 
 	if cb.cfg.IsSuccessful(*err) {
 		cb.onSuccess(state, now)
@@ -271,7 +356,7 @@ func (cb *CircuitBreaker) onFailure(state State, now time.Time, err error) {
 	cb.counts.ConsecutiveSuccesses = 0
 	cb.lastFailure = now
 
-	if cb.cfg.ReadyToTrip(cb.counts) {
+	if state == StateHalfOpen || cb.cfg.ReadyToTrip(cb.counts) {
 		cb.setState(now, StateOpen)
 	}
 }
@@ -322,6 +407,7 @@ func (cb *CircuitBreaker) setState(now time.Time, newState State) {
 	cb.counts.Requests = 0
 	cb.counts.ConsecutiveSuccesses = 0
 	cb.counts.ConsecutiveFailures = 0
+	cb.inFlight = 0
 
 	if cb.cfg.OnStateChange != nil {
 		cb.cfg.OnStateChange(cb.name, oldState, newState)
@@ -343,7 +429,41 @@ func (cb *CircuitBreaker) GetMetrics() map[string]interface{} {
 		"consecutive_failures":  cb.counts.ConsecutiveFailures,
 		"last_failure":          cb.lastFailure,
 		"last_success":          cb.lastSuccess,
+		"open_until":            cb.openUntil(),
+	}
+}
+
+// Status is a point-in-time view of a breaker suitable for JSON responses.
+type Status struct {
+	State               string    `json:"state"`
+	ConsecutiveFailures uint32    `json:"consecutive_failures"`
+	TotalFailures       uint32    `json:"total_failures"`
+	TotalSuccesses      uint32    `json:"total_successes"`
+	OpenUntil           time.Time `json:"open_until,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitempty"`
+}
+
+// Status returns a snapshot of the breaker state and counters.
+func (cb *CircuitBreaker) Status() Status {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return Status{
+		State:               cb.state.String(),
+		ConsecutiveFailures: cb.counts.ConsecutiveFailures,
+		TotalFailures:       cb.counts.TotalFailures,
+		TotalSuccesses:      cb.counts.TotalSuccesses,
+		OpenUntil:           cb.openUntil(),
+		LastFailure:         cb.lastFailure,
+	}
+}
+
+// openUntil returns the recovery deadline while open; callers must hold cb.mu
+func (cb *CircuitBreaker) openUntil() time.Time {
+	if cb.state != StateOpen {
+		return time.Time{}
 	}
+	return cb.expiry
 }
 
 // RecordUpstreamRequest records metrics for a specific upstream endpoint
@@ -387,14 +507,14 @@ func (cb *CircuitBreaker) GetUpstreamMetrics(upstream string) *RequestMetrics {
 
 		// Return a copy to avoid race conditions
 		return &RequestMetrics{
-			TotalRequests:     metrics.TotalRequests,
-			FailedRequests:    metrics.FailedRequests,
-			SuccessRequests:   metrics.SuccessRequests,
-			LastError:         metrics.LastError,
-			LastErrorTime:     metrics.LastErrorTime,
-			LastSuccessTime:   metrics.LastSuccessTime,
-			AverageLatency:    metrics.AverageLatency,
-			FailureRate:       metrics.FailureRate,
+			TotalRequests:   metrics.TotalRequests,
+			FailedRequests:  metrics.FailedRequests,
+			SuccessRequests: metrics.SuccessRequests,
+			LastError:       metrics.LastError,
+			LastErrorTime:   metrics.LastErrorTime,
+			LastSuccessTime: metrics.LastSuccessTime,
+			AverageLatency:  metrics.AverageLatency,
+			FailureRate:     metrics.FailureRate,
 		}
 	}
 
@@ -410,6 +530,7 @@ func (cb *CircuitBreaker) Reset() {
 	cb.generation++
 	cb.counts = Counts{}
 	cb.expiry = time.Time{}
+	cb.inFlight = 0
 	cb.requests = make(map[string]*RequestMetrics)
 }
 
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/schedule"
 	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
 )
@@ -55,6 +56,9 @@ type Config struct {
 	// UsageStatisticsEnabled toggles in-memory usage aggregation; when false, usage data is discarded.
 	UsageStatisticsEnabled bool `yaml:"usage-statistics-enabled" json:"usage-statistics-enabled"`
 
+	// Pricing overrides or extends the built-in per-model list prices used for usage cost accounting.
+	Pricing []ModelPrice `yaml:"pricing,omitempty" json:"pricing,omitempty"`
+
 	// DisableCooling disables quota cooldown scheduling when true.
 	DisableCooling bool `yaml:"disable-cooling" json:"disable-cooling"`
 
@@ -117,6 +121,9 @@ type Config struct {
 	// CircuitBreaker holds circuit breaker configuration.
 	CircuitBreaker CircuitBreakerConfig `yaml:"circuit-breaker" json:"circuit-breaker"`
 
+	// HealthProbe holds background credential health probe configuration.
+	HealthProbe HealthProbeConfig `yaml:"health-probe" json:"health-probe"`
+
 	// Metrics holds Prometheus metrics configuration.
 	Metrics MetricsConfig `yaml:"metrics" json:"metrics"`
 
@@ -137,6 +144,32 @@ type TLSConfig struct {
 	Cert string `yaml:"cert" json:"cert"`
 	// Key is the path to the TLS private key file.
 	Key string `yaml:"key" json:"key"`
+	// ClientCA is the path to a PEM bundle of CAs trusted to sign client certificates.
+	ClientCA string `yaml:"client-ca,omitempty" json:"client-ca,omitempty"`
+	// ClientAuth selects client certificate verification when ClientCA is set:
+	// "optional" verifies a certificate when one is presented, "require" rejects handshakes
+	// without one. Default is "optional".
+	ClientAuth string `yaml:"client-auth,omitempty" json:"client-auth,omitempty"`
+}
+
+// Client certificate verification modes for TLSConfig.ClientAuth.
+const (
+	TLSClientAuthOptional = "optional"
+	TLSClientAuthRequire  = "require"
+)
+
+// ModelPrice is the list price of a model in USD per million tokens.
+type ModelPrice struct {
+	// Model is the model name; "*" wildcards match families such as "claude-sonnet-4*".
+	Model string `yaml:"model" json:"model"`
+	// Input is the price of uncached input tokens.
+	Input float64 `yaml:"input" json:"input"`
+	// Output is the price of output tokens.
+	Output float64 `yaml:"output" json:"output"`
+	// CachedInput is the price of input tokens served from the prompt cache. Zero uses Input.
+	CachedInput float64 `yaml:"cached-input,omitempty" json:"cached-input,omitempty"`
+	// Reasoning is the price of reasoning tokens. Zero uses Output.
+	Reasoning float64 `yaml:"reasoning,omitempty" json:"reasoning,omitempty"`
 }
 
 // RemoteManagement holds management API configuration under 'remote-management'.
@@ -165,8 +198,45 @@ type QuotaExceeded struct {
 // RoutingConfig configures how credentials are selected for requests.
 type RoutingConfig struct {
 	// Strategy selects the credential selection strategy.
-	// Supported values: "round-robin" (default), "fill-first".
+	// Supported values: "round-robin" (default), "fill-first", "least-loaded", "weighted".
+	// Every strategy drains the highest credential priority tier before falling back to lower tiers.
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// SessionAffinity pins requests from the same client session to the same credential.
+	SessionAffinity SessionAffinityConfig `yaml:"session-affinity,omitempty" json:"session-affinity,omitempty"`
+
+	// Concurrency caps the requests in flight per credential and queues requests while all are saturated.
+	Concurrency ConcurrencyConfig `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// RateLimitHeadroom skips credentials before the provider reported rate limits run out.
+	RateLimitHeadroom RateLimitHeadroomConfig `yaml:"rate-limit-headroom,omitempty" json:"rate-limit-headroom,omitempty"`
+}
+
+// RateLimitHeadroomConfig sets when a provider reported rate limit window counts as exhausted.
+type RateLimitHeadroomConfig struct {
+	// MinRemaining skips a credential once a window reports this many or fewer requests or tokens left (default: 0).
+	MinRemaining int64 `yaml:"min-remaining,omitempty" json:"min-remaining,omitempty"`
+	// MaxUsedPercent skips a credential once a window is at least this percent used (default: 100).
+	MaxUsedPercent float64 `yaml:"max-used-percent,omitempty" json:"max-used-percent,omitempty"`
+}
+
+// ConcurrencyConfig configures per-credential concurrency limits.
+type ConcurrencyConfig struct {
+	// ProviderDefaults maps a provider (e.g. "claude", "codex") to the max-concurrency applied to
+	// its credentials that do not set their own. Providers without an entry are unlimited.
+	ProviderDefaults map[string]int `yaml:"provider-defaults,omitempty" json:"provider-defaults,omitempty"`
+	// QueueSize bounds how many requests may wait for a free credential (default: 64).
+	QueueSize int `yaml:"queue-size,omitempty" json:"queue-size,omitempty"`
+	// QueueTimeout is how long a request waits for a free credential before failing (default: 30s).
+	QueueTimeout string `yaml:"queue-timeout,omitempty" json:"queue-timeout,omitempty"`
+}
+
+// SessionAffinityConfig configures sticky credential selection for client sessions.
+type SessionAffinityConfig struct {
+	// Enabled determines if session affinity is active.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// TTL is how long an idle session stays bound to its credential (default: 1h).
+	TTL string `yaml:"ttl,omitempty" json:"ttl,omitempty"`
 }
 
 // ModelNameMapping defines a model ID mapping for a specific channel.
@@ -282,6 +352,19 @@ type CircuitBreakerConfig struct {
 	HalfOpenMaxRequests int `yaml:"half-open-max-requests" json:"half-open-max-requests"`
 }
 
+// HealthProbeConfig configures background probes that quarantine credentials the provider rejects.
+type HealthProbeConfig struct {
+	// Enabled determines if health probes run.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Interval is the time between probe rounds (default: 10m).
+	Interval string `yaml:"interval" json:"interval"`
+	// Timeout bounds a single probe request (default: 30s).
+	Timeout string `yaml:"timeout" json:"timeout"`
+	// Models maps a provider to the model probed for its credentials. Providers without an entry
+	// use the first model registered for the credential.
+	Models map[string]string `yaml:"models,omitempty" json:"models,omitempty"`
+}
+
 // MetricsConfig holds Prometheus metrics configuration.
 type MetricsConfig struct {
 	// Enabled determines if metrics collection is active.
@@ -350,6 +433,18 @@ type ClaudeKey struct {
 
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Priority places this credential in a routing tier; higher tiers are drained before lower ones.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Weight sets the relative share of traffic this credential receives within its priority tier.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// MaxConcurrency caps the requests in flight on this credential; 0 falls back to the provider default.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Schedule restricts the credential to active windows, e.g. "weekdays 18:00-08:00 UTC" or "never on mondays".
+	Schedule []string `yaml:"schedule,omitempty" json:"schedule,omitempty"`
 }
 
 // ClaudeModel describes a mapping between an alias and the actual upstream model name.
@@ -388,6 +483,18 @@ type CodexKey struct {
 
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Priority places this credential in a routing tier; higher tiers are drained before lower ones.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Weight sets the relative share of traffic this credential receives within its priority tier.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// MaxConcurrency caps the requests in flight on this credential; 0 falls back to the provider default.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Schedule restricts the credential to active windows, e.g. "weekdays 18:00-08:00 UTC" or "never on mondays".
+	Schedule []string `yaml:"schedule,omitempty" json:"schedule,omitempty"`
 }
 
 // CodexModel describes a mapping between an alias and the actual upstream model name.
@@ -425,6 +532,18 @@ type GeminiKey struct {
 
 	// ExcludedModels lists model IDs that should be excluded for this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// Priority places this credential in a routing tier; higher tiers are drained before lower ones.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Weight sets the relative share of traffic this credential receives within its priority tier.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// MaxConcurrency caps the requests in flight on this credential; 0 falls back to the provider default.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Schedule restricts the credential to active windows, e.g. "weekdays 18:00-08:00 UTC" or "never on mondays".
+	Schedule []string `yaml:"schedule,omitempty" json:"schedule,omitempty"`
 }
 
 // GeminiModel describes a mapping between an alias and the actual upstream model name.
@@ -468,6 +587,18 @@ type OpenAICompatibilityAPIKey struct {
 
 	// ProxyURL overrides the global proxy setting for this API key if provided.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Priority places this credential in a routing tier; higher tiers are drained before lower ones.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Weight sets the relative share of traffic this credential receives within its priority tier.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// MaxConcurrency caps the requests in flight on this credential; 0 falls back to the provider default.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Schedule restricts the credential to active windows, e.g. "weekdays 18:00-08:00 UTC" or "never on mondays".
+	Schedule []string `yaml:"schedule,omitempty" json:"schedule,omitempty"`
 }
 
 // OpenAICompatibilityModel represents a model configuration for OpenAI compatibility,
@@ -603,6 +734,14 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Normalize global OAuth model name mappings.
 	cfg.SanitizeOAuthModelMappings()
 
+	// Normalize cross-model fallback chains.
+	cfg.SanitizeModelFallbacks()
+
+	// Reject invalid credential schedules instead of running the credential around the clock.
+	if err = cfg.ValidateSchedules(); err != nil {
+		return nil, err
+	}
+
 	if cfg.legacyMigrationPending {
 		fmt.Println("Detected legacy configuration keys, attempting to persist the normalized config...")
 		if !optional && configFile != "" {
@@ -720,6 +859,48 @@ func (cfg *Config) SanitizeClaudeKeys() {
 	}
 }
 
+// ValidateSchedules reports the first credential whose schedule rules do not parse.
+func (cfg *Config) ValidateSchedules() error {
+	if cfg == nil {
+		return nil
+	}
+	check := func(section string, index int, rules []string) error {
+		if _, err := schedule.Parse(rules); err != nil {
+			return fmt.Errorf("%s[%d] schedule: %w", section, index, err)
+		}
+		return nil
+	}
+	for i := range cfg.GeminiKey {
+		if err := check("gemini-api-key", i, cfg.GeminiKey[i].Schedule); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.ClaudeKey {
+		if err := check("claude-api-key", i, cfg.ClaudeKey[i].Schedule); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.CodexKey {
+		if err := check("codex-api-key", i, cfg.CodexKey[i].Schedule); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.VertexCompatAPIKey {
+		if err := check("vertex-api-key", i, cfg.VertexCompatAPIKey[i].Schedule); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.OpenAICompatibility {
+		compat := &cfg.OpenAICompatibility[i]
+		for j := range compat.APIKeyEntries {
+			if err := check(fmt.Sprintf("openai-compatibility[%d].api-key-entries", i), j, compat.APIKeyEntries[j].Schedule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // SanitizeGeminiKeys deduplicates and normalizes Gemini credentials.
 func (cfg *Config) SanitizeGeminiKeys() {
 	if cfg == nil {
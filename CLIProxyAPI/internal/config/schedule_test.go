@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigRejectsInvalidSchedule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "claude-api-key:\n  - api-key: valid\n    schedule: [\"weekdays 18:00-08:00 UTC\"]\n  - api-key: typo\n    schedule: [\"wekdays 18:00-08:00\"]\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "claude-api-key[1] schedule") {
+		t.Fatalf("LoadConfig() error = %v, want invalid claude-api-key[1] schedule", err)
+	}
+}
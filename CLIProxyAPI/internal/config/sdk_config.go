@@ -4,6 +4,11 @@
 // debug settings, proxy configuration, and API keys.
 package config
 
+import (
+	"strings"
+	"time"
+)
+
 // SDKConfig represents the application's configuration, loaded from a YAML file.
 type SDKConfig struct {
 	// ProxyURL is the URL of an optional proxy server to use for outbound requests.
@@ -20,6 +25,23 @@ type SDKConfig struct {
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 
+	// ClientKeys lists client API keys that carry a usage policy. They authenticate like
+	// APIKeys and additionally restrict models, providers, request rate and token usage.
+	ClientKeys []ClientAPIKey `yaml:"client-keys,omitempty" json:"client-keys,omitempty"`
+
+	// DBAPIKeys enables client API keys stored in the database and managed through
+	// the /v0/management/client-keys endpoints.
+	DBAPIKeys DBAPIKeyConfig `yaml:"db-api-keys,omitempty" json:"db-api-keys,omitempty"`
+
+	// JWTAccess enables short-lived JWT bearer tokens signed with jwt-secret as client credentials.
+	JWTAccess JWTAccessConfig `yaml:"jwt-access,omitempty" json:"jwt-access,omitempty"`
+
+	// OIDCAccess enables bearer tokens issued by an external OpenID Connect identity provider.
+	OIDCAccess OIDCAccessConfig `yaml:"oidc-access,omitempty" json:"oidc-access,omitempty"`
+
+	// MTLSAccess enables authentication by verified TLS client certificates (see tls.client-ca).
+	MTLSAccess MTLSAccessConfig `yaml:"mtls-access,omitempty" json:"mtls-access,omitempty"`
+
 	// Access holds request authentication provider configuration.
 	Access AccessConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
 
@@ -28,6 +50,145 @@ type SDKConfig struct {
 
 	// ProxyGrid holds Proxy Grid API integration configuration.
 	ProxyGrid ProxyGridConfig `yaml:"proxygrid,omitempty" json:"proxygrid,omitempty"`
+
+	// ModelFallbacks declares models to try, in order, when every credential for the requested
+	// model is cooling down or failing upstream.
+	ModelFallbacks []ModelFallback `yaml:"model-fallbacks,omitempty" json:"model-fallbacks,omitempty"`
+
+	// Transport tunes the pooled HTTP transports shared by upstream requests.
+	Transport TransportConfig `yaml:"transport,omitempty" json:"transport,omitempty"`
+}
+
+// TransportConfig controls the shared upstream HTTP transports. Zero values select the defaults.
+type TransportConfig struct {
+	// DialTimeoutSeconds bounds TCP connection setup, including SOCKS5 proxies. Default is 30.
+	DialTimeoutSeconds int `yaml:"dial-timeout-seconds,omitempty" json:"dial-timeout-seconds,omitempty"`
+
+	// TLSHandshakeTimeoutSeconds bounds the TLS handshake. Default is 10.
+	TLSHandshakeTimeoutSeconds int `yaml:"tls-handshake-timeout-seconds,omitempty" json:"tls-handshake-timeout-seconds,omitempty"`
+
+	// IdleConnTimeoutSeconds closes keep-alive connections idle for longer than this. Default is 90.
+	IdleConnTimeoutSeconds int `yaml:"idle-conn-timeout-seconds,omitempty" json:"idle-conn-timeout-seconds,omitempty"`
+
+	// MaxIdleConnsPerHost caps idle keep-alive connections per upstream host. Default is 32.
+	MaxIdleConnsPerHost int `yaml:"max-idle-conns-per-host,omitempty" json:"max-idle-conns-per-host,omitempty"`
+
+	// MaxConnsPerHost caps all connections per upstream host. Zero means no limit.
+	MaxConnsPerHost int `yaml:"max-conns-per-host,omitempty" json:"max-conns-per-host,omitempty"`
+
+	// DisableHTTP2 turns off HTTP/2 negotiation for upstream connections.
+	DisableHTTP2 bool `yaml:"disable-http2,omitempty" json:"disable-http2,omitempty"`
+}
+
+// ClientAPIKey is a client API key together with the policy enforced for its requests.
+type ClientAPIKey struct {
+	// Key is the secret clients present.
+	Key string `yaml:"key" json:"key"`
+
+	// Name is a display name used in logs and management views.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// AllowedModels lists model globs ('*' matches any substring) the key may use. Empty allows all.
+	AllowedModels []string `yaml:"allowed-models,omitempty" json:"allowed-models,omitempty"`
+
+	// AllowedProviders lists the providers the key may be routed to. Empty allows all.
+	AllowedProviders []string `yaml:"allowed-providers,omitempty" json:"allowed-providers,omitempty"`
+
+	// ModelPrefix is prepended to requested models so the key only uses credentials with that prefix.
+	ModelPrefix string `yaml:"model-prefix,omitempty" json:"model-prefix,omitempty"`
+
+	// RequestsPerMinute caps the request rate of the key. Zero means unlimited.
+	RequestsPerMinute int `yaml:"requests-per-minute,omitempty" json:"requests-per-minute,omitempty"`
+
+	// TokensPerDay caps the tokens the key may consume per UTC day. Zero means unlimited.
+	TokensPerDay int64 `yaml:"tokens-per-day,omitempty" json:"tokens-per-day,omitempty"`
+
+	// ExpiresAt disables the key after the given time. The zero value never expires.
+	ExpiresAt time.Time `yaml:"expires-at,omitempty" json:"expires-at,omitempty"`
+}
+
+// DBAPIKeyConfig configures the database-backed client API key provider.
+type DBAPIKeyConfig struct {
+	// Enabled turns on the provider. It needs the Postgres store to be configured.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// CacheTTLSeconds controls how long validated keys are cached in memory. Revocations made
+	// on another replica take up to this long to apply. Default is 30; negative disables caching.
+	CacheTTLSeconds int `yaml:"cache-ttl-seconds,omitempty" json:"cache-ttl-seconds,omitempty"`
+}
+
+// JWTAccessConfig configures the JWT bearer access provider.
+type JWTAccessConfig struct {
+	// Enabled turns on the provider. Tokens are signed and verified with the top-level jwt-secret.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// DefaultTTLSeconds is the lifetime of issued tokens when the request names none. Default is 900.
+	DefaultTTLSeconds int `yaml:"default-ttl-seconds,omitempty" json:"default-ttl-seconds,omitempty"`
+
+	// MaxTTLSeconds caps the lifetime that may be requested for issued tokens. Default is 86400.
+	MaxTTLSeconds int `yaml:"max-ttl-seconds,omitempty" json:"max-ttl-seconds,omitempty"`
+}
+
+// OIDCAccessConfig configures the OIDC access provider, which validates SSO-issued bearer
+// tokens against the issuer's JSON Web Key Set.
+type OIDCAccessConfig struct {
+	// Enabled turns on the provider.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Issuer is the expected "iss" claim, e.g. https://login.example.com/realms/eng.
+	Issuer string `yaml:"issuer" json:"issuer"`
+
+	// JWKSURL overrides the key set location. When empty it is discovered from
+	// <issuer>/.well-known/openid-configuration.
+	JWKSURL string `yaml:"jwks-url,omitempty" json:"jwks-url,omitempty"`
+
+	// Audiences lists the accepted "aud" values. At least one is required.
+	Audiences []string `yaml:"audiences" json:"audiences"`
+
+	// PrincipalClaim names the claim used as the principal for policies and usage attribution.
+	// Default is "email"; for list claims such as "groups" the first entry is used.
+	PrincipalClaim string `yaml:"principal-claim,omitempty" json:"principal-claim,omitempty"`
+
+	// RefreshIntervalSeconds controls how often the key set is re-fetched. Unknown key IDs
+	// trigger an earlier refresh. Default is 3600.
+	RefreshIntervalSeconds int `yaml:"refresh-interval-seconds,omitempty" json:"refresh-interval-seconds,omitempty"`
+}
+
+// MTLSAccessConfig configures the mTLS access provider, which maps verified client
+// certificates to principals.
+type MTLSAccessConfig struct {
+	// Enabled turns on the provider. It needs tls.enable and tls.client-ca.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// PrincipalFrom selects the certificate field used as the principal: "subject-cn" (default),
+	// "san-uri" (e.g. SPIFFE IDs), "san-dns" or "san-email". The first value found is used.
+	PrincipalFrom string `yaml:"principal-from,omitempty" json:"principal-from,omitempty"`
+}
+
+// InlineAPIKeys returns the plain API keys followed by the structured client keys.
+func (c *SDKConfig) InlineAPIKeys() []string {
+	if c == nil {
+		return nil
+	}
+	if len(c.ClientKeys) == 0 {
+		return c.APIKeys
+	}
+	keys := append([]string(nil), c.APIKeys...)
+	for i := range c.ClientKeys {
+		if key := strings.TrimSpace(c.ClientKeys[i].Key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ModelFallback maps a requested model to the chain of models used when it is unavailable.
+type ModelFallback struct {
+	// Model is the client-facing model name the chain applies to.
+	Model string `yaml:"model" json:"model"`
+
+	// Fallbacks lists the models tried in order after Model fails.
+	Fallbacks []string `yaml:"fallbacks" json:"fallbacks"`
 }
 
 // StreamingConfig holds server streaming behavior configuration.
@@ -72,6 +233,18 @@ const (
 
 	// DefaultAccessProviderName is applied when no provider name is supplied.
 	DefaultAccessProviderName = "config-inline"
+
+	// AccessProviderTypeDBAPIKey is the built-in provider validating keys stored in the database.
+	AccessProviderTypeDBAPIKey = "db-api-key"
+
+	// AccessProviderTypeJWT is the built-in provider validating JWT bearer tokens.
+	AccessProviderTypeJWT = "jwt"
+
+	// AccessProviderTypeOIDC is the built-in provider validating tokens from an OIDC issuer.
+	AccessProviderTypeOIDC = "oidc"
+
+	// AccessProviderTypeMTLS is the built-in provider authenticating verified client certificates.
+	AccessProviderTypeMTLS = "mtls"
 )
 
 // ConfigAPIKeyProvider returns the first inline API key provider if present.
@@ -104,6 +277,67 @@ func MakeInlineAPIKeyProvider(keys []string) *AccessProvider {
 	return provider
 }
 
+// MakeDBAPIKeyProvider constructs the database API key provider configuration.
+// It returns nil when the provider is disabled.
+func MakeDBAPIKeyProvider(cfg DBAPIKeyConfig) *AccessProvider {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &AccessProvider{
+		Name:   AccessProviderTypeDBAPIKey,
+		Type:   AccessProviderTypeDBAPIKey,
+		Config: map[string]any{"cache-ttl-seconds": cfg.CacheTTLSeconds},
+	}
+}
+
+// MakeOIDCAccessProvider constructs the OIDC access provider configuration.
+// It returns nil when the provider is disabled.
+func MakeOIDCAccessProvider(cfg OIDCAccessConfig) *AccessProvider {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &AccessProvider{
+		Name: AccessProviderTypeOIDC,
+		Type: AccessProviderTypeOIDC,
+		Config: map[string]any{
+			"issuer":                   cfg.Issuer,
+			"jwks-url":                 cfg.JWKSURL,
+			"audiences":                append([]string(nil), cfg.Audiences...),
+			"principal-claim":          cfg.PrincipalClaim,
+			"refresh-interval-seconds": cfg.RefreshIntervalSeconds,
+		},
+	}
+}
+
+// BuiltinAccessProviders returns the enabled built-in providers that follow the inline API
+// key provider, in evaluation order.
+func (c *SDKConfig) BuiltinAccessProviders() []*AccessProvider {
+	if c == nil {
+		return nil
+	}
+	var providers []*AccessProvider
+	if provider := MakeDBAPIKeyProvider(c.DBAPIKeys); provider != nil {
+		providers = append(providers, provider)
+	}
+	if c.JWTAccess.Enabled {
+		providers = append(providers, &AccessProvider{
+			Name: AccessProviderTypeJWT,
+			Type: AccessProviderTypeJWT,
+		})
+	}
+	if provider := MakeOIDCAccessProvider(c.OIDCAccess); provider != nil {
+		providers = append(providers, provider)
+	}
+	if c.MTLSAccess.Enabled {
+		providers = append(providers, &AccessProvider{
+			Name:   AccessProviderTypeMTLS,
+			Type:   AccessProviderTypeMTLS,
+			Config: map[string]any{"principal-from": c.MTLSAccess.PrincipalFrom},
+		})
+	}
+	return providers
+}
+
 // ProxyGridConfig holds Proxy Grid API integration settings.
 type ProxyGridConfig struct {
 	// Enabled enables or disables the Proxy Grid integration.
@@ -150,3 +384,57 @@ type ProxyGridCache struct {
 type CORSConfig struct {
 	AllowedOrigins []string `yaml:"allowed-origins,omitempty" json:"allowed-origins,omitempty"`
 }
+
+// SanitizeModelFallbacks trims model names, drops empty or self-referencing fallbacks,
+// removes duplicate models within a chain and keeps only the first chain per model.
+func (cfg *SDKConfig) SanitizeModelFallbacks() {
+	if cfg == nil || len(cfg.ModelFallbacks) == 0 {
+		return
+	}
+	seenModels := make(map[string]struct{}, len(cfg.ModelFallbacks))
+	out := make([]ModelFallback, 0, len(cfg.ModelFallbacks))
+	for _, entry := range cfg.ModelFallbacks {
+		model := strings.TrimSpace(entry.Model)
+		modelKey := strings.ToLower(model)
+		if model == "" {
+			continue
+		}
+		if _, ok := seenModels[modelKey]; ok {
+			continue
+		}
+		seenChain := map[string]struct{}{modelKey: {}}
+		fallbacks := make([]string, 0, len(entry.Fallbacks))
+		for _, raw := range entry.Fallbacks {
+			fallback := strings.TrimSpace(raw)
+			fallbackKey := strings.ToLower(fallback)
+			if fallback == "" {
+				continue
+			}
+			if _, ok := seenChain[fallbackKey]; ok {
+				continue
+			}
+			seenChain[fallbackKey] = struct{}{}
+			fallbacks = append(fallbacks, fallback)
+		}
+		if len(fallbacks) == 0 {
+			continue
+		}
+		seenModels[modelKey] = struct{}{}
+		out = append(out, ModelFallback{Model: model, Fallbacks: fallbacks})
+	}
+	cfg.ModelFallbacks = out
+}
+
+// ModelFallbackChain returns the fallback models configured for model, or nil when none are set.
+func (cfg *SDKConfig) ModelFallbackChain(model string) []string {
+	if cfg == nil {
+		return nil
+	}
+	model = strings.TrimSpace(model)
+	for _, entry := range cfg.ModelFallbacks {
+		if strings.EqualFold(strings.TrimSpace(entry.Model), model) {
+			return entry.Fallbacks
+		}
+	}
+	return nil
+}
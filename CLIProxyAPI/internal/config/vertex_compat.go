@@ -30,6 +30,18 @@ type VertexCompatKey struct {
 
 	// Models defines the model configurations including aliases for routing.
 	Models []VertexCompatModel `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Priority places this credential in a routing tier; higher tiers are drained before lower ones.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Weight sets the relative share of traffic this credential receives within its priority tier.
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// MaxConcurrency caps the requests in flight on this credential; 0 falls back to the provider default.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Schedule restricts the credential to active windows, e.g. "weekdays 18:00-08:00 UTC" or "never on mondays".
+	Schedule []string `yaml:"schedule,omitempty" json:"schedule,omitempty"`
 }
 
 // VertexCompatModel represents a model configuration for Vertex compatibility,
@@ -4,6 +4,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -70,6 +71,12 @@ func (m *MigrationManager) RegisterDefaultMigrations() {
 		`, m.cluster.FullTableName("usage_stats")),
 		fmt.Sprintf(`ALTER TABLE %s DROP COLUMN total_tokens`, m.cluster.FullTableName("usage_stats")),
 	)
+
+	// Migration 004: Key usage_stats rows by client API key and hour bucket
+	m.RegisterMigration("004", "usage_stats_hourly_buckets",
+		strings.Join(usageStatsUpgradeSQL(m.cluster.FullTableName("usage_stats")), ";\n")+";",
+		"",
+	)
 }
 
 // Initialize creates the schema migrations tracking table.
@@ -54,8 +54,12 @@ type UsageStats struct {
 	Model string `json:"model"`
 	// AuthID references the OAuth token or API key used.
 	AuthID string `json:"auth_id"`
+	// APIKey is the masked client API key or principal that made the requests.
+	APIKey string `json:"api_key"`
 	// Date is the aggregation date (truncated to day).
 	Date time.Time `json:"date"`
+	// Bucket is the start of the aggregation hour in UTC.
+	Bucket time.Time `json:"bucket"`
 	// RequestCount is the number of requests made.
 	RequestCount int64 `json:"request_count"`
 	// InputTokens is the total input tokens used.
@@ -72,6 +76,8 @@ type UsageStats struct {
 	SuccessCount int64 `json:"success_count"`
 	// ErrorCount is the number of failed requests.
 	ErrorCount int64 `json:"error_count"`
+	// CostUSD is the list price of the requests in USD.
+	CostUSD float64 `json:"cost_usd"`
 	// CreatedAt is the creation timestamp.
 	CreatedAt time.Time `json:"created_at"`
 	// UpdatedAt is the last modification timestamp.
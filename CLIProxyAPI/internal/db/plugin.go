@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	usagestats "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 )
 
@@ -62,7 +64,11 @@ func WithFlushInterval(interval time.Duration) UsagePluginOption {
 
 // HandleUsage implements the usage.Plugin interface.
 // It batches records in memory and flushes them periodically or when the batch is full.
+// Records are dropped while usage statistics are disabled.
 func (p *UsagePlugin) HandleUsage(ctx context.Context, record usage.Record) {
+	if !usagestats.StatisticsEnabled() {
+		return
+	}
 	if record.RequestedAt.IsZero() {
 		record.RequestedAt = time.Now()
 	}
@@ -98,13 +104,16 @@ func (p *UsagePlugin) Flush(ctx context.Context) error {
 			continue
 		}
 
-		date := r.RequestedAt
+		date := r.RequestedAt.UTC()
 		stat := &UsageStats{
 			ID:            uuid.New().String(),
 			Provider:      r.Provider,
 			Model:         r.Model,
 			AuthID:        r.AuthID,
-			Date:          time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location()),
+			// Never persist plaintext client keys; the masked form is enough to tell keys apart.
+			APIKey:        util.HideAPIKey(r.APIKey),
+			Date:          time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+			Bucket:        date.Truncate(time.Hour),
 			RequestCount:  1,
 			InputTokens:   r.Detail.InputTokens,
 			OutputTokens:  r.Detail.OutputTokens,
@@ -114,6 +123,13 @@ func (p *UsagePlugin) Flush(ctx context.Context) error {
 			ErrorCount:    0,
 		}
 
+		stat.CostUSD = usagestats.Pricing().Cost(r.Provider, r.Model, usagestats.TokenStats{
+			InputTokens:     r.Detail.InputTokens,
+			OutputTokens:    r.Detail.OutputTokens,
+			ReasoningTokens: r.Detail.ReasoningTokens,
+			CachedTokens:    r.Detail.CachedTokens,
+		})
+
 		if r.Failed {
 			stat.ErrorCount = 1
 		} else {
@@ -158,9 +174,11 @@ func (p *UsagePlugin) Close() error {
 }
 
 // RegisterUsagePlugin registers the database usage plugin with the global usage manager.
-func RegisterUsagePlugin(repo *Repo, opts ...UsagePluginOption) {
+// Close the returned plugin on shutdown to flush pending records.
+func RegisterUsagePlugin(repo *Repo, opts ...UsagePluginOption) *UsagePlugin {
 	plugin := NewUsagePlugin(repo, opts...)
 	usage.DefaultManager().Register(plugin)
+	return plugin
 }
 
 // RequestLogger creates a usage.Plugin that logs to the request_logs table.
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -34,6 +35,8 @@ type Queries struct {
 	selectAPIKeyByHash       string
 	selectActiveAPIKeys      string
 	updateAPIKeyLastUsed     string
+	revokeAPIKey             string
+	rotateAPIKey             string
 
 	// Config queries
 	insertConfig             string
@@ -107,10 +110,10 @@ func (q *Queries) initQueries() {
 
 	// Usage Stats Queries
 	q.upsertUsageStats = fmt.Sprintf(`
-		INSERT INTO %s (id, provider, model, auth_id, date, request_count, input_tokens, output_tokens,
-		                reasoning_tokens, cached_tokens, success_count, error_count)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		ON CONFLICT (provider, model, auth_id, date)
+		INSERT INTO %s (provider, model, auth_id, api_key, date, bucket, request_count, input_tokens, output_tokens,
+		                reasoning_tokens, cached_tokens, success_count, error_count, cost_usd)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (provider, model, auth_id, api_key, bucket)
 		DO UPDATE SET request_count = %s.request_count + EXCLUDED.request_count,
 		              input_tokens = %s.input_tokens + EXCLUDED.input_tokens,
 		              output_tokens = %s.output_tokens + EXCLUDED.output_tokens,
@@ -118,10 +121,11 @@ func (q *Queries) initQueries() {
 		              cached_tokens = COALESCE(%s.cached_tokens, 0) + COALESCE(EXCLUDED.cached_tokens, 0),
 		              success_count = %s.success_count + EXCLUDED.success_count,
 		              error_count = %s.error_count + EXCLUDED.error_count,
+		              cost_usd = %s.cost_usd + EXCLUDED.cost_usd,
 		              updated_at = NOW()
-		RETURNING id, total_tokens
+		RETURNING id, total_tokens, created_at, updated_at
 	`, table("usage_stats"), table("usage_stats"), table("usage_stats"), table("usage_stats"),
-		table("usage_stats"), table("usage_stats"), table("usage_stats"), table("usage_stats"))
+		table("usage_stats"), table("usage_stats"), table("usage_stats"), table("usage_stats"), table("usage_stats"))
 
 	q.selectUsageStatsByDate = fmt.Sprintf(`
 		SELECT provider, model, auth_id, date, request_count, input_tokens, output_tokens,
@@ -153,7 +157,7 @@ func (q *Queries) initQueries() {
 	`, table("api_keys"))
 
 	q.selectActiveAPIKeys = fmt.Sprintf(`
-		SELECT id, key_hash, key_prefix, name, description, rate_limit, expires_at, created_at
+		SELECT id, key_hash, key_prefix, name, description, rate_limit, is_active, expires_at, last_used_at, created_at, updated_at
 		FROM %s
 		WHERE is_active = true AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -165,6 +169,19 @@ func (q *Queries) initQueries() {
 		WHERE id = $1
 	`, table("api_keys"))
 
+	q.revokeAPIKey = fmt.Sprintf(`
+		UPDATE %s
+		SET is_active = false, deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, table("api_keys"))
+
+	q.rotateAPIKey = fmt.Sprintf(`
+		UPDATE %s
+		SET key_hash = $2, key_prefix = $3, updated_at = NOW()
+		WHERE id = $1 AND is_active = true AND deleted_at IS NULL
+		RETURNING id, key_hash, key_prefix, name, description, rate_limit, is_active, expires_at, last_used_at, created_at, updated_at
+	`, table("api_keys"))
+
 	// Config Queries
 	q.insertConfig = fmt.Sprintf(`
 		INSERT INTO %s (id, name, yaml_config, version, is_active)
@@ -213,7 +230,7 @@ func (q *Queries) initQueries() {
 	`, table("cache"))
 
 	q.selectCacheByKey = fmt.Sprintf(`
-		SELECT key, value, expires_at, content_type, tags, created_at
+		SELECT value, expires_at
 		FROM %s
 		WHERE key = $1 AND expires_at > NOW()
 	`, table("cache"))
@@ -340,17 +357,17 @@ func (q *Queries) SelectOAuthTokenByID(ctx context.Context, id string) (*OAuthTo
 
 // UsageStats Operations
 
-// UpsertUsageStats inserts or updates usage statistics for a day.
+// UpsertUsageStats inserts or updates usage statistics for an hour bucket.
 func (q *Queries) UpsertUsageStats(ctx context.Context, stats *UsageStats) error {
 	var id string
 	var totalTokens int64
 	var createdAt, updatedAt time.Time
 
 	err := q.cluster.Primary().QueryRow(ctx, q.upsertUsageStats,
-		stats.Provider, stats.Model, stats.AuthID, stats.Date,
+		stats.Provider, stats.Model, stats.AuthID, stats.APIKey, stats.Date, stats.Bucket,
 		stats.RequestCount, stats.InputTokens, stats.OutputTokens,
 		stats.ReasoningTokens, stats.CachedTokens,
-		stats.SuccessCount, stats.ErrorCount,
+		stats.SuccessCount, stats.ErrorCount, stats.CostUSD,
 	).Scan(&id, &totalTokens, &createdAt, &updatedAt)
 
 	if err != nil {
@@ -366,14 +383,27 @@ func (q *Queries) UpsertUsageStats(ctx context.Context, stats *UsageStats) error
 
 // APIKey Operations
 
+var (
+	// ErrAPIKeyNotFound is returned when no active API key matches.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyExpired is returned by ValidateAPIKey for keys past their expiry.
+	ErrAPIKeyExpired = errors.New("api key expired")
+)
+
+// HashAPIKey returns the stored hash and identification prefix of a plaintext API key.
+func HashAPIKey(plaintextKey string) (hash, prefix string) {
+	sum := sha256.Sum256([]byte(plaintextKey))
+	prefix = plaintextKey
+	if len(prefix) > 8 {
+		prefix = prefix[:8]
+	}
+	return hex.EncodeToString(sum[:]), prefix
+}
+
 // InsertAPIKey inserts a new API key.
 func (q *Queries) InsertAPIKey(ctx context.Context, key *APIKey, plaintextKey string) error {
-	hash := sha256.Sum256([]byte(plaintextKey))
-	key.KeyHash = hex.EncodeToString(hash[:])
-	key.KeyPrefix = plaintextKey
-	if len(key.KeyPrefix) > 8 {
-		key.KeyPrefix = key.KeyPrefix[:8]
-	}
+	key.KeyHash, key.KeyPrefix = HashAPIKey(plaintextKey)
+	key.IsActive = true
 
 	if key.ID == "" {
 		key.ID = uuid.New().String()
@@ -404,7 +434,7 @@ func (q *Queries) SelectAPIKeyByHash(ctx context.Context, hash string) (*APIKey,
 	)
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("api key not found")
+		return nil, ErrAPIKeyNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("select api key: %w", err)
@@ -415,8 +445,7 @@ func (q *Queries) SelectAPIKeyByHash(ctx context.Context, hash string) (*APIKey,
 
 // ValidateAPIKey validates a plaintext API key.
 func (q *Queries) ValidateAPIKey(ctx context.Context, plaintextKey string) (*APIKey, error) {
-	hash := sha256.Sum256([]byte(plaintextKey))
-	hashStr := hex.EncodeToString(hash[:])
+	hashStr, _ := HashAPIKey(plaintextKey)
 
 	key, err := q.SelectAPIKeyByHash(ctx, hashStr)
 	if err != nil {
@@ -425,7 +454,7 @@ func (q *Queries) ValidateAPIKey(ctx context.Context, plaintextKey string) (*API
 
 	// Check expiration
 	if key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now()) {
-		return nil, fmt.Errorf("api key expired")
+		return nil, ErrAPIKeyExpired
 	}
 
 	// Update last used timestamp
@@ -442,8 +471,68 @@ func (q *Queries) UpdateAPIKeyLastUsed(ctx context.Context, id string) error {
 	return err
 }
 
+// ListAPIKeys returns all active, non-deleted API keys, newest first.
+func (q *Queries) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	rows, err := q.cluster.Replica().Query(ctx, q.selectActiveAPIKeys)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		var key APIKey
+		if err = rows.Scan(
+			&key.ID, &key.KeyHash, &key.KeyPrefix, &key.Name,
+			&key.Description, &key.RateLimit, &key.IsActive,
+			&key.ExpiresAt, &key.LastUsedAt, &key.CreatedAt, &key.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey deactivates and soft-deletes an API key.
+func (q *Queries) RevokeAPIKey(ctx context.Context, id string) error {
+	tag, err := q.cluster.Primary().Exec(ctx, q.revokeAPIKey, id)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// RotateAPIKey replaces the secret of an active API key, keeping its id and settings.
+// The previous plaintext stops validating immediately.
+func (q *Queries) RotateAPIKey(ctx context.Context, id, plaintextKey string) (*APIKey, error) {
+	hash, prefix := HashAPIKey(plaintextKey)
+	var key APIKey
+	err := q.cluster.Primary().QueryRow(ctx, q.rotateAPIKey, id, hash, prefix).Scan(
+		&key.ID, &key.KeyHash, &key.KeyPrefix, &key.Name,
+		&key.Description, &key.RateLimit, &key.IsActive,
+		&key.ExpiresAt, &key.LastUsedAt, &key.CreatedAt, &key.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+	return &key, nil
+}
+
 // Cache Operations
 
+// ErrCacheMiss is returned by GetCache when the key is absent or expired.
+var ErrCacheMiss = errors.New("cache miss")
+
 // SetCache stores a value in the cache.
 func (q *Queries) SetCache(ctx context.Context, key string, value []byte, ttl time.Duration, contentType string, tags []string) error {
 	expiresAt := time.Now().Add(ttl)
@@ -465,7 +554,7 @@ func (q *Queries) GetCache(ctx context.Context, key string) ([]byte, error) {
 	)
 
 	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("cache miss")
+		return nil, ErrCacheMiss
 	}
 	if err != nil {
 		return nil, fmt.Errorf("get cache: %w", err)
@@ -165,10 +165,10 @@ func (b *BatchOperations) BatchInsertUsageStats(ctx context.Context, stats []*Us
 
 	table := b.repo.cluster.FullTableName("usage_stats")
 	query := fmt.Sprintf(`
-		INSERT INTO %s (id, provider, model, auth_id, date, request_count, input_tokens,
-		                output_tokens, reasoning_tokens, cached_tokens, success_count, error_count)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-		ON CONFLICT (provider, model, auth_id, date)
+		INSERT INTO %s (id, provider, model, auth_id, api_key, date, bucket, request_count, input_tokens,
+		                output_tokens, reasoning_tokens, cached_tokens, success_count, error_count, cost_usd)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (provider, model, auth_id, api_key, bucket)
 		DO UPDATE SET request_count = %s.request_count + EXCLUDED.request_count,
 		              input_tokens = %s.input_tokens + EXCLUDED.input_tokens,
 		              output_tokens = %s.output_tokens + EXCLUDED.output_tokens,
@@ -176,8 +176,9 @@ func (b *BatchOperations) BatchInsertUsageStats(ctx context.Context, stats []*Us
 		              cached_tokens = COALESCE(%s.cached_tokens, 0) + COALESCE(EXCLUDED.cached_tokens, 0),
 		              success_count = %s.success_count + EXCLUDED.success_count,
 		              error_count = %s.error_count + EXCLUDED.error_count,
+		              cost_usd = %s.cost_usd + EXCLUDED.cost_usd,
 		              updated_at = NOW()
-	`, table, table, table, table, table, table, table, table)
+	`, table, table, table, table, table, table, table, table, table)
 
 	batch := &pgx.Batch{}
 	for _, stat := range stats {
@@ -185,9 +186,9 @@ func (b *BatchOperations) BatchInsertUsageStats(ctx context.Context, stats []*Us
 			stat.ID = uuid.New().String()
 		}
 		batch.Queue(query,
-			stat.ID, stat.Provider, stat.Model, stat.AuthID, stat.Date,
+			stat.ID, stat.Provider, stat.Model, stat.AuthID, stat.APIKey, stat.Date, stat.Bucket,
 			stat.RequestCount, stat.InputTokens, stat.OutputTokens,
-			stat.ReasoningTokens, stat.CachedTokens, stat.SuccessCount, stat.ErrorCount,
+			stat.ReasoningTokens, stat.CachedTokens, stat.SuccessCount, stat.ErrorCount, stat.CostUSD,
 		)
 	}
 
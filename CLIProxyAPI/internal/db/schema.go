@@ -99,7 +99,9 @@ func (sm *SchemaManager) createUsageStatsTable(ctx context.Context) error {
 			provider TEXT NOT NULL,
 			model TEXT NOT NULL,
 			auth_id TEXT NOT NULL,
+			api_key TEXT NOT NULL DEFAULT '',
 			date DATE NOT NULL,
+			bucket TIMESTAMPTZ NOT NULL,
 			request_count BIGINT NOT NULL DEFAULT 0,
 			input_tokens BIGINT NOT NULL DEFAULT 0,
 			output_tokens BIGINT NOT NULL DEFAULT 0,
@@ -110,9 +112,9 @@ func (sm *SchemaManager) createUsageStatsTable(ctx context.Context) error {
 			) STORED,
 			success_count BIGINT NOT NULL DEFAULT 0,
 			error_count BIGINT NOT NULL DEFAULT 0,
+			cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			UNIQUE (provider, model, auth_id, date)
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		)
 	`, table)
 
@@ -120,6 +122,12 @@ func (sm *SchemaManager) createUsageStatsTable(ctx context.Context) error {
 		return err
 	}
 
+	for _, stmt := range usageStatsUpgradeSQL(table) {
+		if _, err := sm.cluster.Primary().Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
 	// Create indexes for analytics queries
 	indexes := []string{
 		// Index for daily stats by provider/model
@@ -130,6 +138,8 @@ func (sm *SchemaManager) createUsageStatsTable(ctx context.Context) error {
 		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_usage_stats_date ON %s (date DESC)`, table),
 		// Partial index for non-zero usage
 		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_usage_stats_nonzero ON %s (date) WHERE request_count > 0`, table),
+		// Index for time range queries by hour bucket
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_usage_stats_bucket ON %s (bucket DESC)`, table),
 	}
 
 	for _, idx := range indexes {
@@ -141,6 +151,31 @@ func (sm *SchemaManager) createUsageStatsTable(ctx context.Context) error {
 	return nil
 }
 
+// EnsureUsageStatsTable creates the usage_stats table written by the usage plugin if it is
+// missing, and upgrades older tables to the per-key hourly layout.
+func (sm *SchemaManager) EnsureUsageStatsTable(ctx context.Context) error {
+	return sm.createUsageStatsTable(ctx)
+}
+
+// usageStatsUpgradeSQL upgrades usage_stats tables created before rows were keyed by client API
+// key and hour bucket. Every statement is idempotent.
+func usageStatsUpgradeSQL(table string) []string {
+	return []string{
+		fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS api_key TEXT NOT NULL DEFAULT ''`, table),
+		fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS bucket TIMESTAMPTZ`, table),
+		fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0`, table),
+		fmt.Sprintf(`UPDATE %s SET bucket = date::timestamp AT TIME ZONE 'UTC' WHERE bucket IS NULL`, table),
+		fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN bucket SET NOT NULL`, table),
+		fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT IF EXISTS usage_stats_provider_model_auth_id_date_key`, table),
+		fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS idx_usage_stats_bucket_key ON %s (provider, model, auth_id, api_key, bucket)`, table),
+	}
+}
+
+// EnsureAPIKeysTable creates the api_keys table used by the API key queries if it is missing.
+func (sm *SchemaManager) EnsureAPIKeysTable(ctx context.Context) error {
+	return sm.createAPIKeysTable(ctx)
+}
+
 func (sm *SchemaManager) createAPIKeysTable(ctx context.Context) error {
 	table := sm.cluster.FullTableName("api_keys")
 	query := fmt.Sprintf(`
@@ -222,6 +257,11 @@ func (sm *SchemaManager) createConfigsTable(ctx context.Context) error {
 	return nil
 }
 
+// EnsureCacheTable creates the cache table used by Queries.SetCache and GetCache if it is missing.
+func (sm *SchemaManager) EnsureCacheTable(ctx context.Context) error {
+	return sm.createCacheTable(ctx)
+}
+
 func (sm *SchemaManager) createCacheTable(ctx context.Context) error {
 	table := sm.cluster.FullTableName("cache")
 	query := fmt.Sprintf(`
@@ -245,8 +285,6 @@ func (sm *SchemaManager) createCacheTable(ctx context.Context) error {
 		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_cache_expires_at ON %s (expires_at)`, table),
 		// Index for tag-based invalidation
 		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_cache_tags ON %s USING GIN (tags)`, table),
-		// Partial index for non-expired entries
-		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_cache_valid ON %s (expires_at) WHERE expires_at > NOW()`, table),
 	}
 
 	for _, idx := range indexes {
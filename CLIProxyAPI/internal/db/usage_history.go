@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+)
+
+// usageHistoryColumns maps history dimensions to usage_stats columns.
+var usageHistoryColumns = map[string]string{
+	usage.HistoryGroupAPIKey:   "api_key",
+	usage.HistoryGroupModel:    "model",
+	usage.HistoryGroupProvider: "provider",
+	usage.HistoryGroupAuth:     "auth_id",
+}
+
+// QueryUsageHistory aggregates usage_stats over a time range. It implements usage.HistoryStore.
+func (a *Analytics) QueryUsageHistory(ctx context.Context, q usage.HistoryQuery) ([]usage.HistoryRow, error) {
+	query, columns, err := buildUsageHistoryQuery(a.repo.cluster.FullTableName("usage_stats"), q)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := a.repo.cluster.Replica().Query(ctx, query, q.From.UTC().Truncate(time.Hour), q.To.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query usage history: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]usage.HistoryRow, 0)
+	for rows.Next() {
+		var (
+			r      usage.HistoryRow
+			bucket time.Time
+		)
+		dest := make([]any, 0, len(columns)+9)
+		if q.Bucket != "" {
+			dest = append(dest, &bucket)
+		}
+		for _, dim := range columns {
+			switch dim {
+			case usage.HistoryGroupAPIKey:
+				dest = append(dest, &r.APIKey)
+			case usage.HistoryGroupModel:
+				dest = append(dest, &r.Model)
+			case usage.HistoryGroupProvider:
+				dest = append(dest, &r.Provider)
+			case usage.HistoryGroupAuth:
+				dest = append(dest, &r.AuthID)
+			}
+		}
+		dest = append(dest,
+			&r.TotalRequests, &r.SuccessCount, &r.FailureCount,
+			&r.InputTokens, &r.OutputTokens, &r.ReasoningTokens, &r.CachedTokens,
+			&r.TotalTokens, &r.TotalCostUSD,
+		)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan usage history: %w", err)
+		}
+		if q.Bucket != "" {
+			bucket = bucket.UTC()
+			r.Bucket = &bucket
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// buildUsageHistoryQuery returns the SQL for q and the group-by dimensions in select order.
+// Only whitelisted column names are interpolated; the range is passed as $1 and $2. Sums are
+// coalesced so an ungrouped query over an empty range still scans as a zero row.
+func buildUsageHistoryQuery(table string, q usage.HistoryQuery) (string, []string, error) {
+	if err := q.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	var selects, groups, orders []string
+	if q.Bucket != "" {
+		selects = append(selects, fmt.Sprintf("date_trunc('%s', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket_start", q.Bucket))
+		groups = append(groups, "bucket_start")
+		orders = append(orders, "bucket_start")
+	}
+	for _, dim := range q.GroupBy {
+		column := usageHistoryColumns[dim]
+		selects = append(selects, column)
+		groups = append(groups, column)
+	}
+	selects = append(selects,
+		"COALESCE(SUM(request_count), 0)::BIGINT AS total_requests",
+		"COALESCE(SUM(success_count), 0)::BIGINT",
+		"COALESCE(SUM(error_count), 0)::BIGINT",
+		"COALESCE(SUM(input_tokens), 0)::BIGINT",
+		"COALESCE(SUM(output_tokens), 0)::BIGINT",
+		"COALESCE(SUM(reasoning_tokens), 0)::BIGINT",
+		"COALESCE(SUM(cached_tokens), 0)::BIGINT",
+		"COALESCE(SUM(total_tokens), 0)::BIGINT",
+		"COALESCE(SUM(cost_usd), 0)::DOUBLE PRECISION",
+	)
+	orders = append(orders, "total_requests DESC")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT %s FROM %s WHERE bucket >= $1 AND bucket < $2", strings.Join(selects, ", "), table)
+	if len(groups) > 0 {
+		fmt.Fprintf(&b, " GROUP BY %s", strings.Join(groups, ", "))
+	}
+	fmt.Fprintf(&b, " ORDER BY %s", strings.Join(orders, ", "))
+	return b.String(), q.GroupBy, nil
+}
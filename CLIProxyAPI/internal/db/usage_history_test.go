@@ -0,0 +1,53 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+)
+
+func TestBuildUsageHistoryQuery(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+
+	query, columns, err := buildUsageHistoryQuery(`"usage_stats"`, usage.HistoryQuery{
+		From:    from,
+		To:      to,
+		GroupBy: []string{usage.HistoryGroupAuth, usage.HistoryGroupAPIKey},
+		Bucket:  usage.HistoryBucketDay,
+	})
+	if err != nil {
+		t.Fatalf("buildUsageHistoryQuery() error = %v", err)
+	}
+	for _, want := range []string{
+		"SELECT date_trunc('day', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket_start, auth_id, api_key, ",
+		`FROM "usage_stats" WHERE bucket >= $1 AND bucket < $2`,
+		"GROUP BY bucket_start, auth_id, api_key",
+		"ORDER BY bucket_start, total_requests DESC",
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("query %q does not contain %q", query, want)
+		}
+	}
+	if len(columns) != 2 || columns[0] != usage.HistoryGroupAuth {
+		t.Fatalf("columns = %v", columns)
+	}
+
+	query, _, err = buildUsageHistoryQuery(`"usage_stats"`, usage.HistoryQuery{From: from, To: to})
+	if err != nil {
+		t.Fatalf("buildUsageHistoryQuery() error = %v", err)
+	}
+	if strings.Contains(query, "GROUP BY") || strings.Contains(query, "bucket_start") {
+		t.Fatalf("ungrouped query = %q", query)
+	}
+
+	if _, _, err = buildUsageHistoryQuery(`"usage_stats"`, usage.HistoryQuery{
+		From:    from,
+		To:      to,
+		GroupBy: []string{"model; DROP TABLE usage_stats"},
+	}); err == nil {
+		t.Fatal("buildUsageHistoryQuery() accepted an unknown dimension")
+	}
+}
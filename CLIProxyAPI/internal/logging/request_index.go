@@ -0,0 +1,321 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// defaultRequestIndexCapacity bounds the number of requests kept in the index.
+const defaultRequestIndexCapacity = 10000
+
+const ginRequestLogWrittenKey = "__request_log_written__"
+
+// RequestIndexEntry is the structured record of one AI API request. Routing fields and token
+// counts come from the usage records of the request's upstream attempts; the last attempt is
+// the one that served the response.
+type RequestIndexEntry struct {
+	RequestID       string    `json:"request_id"`
+	Timestamp       time.Time `json:"timestamp"`
+	ClientKey       string    `json:"client_key,omitempty"`
+	Method          string    `json:"method"`
+	Path            string    `json:"path"`
+	InboundFormat   string    `json:"inbound_format,omitempty"`
+	Model           string    `json:"model,omitempty"`
+	Provider        string    `json:"provider,omitempty"`
+	AuthID          string    `json:"auth_id,omitempty"`
+	AuthIndex       string    `json:"auth_index,omitempty"`
+	Attempts        int       `json:"attempts"`
+	FailedAttempts  int       `json:"failed_attempts"`
+	Status          int       `json:"status"`
+	Stream          bool      `json:"stream"`
+	LatencyMs       int64     `json:"latency_ms"`
+	TTFBMs          int64     `json:"ttfb_ms"`
+	InputTokens     int64     `json:"input_tokens"`
+	OutputTokens    int64     `json:"output_tokens"`
+	ReasoningTokens int64     `json:"reasoning_tokens"`
+	CachedTokens    int64     `json:"cached_tokens"`
+	TotalTokens     int64     `json:"total_tokens"`
+	// LogFile names the management endpoint serving the full request log, when one was written.
+	LogFile string `json:"log_file,omitempty"`
+}
+
+// RequestIndexFilter selects index entries. Zero fields match everything.
+type RequestIndexFilter struct {
+	RequestID     string
+	ClientKey     string
+	InboundFormat string
+	Model         string
+	Provider      string
+	AuthID        string
+	AuthIndex     string
+	// Status matches an exact code; StatusClass matches "2xx", "4xx", "5xx" or "error" (>= 400).
+	Status       int
+	StatusClass  string
+	MinLatencyMs int64
+	From         time.Time
+	To           time.Time
+}
+
+// RequestIndex keeps the most recent requests in memory as structured entries. It implements
+// coreusage.Plugin to attach routing and token data to entries as attempts complete.
+type RequestIndex struct {
+	mu       sync.Mutex
+	capacity int
+	entries  []*RequestIndexEntry
+	byID     map[string]*RequestIndexEntry
+}
+
+// NewRequestIndex creates an index holding at most capacity entries.
+func NewRequestIndex(capacity int) *RequestIndex {
+	if capacity <= 0 {
+		capacity = defaultRequestIndexCapacity
+	}
+	return &RequestIndex{capacity: capacity, byID: make(map[string]*RequestIndexEntry)}
+}
+
+var defaultRequestIndex = NewRequestIndex(defaultRequestIndexCapacity)
+
+func init() {
+	coreusage.RegisterPlugin(defaultRequestIndex)
+}
+
+// DefaultRequestIndex returns the shared request index fed by the server middleware.
+func DefaultRequestIndex() *RequestIndex { return defaultRequestIndex }
+
+// begin adds an entry for a request that has just started, evicting the oldest when full.
+func (x *RequestIndex) begin(entry *RequestIndexEntry) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if len(x.entries) >= x.capacity {
+		evicted := x.entries[0]
+		x.entries[0] = nil
+		x.entries = x.entries[1:]
+		if x.byID[evicted.RequestID] == evicted {
+			delete(x.byID, evicted.RequestID)
+		}
+	}
+	x.entries = append(x.entries, entry)
+	x.byID[entry.RequestID] = entry
+}
+
+// update applies fn to the entry of requestID under the index lock.
+func (x *RequestIndex) update(requestID string, fn func(*RequestIndexEntry)) {
+	if requestID == "" {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if entry, ok := x.byID[requestID]; ok {
+		fn(entry)
+	}
+}
+
+// HandleUsage implements coreusage.Plugin. Each record is one upstream attempt.
+func (x *RequestIndex) HandleUsage(ctx context.Context, record coreusage.Record) {
+	x.update(GetRequestID(ctx), func(entry *RequestIndexEntry) {
+		entry.Attempts++
+		if record.Failed {
+			entry.FailedAttempts++
+		}
+		entry.Provider = record.Provider
+		entry.Model = record.Model
+		entry.AuthID = record.AuthID
+		entry.AuthIndex = record.AuthIndex
+		entry.InputTokens += record.Detail.InputTokens
+		entry.OutputTokens += record.Detail.OutputTokens
+		entry.ReasoningTokens += record.Detail.ReasoningTokens
+		entry.CachedTokens += record.Detail.CachedTokens
+		entry.TotalTokens += record.Detail.TotalTokens
+	})
+}
+
+// Get returns a copy of the entry for requestID.
+func (x *RequestIndex) Get(requestID string) (RequestIndexEntry, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	entry, ok := x.byID[requestID]
+	if !ok {
+		return RequestIndexEntry{}, false
+	}
+	return *entry, true
+}
+
+// Search returns matching entries newest first, skipping offset and returning at most limit,
+// together with the total number of matches.
+func (x *RequestIndex) Search(filter RequestIndexFilter, offset, limit int) ([]RequestIndexEntry, int) {
+	x.mu.Lock()
+	matched := make([]RequestIndexEntry, 0)
+	// Entries are kept in start order, so walking backwards lists the newest first.
+	for i := len(x.entries) - 1; i >= 0; i-- {
+		if filter.matches(x.entries[i]) {
+			matched = append(matched, *x.entries[i])
+		}
+	}
+	x.mu.Unlock()
+
+	total := len(matched)
+	if offset >= total {
+		return []RequestIndexEntry{}, total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return matched[offset:end], total
+}
+
+func (f RequestIndexFilter) matches(entry *RequestIndexEntry) bool {
+	if f.RequestID != "" && entry.RequestID != f.RequestID {
+		return false
+	}
+	if f.ClientKey != "" && entry.ClientKey != f.ClientKey && entry.ClientKey != util.HideAPIKey(f.ClientKey) {
+		return false
+	}
+	if !equalFoldIfSet(f.InboundFormat, entry.InboundFormat) ||
+		!equalFoldIfSet(f.Model, entry.Model) ||
+		!equalFoldIfSet(f.Provider, entry.Provider) ||
+		!equalFoldIfSet(f.AuthID, entry.AuthID) ||
+		!equalFoldIfSet(f.AuthIndex, entry.AuthIndex) {
+		return false
+	}
+	if f.Status != 0 && entry.Status != f.Status {
+		return false
+	}
+	switch f.StatusClass {
+	case "":
+	case "error":
+		if entry.Status < http.StatusBadRequest {
+			return false
+		}
+	default:
+		if len(f.StatusClass) != 3 || entry.Status/100 != int(f.StatusClass[0]-'0') {
+			return false
+		}
+	}
+	if f.MinLatencyMs > 0 && entry.LatencyMs < f.MinLatencyMs {
+		return false
+	}
+	if !f.From.IsZero() && entry.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !entry.Timestamp.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+func equalFoldIfSet(want, got string) bool {
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// MarkRequestLogWritten records that a request log file was written for the request.
+func MarkRequestLogWritten(c *gin.Context) {
+	if c != nil {
+		c.Set(ginRequestLogWrittenKey, true)
+	}
+}
+
+func requestLogWritten(c *gin.Context) bool {
+	written, _ := c.Get(ginRequestLogWrittenKey)
+	value, _ := written.(bool)
+	return value
+}
+
+// RequestIndexMiddleware records AI API requests in index. It must run after GinLogrusLogger,
+// which assigns request IDs, and before RequestLoggingMiddleware so the log file is known when
+// the entry is finalized.
+func RequestIndexMiddleware(index *RequestIndex) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := GetGinRequestID(c)
+		if index == nil || requestID == "" {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		path := c.Request.URL.Path
+		index.begin(&RequestIndexEntry{
+			RequestID:     requestID,
+			Timestamp:     start.UTC(),
+			Method:        c.Request.Method,
+			Path:          path,
+			InboundFormat: inboundFormat(path),
+		})
+		writer := &ttfbWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		latency := time.Since(start)
+		clientKey := ""
+		if value, exists := c.Get("apiKey"); exists {
+			if key, ok := value.(string); ok {
+				clientKey = util.HideAPIKey(key)
+			}
+		}
+		status := writer.Status()
+		stream := strings.Contains(writer.Header().Get("Content-Type"), "text/event-stream")
+		logWritten := requestLogWritten(c)
+		index.update(requestID, func(entry *RequestIndexEntry) {
+			entry.ClientKey = clientKey
+			entry.Status = status
+			entry.Stream = stream
+			entry.LatencyMs = latency.Milliseconds()
+			if !writer.firstByte.IsZero() {
+				entry.TTFBMs = writer.firstByte.Sub(start).Milliseconds()
+			}
+			if logWritten {
+				entry.LogFile = "/v0/management/request-log-by-id/" + requestID
+			}
+		})
+	}
+}
+
+// ttfbWriter records when the first response byte is written.
+type ttfbWriter struct {
+	gin.ResponseWriter
+	firstByte time.Time
+}
+
+func (w *ttfbWriter) Write(data []byte) (int, error) {
+	if w.firstByte.IsZero() && len(data) > 0 {
+		w.firstByte = time.Now()
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *ttfbWriter) WriteString(data string) (int, error) {
+	if w.firstByte.IsZero() && len(data) > 0 {
+		w.firstByte = time.Now()
+	}
+	return w.ResponseWriter.WriteString(data)
+}
+
+// inboundFormat maps an AI API path to the request format the client spoke.
+func inboundFormat(path string) string {
+	if rest, ok := strings.CutPrefix(path, "/api/provider/"); ok {
+		// Provider aliases wrap the standard routes: /api/provider/{provider}/v1/...
+		if idx := strings.Index(rest, "/"); idx >= 0 {
+			path = rest[idx:]
+		}
+	}
+	switch {
+	case strings.HasPrefix(path, "/v1/chat/completions"), strings.HasPrefix(path, "/v1/completions"):
+		return constant.OpenAI
+	case strings.HasPrefix(path, "/v1/responses"):
+		return constant.OpenaiResponse
+	case strings.HasPrefix(path, "/v1/messages"):
+		return constant.Claude
+	case strings.HasPrefix(path, "/v1beta/models"):
+		return constant.Gemini
+	}
+	return ""
+}
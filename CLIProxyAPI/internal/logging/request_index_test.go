@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func newIndexedRouter(index *RequestIndex) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(GinLogrusLogger(), RequestIndexMiddleware(index))
+	router.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Set("apiKey", "sk-client-0123456789")
+		ctx := c.Request.Context()
+		index.HandleUsage(ctx, coreusage.Record{Provider: "codex", Model: "gpt-5", AuthID: "a.json", AuthIndex: "1", Failed: true})
+		index.HandleUsage(ctx, coreusage.Record{
+			Provider:  "codex",
+			Model:     "gpt-5",
+			AuthID:    "b.json",
+			AuthIndex: "2",
+			Detail:    coreusage.Detail{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		})
+		if strings.Contains(c.Query("fail"), "1") {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "upstream"})
+			return
+		}
+		MarkRequestLogWritten(c)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.POST("/v1/messages", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+	return router
+}
+
+func TestRequestIndexRecordsRequests(t *testing.T) {
+	index := NewRequestIndex(10)
+	router := newIndexedRouter(index)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+
+	entries, total := index.Search(RequestIndexFilter{}, 0, 0)
+	if total != 1 {
+		t.Fatalf("total = %d, want 1", total)
+	}
+	entry := entries[0]
+	if entry.RequestID == "" || entry.InboundFormat != "openai" || entry.Status != http.StatusOK {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.Attempts != 2 || entry.FailedAttempts != 1 || entry.AuthID != "b.json" || entry.AuthIndex != "2" {
+		t.Fatalf("attempts = %+v", entry)
+	}
+	if entry.TotalTokens != 15 || entry.ClientKey != "sk-c...6789" || entry.TTFBMs > entry.LatencyMs {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.LogFile != "/v0/management/request-log-by-id/"+entry.RequestID {
+		t.Fatalf("log file = %q", entry.LogFile)
+	}
+	if got, ok := index.Get(entry.RequestID); !ok || got.Model != "gpt-5" {
+		t.Fatalf("Get() = %+v, %v", got, ok)
+	}
+}
+
+func TestRequestIndexSearch(t *testing.T) {
+	index := NewRequestIndex(3)
+	router := newIndexedRouter(index)
+	for _, path := range []string{
+		"/v1/chat/completions",
+		"/v1/chat/completions?fail=1",
+		"/v1/messages",
+		"/v1/chat/completions",
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+
+	// The oldest request was evicted.
+	if _, total := index.Search(RequestIndexFilter{}, 0, 0); total != 3 {
+		t.Fatalf("total = %d, want 3", total)
+	}
+
+	cases := []struct {
+		name   string
+		filter RequestIndexFilter
+		want   int
+	}{
+		{"errors", RequestIndexFilter{StatusClass: "error"}, 2},
+		{"5xx", RequestIndexFilter{StatusClass: "5xx"}, 1},
+		{"status", RequestIndexFilter{Status: http.StatusUnauthorized}, 1},
+		{"format", RequestIndexFilter{InboundFormat: "claude"}, 1},
+		{"provider", RequestIndexFilter{Provider: "CODEX"}, 2},
+		{"plaintext key", RequestIndexFilter{ClientKey: "sk-client-0123456789"}, 2},
+		{"masked key", RequestIndexFilter{ClientKey: "sk-c...6789"}, 2},
+		{"future", RequestIndexFilter{From: time.Now().Add(time.Hour)}, 0},
+	}
+	for _, tc := range cases {
+		if _, total := index.Search(tc.filter, 0, 0); total != tc.want {
+			t.Errorf("%s: total = %d, want %d", tc.name, total, tc.want)
+		}
+	}
+
+	page, total := index.Search(RequestIndexFilter{}, 1, 1)
+	if total != 3 || len(page) != 1 || page[0].Status != http.StatusUnauthorized {
+		t.Fatalf("page = %+v, total = %d", page, total)
+	}
+	if page, _ = index.Search(RequestIndexFilter{}, 5, 1); len(page) != 0 {
+		t.Fatalf("page past the end = %+v", page)
+	}
+}
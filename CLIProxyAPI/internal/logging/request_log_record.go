@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// RequestLogRecord is the inbound request and final response recovered from a request log file.
+type RequestLogRecord struct {
+	URL    string
+	Method string
+	Body   []byte
+	// Status is zero when the log does not record the response status.
+	Status       int
+	ResponseBody []byte
+	// HasResponse reports whether the log contains a response section.
+	HasResponse bool
+}
+
+const (
+	requestInfoHeader = "=== REQUEST INFO ===\n"
+	requestBodyHeader = "=== REQUEST BODY ===\n"
+	responseHeader    = "\n=== RESPONSE ===\n"
+)
+
+// ParseRequestLog extracts the request and response sections of a log written by FileRequestLogger.
+func ParseRequestLog(data []byte) (*RequestLogRecord, error) {
+	if !bytes.HasPrefix(data, []byte(requestInfoHeader)) {
+		return nil, errors.New("not a request log")
+	}
+	record := &RequestLogRecord{}
+
+	infoEnd := bytes.Index(data, []byte("\n\n"))
+	if infoEnd < 0 {
+		return nil, errors.New("truncated request info section")
+	}
+	for _, line := range strings.Split(string(data[len(requestInfoHeader):infoEnd]), "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "URL":
+			record.URL = value
+		case "Method":
+			record.Method = value
+		}
+	}
+
+	bodyStart := bytes.Index(data, []byte(requestBodyHeader))
+	if bodyStart < 0 {
+		return nil, errors.New("missing request body section")
+	}
+	bodyStart += len(requestBodyHeader)
+	// The body is followed by a blank line and the next section header.
+	rest := data[bodyStart:]
+	bodyEnd := bytes.Index(rest, []byte("\n\n=== "))
+	if bodyEnd < 0 {
+		record.Body = bytes.TrimSuffix(rest, []byte("\n\n"))
+		return record, nil
+	}
+	record.Body = rest[:bodyEnd]
+	rest = rest[bodyEnd+1:]
+
+	respStart := bytes.Index(rest, []byte(responseHeader))
+	if respStart < 0 {
+		return record, nil
+	}
+	record.HasResponse = true
+	rest = rest[respStart+len(responseHeader):]
+	// Status and response headers end with a blank line; the body follows.
+	headersEnd := bytes.Index(rest, []byte("\n\n"))
+	var headerBlock []byte
+	if bytes.HasPrefix(rest, []byte("\n")) {
+		rest = rest[1:]
+	} else if headersEnd >= 0 {
+		headerBlock = rest[:headersEnd]
+		rest = rest[headersEnd+2:]
+	} else {
+		headerBlock, rest = rest, nil
+	}
+	for _, line := range strings.Split(string(headerBlock), "\n") {
+		if value, ok := strings.CutPrefix(line, "Status: "); ok {
+			if status, errAtoi := strconv.Atoi(strings.TrimSpace(value)); errAtoi == nil {
+				record.Status = status
+			}
+			break
+		}
+	}
+	record.ResponseBody = bytes.TrimSuffix(rest, []byte("\n"))
+	return record, nil
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+)
+
+func writeTestRequestLog(t *testing.T, body, response, apiResponse []byte, status int) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	logger := NewFileRequestLogger(true, dir, "")
+	err := logger.LogRequest(
+		"/v1/chat/completions?alt=", "POST",
+		map[string][]string{"Authorization": {"Bearer sk-secret"}},
+		body, status,
+		map[string][]string{"Content-Type": {"application/json"}},
+		response, []byte("upstream request"), apiResponse,
+		[]*interfaces.ErrorMessage{{StatusCode: 429}}, "abc123",
+	)
+	if err != nil {
+		t.Fatalf("LogRequest: %v", err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "*-abc123.log"))
+	if len(matches) != 1 {
+		t.Fatalf("log files = %v", matches)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	return data
+}
+
+func TestParseRequestLog(t *testing.T) {
+	body := []byte("{\"model\":\"gpt-5\",\n\n\"stream\":false}")
+	data := writeTestRequestLog(t, body, []byte(`{"id":"resp"}`), []byte("upstream response"), 200)
+
+	record, err := ParseRequestLog(data)
+	if err != nil {
+		t.Fatalf("ParseRequestLog: %v", err)
+	}
+	if record.URL != "/v1/chat/completions?alt=" || record.Method != "POST" {
+		t.Fatalf("url/method = %q %q", record.URL, record.Method)
+	}
+	if string(record.Body) != string(body) {
+		t.Fatalf("body = %q", record.Body)
+	}
+	if !record.HasResponse || record.Status != 200 || string(record.ResponseBody) != `{"id":"resp"}` {
+		t.Fatalf("response = %v %d %q", record.HasResponse, record.Status, record.ResponseBody)
+	}
+}
+
+func TestParseRequestLogEmptyBodies(t *testing.T) {
+	data := writeTestRequestLog(t, nil, nil, nil, 502)
+
+	record, err := ParseRequestLog(data)
+	if err != nil {
+		t.Fatalf("ParseRequestLog: %v", err)
+	}
+	if len(record.Body) != 0 || len(record.ResponseBody) != 0 || record.Status != 502 {
+		t.Fatalf("record = %+v", record)
+	}
+}
+
+func TestParseRequestLogRejectsOtherFiles(t *testing.T) {
+	if _, err := ParseRequestLog([]byte("time=... level=info\n")); err == nil {
+		t.Fatal("expected error for non request log")
+	}
+}
@@ -1,47 +1,88 @@
 // Package production provides production-ready middleware and components for the CLI Proxy API.
-// It includes rate limiting, request validation, metrics, circuit breaker, and JWT authentication.
+// It includes rate limiting, request validation, metrics and JWT authentication.
+//
+// Upstream circuit breakers are not part of this package's components: they are owned by the
+// core auth manager, which applies the circuit-breaker settings during credential selection.
 package production
 
 import (
 	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/middleware"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/auth/jwt"
-	"github.com/router-for-me/CLIProxyAPI/v6/internal/circuitbreaker"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
-	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/metrics"
-	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 	log "github.com/sirupsen/logrus"
 )
 
 // Components holds all production components
 type Components struct {
-	MetricsCollector   *metrics.MetricsCollector
-	CircuitBreakerMgr  *circuitbreaker.Manager
-	JWTManager         *jwt.Manager
-	RateLimiter        *middleware.RateLimiter
+	MetricsCollector *metrics.MetricsCollector
+	JWTManager       *jwt.Manager
+	RateLimiter      *middleware.RateLimiter
+
+	handlers    map[Stage]gin.HandlerFunc
+	metricsPath string
+	cancel      context.CancelFunc
 }
 
-// SetupComponents initializes all production components based on configuration
-func SetupComponents(cfg *config.Config) (*Components, error) {
-	components := &Components{}
+// Stage identifies a fixed position in the production middleware chain.
+type Stage int
+
+const (
+	// StageValidation rejects oversized or malformed requests.
+	StageValidation Stage = iota
+	// StageMetrics records HTTP request metrics, including requests rejected by later stages,
+	// and serves the Prometheus registry on a custom metrics path.
+	StageMetrics
+	// StageRateLimit enforces per-client request limits.
+	StageRateLimit
+	// StageJWT attaches JWT claims when a valid token is presented.
+	StageJWT
+)
+
+// Stages lists every stage in chain order.
+var Stages = []Stage{StageValidation, StageMetrics, StageRateLimit, StageJWT}
 
-	// Initialize structured logging
-	if err := structured.ConfigureFromConfig(cfg); err != nil {
-		log.WithError(err).Warn("Failed to configure structured logging, using defaults")
-	} else {
-		// Add context hook for request ID tracing
-		structured.AddContextHook()
-		log.Info("Structured logging configured")
+// Option customises SetupComponents.
+type Option func(*setupOptions)
+
+type setupOptions struct {
+	jwtManager  *jwt.Manager
+	revocations *jwt.Revocations
+}
+
+// WithJWTManager reuses an existing JWT manager instead of building a new one. Callers pass the
+// previous manager when jwt-secret is unchanged so issued and revoked tokens keep their state.
+func WithJWTManager(m *jwt.Manager) Option {
+	return func(o *setupOptions) { o.jwtManager = m }
+}
+
+// WithRevocations shares a revocation list with a newly built JWT manager.
+func WithRevocations(r *jwt.Revocations) Option {
+	return func(o *setupOptions) { o.revocations = r }
+}
+
+// SetupComponents initializes all production components based on configuration
+func SetupComponents(cfg *config.Config, opts ...Option) (*Components, error) {
+	components := &Components{handlers: make(map[Stage]gin.HandlerFunc)}
+	var options setupOptions
+	for _, opt := range opts {
+		opt(&options)
 	}
 
 	// Initialize JWT manager
-	if cfg.JWTSecret != "" {
-		jwtMgr, err := jwt.NewManagerFromConfig(cfg)
+	if cfg.JWTSecret != "" && options.jwtManager != nil {
+		components.JWTManager = options.jwtManager
+	} else if cfg.JWTSecret != "" {
+		jwtMgr, err := jwt.NewManagerFromConfig(cfg, options.revocations)
 		if err != nil {
 			log.WithError(err).Warn("Failed to initialize JWT manager")
 		} else {
@@ -50,33 +91,13 @@ func SetupComponents(cfg *config.Config) (*Components, error) {
 		}
 	}
 
-	// Initialize circuit breaker manager
-	if cfg.CircuitBreaker.Enabled {
-		cbCfg := circuitbreaker.DefaultConfig()
-		if cfg.CircuitBreaker.FailureThreshold > 0 {
-			cbCfg.FailureThreshold = cfg.CircuitBreaker.FailureThreshold
-		}
-		if cfg.CircuitBreaker.SuccessThreshold > 0 {
-			cbCfg.SuccessThreshold = cfg.CircuitBreaker.SuccessThreshold
-		}
-		if cfg.CircuitBreaker.Timeout != "" {
-			if duration, err := time.ParseDuration(cfg.CircuitBreaker.Timeout); err == nil {
-				cbCfg.Timeout = duration
-			}
-		}
-		if cfg.CircuitBreaker.HalfOpenMaxRequests > 0 {
-			cbCfg.MaxRequests = uint32(cfg.CircuitBreaker.HalfOpenMaxRequests)
-		}
-
-		components.CircuitBreakerMgr = circuitbreaker.NewManager(cbCfg)
-		log.Info("Circuit breaker manager initialized")
-	}
-
 	// Initialize Prometheus metrics
 	if cfg.Metrics.Enabled {
 		components.MetricsCollector = metrics.GetInstance(cfg)
+		registerUsageMetrics(components.MetricsCollector)
 		log.Info("Prometheus metrics collector initialized")
 	}
+	usageMetricsEnabled.Store(cfg.Metrics.Enabled)
 
 	// Initialize rate limiter
 	if cfg.RateLimit.Enabled {
@@ -92,18 +113,8 @@ func SetupComponents(cfg *config.Config) (*Components, error) {
 		log.Info("Rate limiter initialized")
 	}
 
-	return components, nil
-}
-
-// GetMiddlewareChain returns a middleware chain with all production middleware
-func GetMiddlewareChain(cfg *config.Config, components *Components) []gin.HandlerFunc {
-	var chain []gin.HandlerFunc
-
-	// Request ID middleware (always enabled for tracing)
-	chain = append(chain, structured.RequestIDMiddleware())
-
 	// Request validation middleware
-	if cfg.Validation.MaxBodySize > 0 || cfg.Validation.MaxHeaderSize > 0 {
+	if cfg.Validation.MaxBodySize > 0 || cfg.Validation.MaxHeaderSize > 0 || cfg.Validation.MaxQueryLength > 0 {
 		vConfig := middleware.DefaultValidatorConfig()
 		if cfg.Validation.MaxBodySize > 0 {
 			vConfig.MaxBodySize = int64(cfg.Validation.MaxBodySize)
@@ -114,106 +125,159 @@ func GetMiddlewareChain(cfg *config.Config, components *Components) []gin.Handle
 		if cfg.Validation.MaxQueryLength > 0 {
 			vConfig.MaxQueryLength = cfg.Validation.MaxQueryLength
 		}
-		chain = append(chain, middleware.ValidationMiddleware(vConfig))
+		components.handlers[StageValidation] = middleware.ValidationMiddleware(vConfig)
 		log.Info("Request validation middleware enabled")
 	}
 
-	// Rate limiting middleware
 	if components.RateLimiter != nil {
-		chain = append(chain, components.RateLimiter.Middleware())
+		components.handlers[StageRateLimit] = components.RateLimiter.Middleware()
 	}
-
-	// Prometheus metrics middleware
 	if components.MetricsCollector != nil {
-		chain = append(chain, components.MetricsCollector.Middleware())
+		if path := strings.TrimSpace(cfg.Metrics.Path); path != "" && path != "/metrics" {
+			components.metricsPath = path
+		}
+		components.handlers[StageMetrics] = components.metricsMiddleware()
 	}
-
-	// JWT auth middleware (optional - based on configuration)
 	if components.JWTManager != nil {
 		// Use optional auth middleware - validates JWT if present but doesn't require it
 		// This allows the existing AuthMiddleware to handle full authentication
-		chain = append(chain, components.JWTManager.OptionalAuthMiddleware())
+		components.handlers[StageJWT] = components.JWTManager.OptionalAuthMiddleware()
 	}
 
-	return chain
+	return components, nil
 }
 
-// RegisterProductionRoutes registers additional production routes
-func RegisterProductionRoutes(engine *gin.Engine, cfg *config.Config, components *Components) {
-	// Register Prometheus metrics endpoint
-	if components.MetricsCollector != nil && cfg.Metrics.Enabled {
-		metricsPath := "/metrics"
-		if cfg.Metrics.Path != "" {
-			metricsPath = cfg.Metrics.Path
+// metricsMiddleware records request metrics and serves the registry on the custom metrics path.
+// The path is matched here rather than registered as a route so a reload can move it; /metrics
+// itself is served by the health routes.
+func (c *Components) metricsMiddleware() gin.HandlerFunc {
+	record := c.MetricsCollector.Middleware()
+	exposition := c.MetricsCollector.Handler()
+	return func(ctx *gin.Context) {
+		if c.metricsPath != "" && ctx.Request.Method == http.MethodGet && ctx.Request.URL.Path == c.metricsPath {
+			// Unmatched routes default to 404 before the middleware runs.
+			ctx.Status(http.StatusOK)
+			exposition.ServeHTTP(ctx.Writer, ctx.Request)
+			ctx.Abort()
+			return
 		}
-		components.MetricsCollector.RegisterRoutes(engine)
-		log.Infof("Prometheus metrics endpoint registered at %s", metricsPath)
+		record(ctx)
 	}
 }
 
-// ApplyServerOptions applies production server options
-func ApplyServerOptions(cfg *config.Config, components *Components) []api.ServerOption {
-	var opts []api.ServerOption
-
-	// Add middleware chain
-	middlewareChain := GetMiddlewareChain(cfg, components)
-	if len(middlewareChain) > 0 {
-		opts = append(opts, api.WithMiddleware(middlewareChain...))
+// Handler returns the middleware for stage, or nil when the component is disabled.
+func (c *Components) Handler(stage Stage) gin.HandlerFunc {
+	if c == nil {
+		return nil
 	}
+	return c.handlers[stage]
+}
 
-	// Register additional routes
-	opts = append(opts, api.WithRouterConfigurator(func(engine *gin.Engine, handler *handlers.BaseAPIHandler, cfg *config.Config) {
-		RegisterProductionRoutes(engine, cfg, components)
-	}))
+// MetricsHandler returns the Prometheus exposition handler, or nil when metrics are disabled.
+func (c *Components) MetricsHandler() http.Handler {
+	if c == nil || c.MetricsCollector == nil {
+		return nil
+	}
+	return c.MetricsCollector.Handler()
+}
 
-	return opts
+// GetMiddlewareChain returns a middleware chain with all enabled production middleware
+func GetMiddlewareChain(components *Components) []gin.HandlerFunc {
+	var chain []gin.HandlerFunc
+	for _, stage := range Stages {
+		if handler := components.Handler(stage); handler != nil {
+			chain = append(chain, handler)
+		}
+	}
+	return chain
 }
 
-// StartBackgroundServices starts background services like health checks and cleanup
-func StartBackgroundServices(ctx context.Context, cfg *config.Config, components *Components) {
-	// Start JWT token cleanup
-	if components.JWTManager != nil {
-		go func() {
-			ticker := time.NewTicker(5 * time.Minute)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case <-ticker.C:
-					components.JWTManager.CleanExpiredTokens()
-				}
+// DynamicMiddleware returns one middleware per stage that looks up the active components on
+// every request. The server installs it once and swaps components on config reload.
+func DynamicMiddleware(current func() *Components) []gin.HandlerFunc {
+	chain := make([]gin.HandlerFunc, 0, len(Stages))
+	for _, stage := range Stages {
+		stage := stage
+		chain = append(chain, func(c *gin.Context) {
+			if handler := current().Handler(stage); handler != nil {
+				handler(c)
+				return
 			}
-		}()
-	}
-
-	// Start circuit breaker health checks
-	if components.CircuitBreakerMgr != nil && cfg.CircuitBreaker.Enabled {
-		go func() {
-			ticker := time.NewTicker(30 * time.Second)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case <-ticker.C:
-					components.CircuitBreakerMgr.RunHealthCheck(ctx, nil)
-				}
-			}
-		}()
-		log.Info("Circuit breaker health check service started")
+			c.Next()
+		})
+	}
+	return chain
+}
+
+// Start launches background maintenance such as JWT blacklist cleanup.
+func (c *Components) Start(ctx context.Context) {
+	if c == nil || c.JWTManager == nil {
+		return
 	}
+	ctx, c.cancel = context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.JWTManager.CleanExpiredTokens()
+			}
+		}
+	}()
 }
 
 // Shutdown gracefully shuts down all production components
 func (c *Components) Shutdown(ctx context.Context) error {
-	log.Info("Shutting down production components...")
+	if c == nil {
+		return nil
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+	if c.RateLimiter != nil {
+		c.RateLimiter.Stop()
+	}
+	return nil
+}
 
-	// Circuit breaker cleanup is handled by garbage collection
-	// No explicit shutdown needed for most components
+var (
+	usageMetricsOnce    sync.Once
+	usageMetricsEnabled atomic.Bool
+)
 
-	log.Info("Production components shut down")
-	return nil
+// registerUsageMetrics forwards upstream usage records to the Prometheus collector.
+func registerUsageMetrics(collector *metrics.MetricsCollector) {
+	usageMetricsOnce.Do(func() {
+		coreusage.RegisterPlugin(&usageMetricsPlugin{collector: collector})
+	})
+}
+
+// usageMetricsPlugin records token counts and upstream failures per provider and model.
+type usageMetricsPlugin struct {
+	collector *metrics.MetricsCollector
+}
+
+// HandleUsage implements coreusage.Plugin.
+func (p *usageMetricsPlugin) HandleUsage(_ context.Context, record coreusage.Record) {
+	if !usageMetricsEnabled.Load() || p.collector == nil {
+		return
+	}
+	if record.Failed {
+		p.collector.RecordError("upstream", record.Provider)
+	}
+	tokens := map[string]int64{
+		"input":     record.Detail.InputTokens,
+		"output":    record.Detail.OutputTokens,
+		"reasoning": record.Detail.ReasoningTokens,
+		"cached":    record.Detail.CachedTokens,
+	}
+	for tokenType, count := range tokens {
+		if count > 0 {
+			p.collector.RecordTokens(record.Provider, record.Model, tokenType, int(count))
+		}
+	}
 }
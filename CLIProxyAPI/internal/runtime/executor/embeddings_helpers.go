@@ -0,0 +1,206 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// embeddingsAction is the request metadata action that switches Execute to the provider's
+// embeddings endpoint. The payload is always an OpenAI embeddings request.
+const embeddingsAction = "embeddings"
+
+func isEmbeddingsRequest(req cliproxyexecutor.Request) bool {
+	action, _ := req.Metadata["action"].(string)
+	return action == embeddingsAction
+}
+
+// openAIEmbeddingInputs extracts the texts of an OpenAI embeddings request.
+// Pre-tokenized inputs are rejected because Gemini only embeds text.
+func openAIEmbeddingInputs(payload []byte) ([]string, error) {
+	input := gjson.GetBytes(payload, "input")
+	if input.Type == gjson.String {
+		return []string{input.String()}, nil
+	}
+	if input.IsArray() {
+		items := input.Array()
+		texts := make([]string, 0, len(items))
+		for _, item := range items {
+			if item.Type != gjson.String {
+				texts = nil
+				break
+			}
+			texts = append(texts, item.String())
+		}
+		if len(texts) > 0 {
+			return texts, nil
+		}
+	}
+	return nil, statusErr{code: http.StatusBadRequest, msg: "input must be a non-empty string or array of strings"}
+}
+
+// buildGeminiBatchEmbedRequest converts OpenAI embedding inputs into a Gemini batchEmbedContents body.
+func buildGeminiBatchEmbedRequest(model string, inputs []string, payload []byte) []byte {
+	dimensions := gjson.GetBytes(payload, "dimensions").Int()
+	body := []byte(`{"requests":[]}`)
+	for i, text := range inputs {
+		entry := []byte(`{}`)
+		entry, _ = sjson.SetBytes(entry, "model", "models/"+model)
+		entry, _ = sjson.SetBytes(entry, "content.parts.0.text", text)
+		if dimensions > 0 {
+			entry, _ = sjson.SetBytes(entry, "outputDimensionality", dimensions)
+		}
+		body, _ = sjson.SetRawBytes(body, fmt.Sprintf("requests.%d", i), entry)
+	}
+	return body
+}
+
+// buildVertexPredictEmbedRequest converts OpenAI embedding inputs into a Vertex AI predict body.
+func buildVertexPredictEmbedRequest(inputs []string, payload []byte) []byte {
+	body := []byte(`{"instances":[]}`)
+	for i, text := range inputs {
+		body, _ = sjson.SetBytes(body, fmt.Sprintf("instances.%d.content", i), text)
+	}
+	if dimensions := gjson.GetBytes(payload, "dimensions").Int(); dimensions > 0 {
+		body, _ = sjson.SetBytes(body, "parameters.outputDimensionality", dimensions)
+	}
+	return body
+}
+
+// geminiEmbeddingsToOpenAI converts a Gemini batchEmbedContents response (embeddings[].values)
+// or a Vertex predict response (predictions[].embeddings.values) into an OpenAI embeddings list.
+// Prompt tokens come from Vertex statistics when present and are estimated locally otherwise.
+func geminiEmbeddingsToOpenAI(model string, data, payload []byte, inputs []string) ([]byte, usage.Detail) {
+	root := gjson.ParseBytes(data)
+	var vectors []gjson.Result
+	var promptTokens int64
+	if embeddings := root.Get("embeddings"); embeddings.Exists() {
+		for _, embedding := range embeddings.Array() {
+			vectors = append(vectors, embedding.Get("values"))
+		}
+	} else {
+		for _, prediction := range root.Get("predictions").Array() {
+			vectors = append(vectors, prediction.Get("embeddings.values"))
+			promptTokens += prediction.Get("embeddings.statistics.token_count").Int()
+		}
+	}
+	if promptTokens == 0 {
+		promptTokens = estimateEmbeddingTokens(model, inputs)
+	}
+
+	base64Format := strings.EqualFold(gjson.GetBytes(payload, "encoding_format").String(), "base64")
+	out := []byte(`{"object":"list","data":[],"model":"","usage":{"prompt_tokens":0,"total_tokens":0}}`)
+	for i, vector := range vectors {
+		item := []byte(`{"object":"embedding","index":0}`)
+		item, _ = sjson.SetBytes(item, "index", i)
+		if base64Format {
+			item, _ = sjson.SetBytes(item, "embedding", encodeEmbeddingBase64(vector))
+		} else {
+			raw := vector.Raw
+			if !vector.IsArray() {
+				raw = "[]"
+			}
+			item, _ = sjson.SetRawBytes(item, "embedding", []byte(raw))
+		}
+		out, _ = sjson.SetRawBytes(out, fmt.Sprintf("data.%d", i), item)
+	}
+	out, _ = sjson.SetBytes(out, "model", model)
+	out, _ = sjson.SetBytes(out, "usage.prompt_tokens", promptTokens)
+	out, _ = sjson.SetBytes(out, "usage.total_tokens", promptTokens)
+	return out, usage.Detail{InputTokens: promptTokens, TotalTokens: promptTokens}
+}
+
+// encodeEmbeddingBase64 packs a vector as little-endian float32 values, matching OpenAI's base64 format.
+func encodeEmbeddingBase64(vector gjson.Result) string {
+	values := vector.Array()
+	buf := make([]byte, 4*len(values))
+	for i, value := range values {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(value.Float())))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// estimateEmbeddingTokens approximates prompt tokens for upstreams that do not report usage.
+func estimateEmbeddingTokens(model string, inputs []string) int64 {
+	enc, err := tokenizerForModel(model)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, text := range inputs {
+		count, errCount := enc.Count(text)
+		if errCount != nil {
+			return 0
+		}
+		total += int64(count)
+	}
+	return total
+}
+
+// doEmbeddingsRequest posts an embeddings body upstream with the usual request logging and
+// returns the raw response. applyHeaders sets provider credentials on the outgoing request.
+func doEmbeddingsRequest(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, provider, url string, body []byte, applyHeaders func(*http.Request)) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if applyHeaders != nil {
+		applyHeaders(httpReq)
+	}
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, cfg, upstreamRequestLog{
+		URL:       url,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      body,
+		Provider:  provider,
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := newProxyAwareHTTPClient(ctx, cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		recordAPIResponseError(ctx, cfg, err)
+		return nil, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("%s executor: close embeddings response body error: %v", provider, errClose)
+		}
+	}()
+	recordAPIResponseMetadata(ctx, cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		recordAPIResponseError(ctx, cfg, err)
+		return nil, err
+	}
+	appendAPIResponseChunk(ctx, cfg, data)
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		log.Debugf("request error, error status: %d, error body: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), data))
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(data)}
+	}
+	return data, nil
+}
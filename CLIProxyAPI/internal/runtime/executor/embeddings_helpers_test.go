@@ -0,0 +1,113 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAIEmbeddingInputs(t *testing.T) {
+	inputs, err := openAIEmbeddingInputs([]byte(`{"input":["alpha","beta"]}`))
+	if err != nil || len(inputs) != 2 || inputs[1] != "beta" {
+		t.Fatalf("openAIEmbeddingInputs(array) = %v, %v", inputs, err)
+	}
+	inputs, err = openAIEmbeddingInputs([]byte(`{"input":"alpha"}`))
+	if err != nil || len(inputs) != 1 || inputs[0] != "alpha" {
+		t.Fatalf("openAIEmbeddingInputs(string) = %v, %v", inputs, err)
+	}
+	_, err = openAIEmbeddingInputs([]byte(`{"input":[1,2,3]}`))
+	if se, ok := err.(statusErr); !ok || se.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("openAIEmbeddingInputs(tokens) error = %v, want 400", err)
+	}
+}
+
+func TestBuildGeminiBatchEmbedRequest(t *testing.T) {
+	body := buildGeminiBatchEmbedRequest("gemini-embedding-001", []string{"alpha", "beta"}, []byte(`{"dimensions":256}`))
+
+	if got := gjson.GetBytes(body, "requests.#").Int(); got != 2 {
+		t.Fatalf("requests.# = %d, want 2", got)
+	}
+	if got := gjson.GetBytes(body, "requests.1.model").String(); got != "models/gemini-embedding-001" {
+		t.Fatalf("requests.1.model = %q", got)
+	}
+	if got := gjson.GetBytes(body, "requests.1.content.parts.0.text").String(); got != "beta" {
+		t.Fatalf("requests.1.content.parts.0.text = %q, want beta", got)
+	}
+	if got := gjson.GetBytes(body, "requests.0.outputDimensionality").Int(); got != 256 {
+		t.Fatalf("requests.0.outputDimensionality = %d, want 256", got)
+	}
+}
+
+func TestGeminiEmbeddingsToOpenAI_VertexPredictions(t *testing.T) {
+	data := []byte(`{"predictions":[{"embeddings":{"values":[0.5,-1],"statistics":{"token_count":3}}},{"embeddings":{"values":[0.25,2],"statistics":{"token_count":4}}}]}`)
+	out, detail := geminiEmbeddingsToOpenAI("text-embedding-005", data, []byte(`{"encoding_format":"base64"}`), []string{"a", "b"})
+
+	if detail.InputTokens != 7 || detail.TotalTokens != 7 {
+		t.Fatalf("usage detail = %+v, want 7 input tokens", detail)
+	}
+	if got := gjson.GetBytes(out, "usage.prompt_tokens").Int(); got != 7 {
+		t.Fatalf("usage.prompt_tokens = %d, want 7", got)
+	}
+	if got := gjson.GetBytes(out, "data.1.index").Int(); got != 1 {
+		t.Fatalf("data.1.index = %d, want 1", got)
+	}
+	raw, err := base64.StdEncoding.DecodeString(gjson.GetBytes(out, "data.0.embedding").String())
+	if err != nil || len(raw) != 8 {
+		t.Fatalf("data.0.embedding decode = %d bytes, %v", len(raw), err)
+	}
+	if got := math.Float32frombits(binary.LittleEndian.Uint32(raw[4:])); got != -1 {
+		t.Fatalf("decoded value = %v, want -1", got)
+	}
+}
+
+func TestGeminiExecutorExecute_Embeddings(t *testing.T) {
+	var gotPath, gotKey string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("x-goog-api-key")
+		gotBody, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"embeddings":[{"values":[0.1,0.2]},{"values":[0.3,0.4]}]}`))
+	}))
+	defer server.Close()
+
+	exec := NewGeminiExecutor(&config.Config{})
+	auth := &cliproxyauth.Auth{ID: "gemini-embed", Provider: "gemini", Attributes: map[string]string{"api_key": "k", "base_url": server.URL}}
+	req := cliproxyexecutor.Request{
+		Model:    "gemini-embedding-001",
+		Payload:  []byte(`{"model":"gemini-embedding-001","input":["alpha","beta"]}`),
+		Metadata: map[string]any{"action": embeddingsAction},
+	}
+	resp, err := exec.Execute(context.Background(), auth, req, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if gotPath != "/v1beta/models/gemini-embedding-001:batchEmbedContents" {
+		t.Fatalf("upstream path = %q", gotPath)
+	}
+	if gotKey != "k" {
+		t.Fatalf("x-goog-api-key = %q, want k", gotKey)
+	}
+	if got := gjson.GetBytes(gotBody, "requests.#").Int(); got != 2 {
+		t.Fatalf("upstream requests.# = %d, want 2", got)
+	}
+	if got := gjson.GetBytes(resp.Payload, "object").String(); got != "list" {
+		t.Fatalf("object = %q, want list", got)
+	}
+	if got := gjson.GetBytes(resp.Payload, "data.1.embedding.1").Float(); got != 0.4 {
+		t.Fatalf("data.1.embedding.1 = %v, want 0.4", got)
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.prompt_tokens").Int(); got <= 0 {
+		t.Fatalf("usage.prompt_tokens = %d, want estimated tokens", got)
+	}
+}
@@ -95,6 +95,10 @@ func (e *GeminiVertexExecutor) HttpRequest(ctx context.Context, auth *cliproxyau
 
 // Execute performs a non-streaming request to the Vertex AI API.
 func (e *GeminiVertexExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	if isEmbeddingsRequest(req) {
+		return e.executeEmbeddings(ctx, auth, req)
+	}
+
 	// Try API key authentication first
 	apiKey, baseURL := vertexAPICreds(auth)
 
@@ -111,6 +115,60 @@ func (e *GeminiVertexExecutor) Execute(ctx context.Context, auth *cliproxyauth.A
 	return e.executeWithAPIKey(ctx, auth, req, opts, apiKey, baseURL)
 }
 
+// executeEmbeddings serves an OpenAI embeddings request through the Vertex AI predict endpoint,
+// using the API key when configured and the service account otherwise.
+func (e *GeminiVertexExecutor) executeEmbeddings(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request) (resp cliproxyexecutor.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	inputs, err := openAIEmbeddingInputs(req.Payload)
+	if err != nil {
+		return resp, err
+	}
+	model := req.Model
+	if override := e.resolveUpstreamModel(req.Model, auth); override != "" {
+		model = override
+	}
+
+	var url string
+	var applyHeaders func(*http.Request)
+	apiKey, baseURL := vertexAPICreds(auth)
+	if apiKey != "" {
+		if baseURL == "" {
+			baseURL = "https://generativelanguage.googleapis.com"
+		}
+		url = fmt.Sprintf("%s/%s/publishers/google/models/%s:predict", baseURL, vertexAPIVersion, model)
+		applyHeaders = func(httpReq *http.Request) {
+			httpReq.Header.Set("x-goog-api-key", apiKey)
+			applyGeminiHeaders(httpReq, auth)
+		}
+	} else {
+		projectID, location, saJSON, errCreds := vertexCreds(auth)
+		if errCreds != nil {
+			return resp, errCreds
+		}
+		token, errTok := vertexAccessToken(ctx, e.cfg, auth, saJSON)
+		if errTok != nil || token == "" {
+			log.Errorf("vertex executor: access token error: %v", errTok)
+			return resp, statusErr{code: 500, msg: "internal server error"}
+		}
+		url = fmt.Sprintf("%s/%s/projects/%s/locations/%s/publishers/google/models/%s:predict", vertexBaseURL(location), vertexAPIVersion, projectID, location, model)
+		applyHeaders = func(httpReq *http.Request) {
+			httpReq.Header.Set("Authorization", "Bearer "+token)
+			applyGeminiHeaders(httpReq, auth)
+		}
+	}
+
+	body := buildVertexPredictEmbedRequest(inputs, req.Payload)
+	data, err := doEmbeddingsRequest(ctx, e.cfg, auth, e.Identifier(), url, body, applyHeaders)
+	if err != nil {
+		return resp, err
+	}
+	out, detail := geminiEmbeddingsToOpenAI(req.Model, data, req.Payload, inputs)
+	reporter.publish(ctx, detail)
+	return cliproxyexecutor.Response{Payload: out}, nil
+}
+
 // ExecuteStream performs a streaming request to the Vertex AI API.
 func (e *GeminiVertexExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (stream <-chan cliproxyexecutor.StreamChunk, err error) {
 	// Try API key authentication first
@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+)
+
+// reportRateLimits forwards the rate limit windows found in headers to the auth manager.
+// Parsers that find nothing return nil and are ignored.
+func reportRateLimits(ctx context.Context, headers http.Header, parsers ...func(http.Header, time.Time) map[string]cliproxyauth.RateLimitWindow) {
+	if len(headers) == 0 {
+		return
+	}
+	now := time.Now()
+	windows := make(map[string]cliproxyauth.RateLimitWindow)
+	for _, parse := range parsers {
+		for name, window := range parse(headers, now) {
+			windows[name] = window
+		}
+	}
+	if len(windows) == 0 {
+		return
+	}
+	cliproxyauth.ReportRateLimit(ctx, &cliproxyauth.RateLimitState{Windows: windows, UpdatedAt: now})
+}
+
+// parseAnthropicRateLimits reads the per model anthropic-ratelimit-{requests,tokens,input-tokens,
+// output-tokens}-* windows (RFC 3339 resets) and the account wide unified 5h/7d subscription
+// windows (utilization 0-1, Unix resets).
+func parseAnthropicRateLimits(headers http.Header, _ time.Time) map[string]cliproxyauth.RateLimitWindow {
+	windows := make(map[string]cliproxyauth.RateLimitWindow)
+	for _, name := range []string{"requests", "tokens", "input-tokens", "output-tokens"} {
+		prefix := "Anthropic-Ratelimit-" + name + "-"
+		remaining, okRemaining := headerInt(headers, prefix+"Remaining")
+		if !okRemaining {
+			continue
+		}
+		window := cliproxyauth.RateLimitWindow{Remaining: &remaining}
+		window.Limit, _ = headerInt(headers, prefix+"Limit")
+		if reset, errParse := time.Parse(time.RFC3339, strings.TrimSpace(headers.Get(prefix+"Reset"))); errParse == nil {
+			window.ResetAt = reset
+		}
+		windows[strings.ReplaceAll(name, "-", "_")] = window
+	}
+	for _, name := range []string{"5h", "7d"} {
+		prefix := "Anthropic-Ratelimit-Unified-" + name + "-"
+		utilization, errParse := strconv.ParseFloat(strings.TrimSpace(headers.Get(prefix+"Utilization")), 64)
+		if errParse != nil {
+			continue
+		}
+		used := utilization * 100
+		window := cliproxyauth.RateLimitWindow{UsedPercent: &used, AccountWide: true}
+		if reset, ok := headerInt(headers, prefix+"Reset"); ok {
+			window.ResetAt = time.Unix(reset, 0)
+		}
+		windows["unified_"+name] = window
+	}
+	return windows
+}
+
+// parseOpenAIRateLimits reads x-ratelimit-{limit,remaining,reset}-{requests,tokens}, where resets
+// are durations such as "6m0s" or "20ms".
+func parseOpenAIRateLimits(headers http.Header, now time.Time) map[string]cliproxyauth.RateLimitWindow {
+	windows := make(map[string]cliproxyauth.RateLimitWindow)
+	for _, name := range []string{"requests", "tokens"} {
+		remaining, okRemaining := headerInt(headers, "X-Ratelimit-Remaining-"+name)
+		if !okRemaining {
+			continue
+		}
+		window := cliproxyauth.RateLimitWindow{Remaining: &remaining}
+		window.Limit, _ = headerInt(headers, "X-Ratelimit-Limit-"+name)
+		if reset, errParse := time.ParseDuration(strings.TrimSpace(headers.Get("X-Ratelimit-Reset-" + name))); errParse == nil {
+			window.ResetAt = now.Add(reset)
+		}
+		windows[name] = window
+	}
+	return windows
+}
+
+// parseCodexRateLimits reads the account wide Codex usage windows
+// x-codex-{primary,secondary}-used-percent with x-codex-*-reset-after-seconds or
+// x-codex-*-reset-at (Unix seconds).
+func parseCodexRateLimits(headers http.Header, now time.Time) map[string]cliproxyauth.RateLimitWindow {
+	windows := make(map[string]cliproxyauth.RateLimitWindow)
+	for _, name := range []string{"primary", "secondary"} {
+		prefix := "X-Codex-" + name + "-"
+		used, errParse := strconv.ParseFloat(strings.TrimSpace(headers.Get(prefix+"Used-Percent")), 64)
+		if errParse != nil {
+			continue
+		}
+		window := cliproxyauth.RateLimitWindow{UsedPercent: &used, AccountWide: true}
+		if after, ok := headerInt(headers, prefix+"Reset-After-Seconds"); ok {
+			window.ResetAt = now.Add(time.Duration(after) * time.Second)
+		} else if at, okAt := headerInt(headers, prefix+"Reset-At"); okAt {
+			window.ResetAt = time.Unix(at, 0)
+		}
+		windows[name] = window
+	}
+	return windows
+}
+
+func headerInt(headers http.Header, key string) (int64, bool) {
+	raw := strings.TrimSpace(headers.Get(key))
+	if raw == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
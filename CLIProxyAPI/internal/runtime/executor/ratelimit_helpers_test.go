@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseAnthropicRateLimits(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("anthropic-ratelimit-requests-limit", "50")
+	headers.Set("anthropic-ratelimit-requests-remaining", "0")
+	headers.Set("anthropic-ratelimit-requests-reset", "2026-10-16T10:00:00Z")
+	headers.Set("anthropic-ratelimit-output-tokens-remaining", "8000")
+	headers.Set("anthropic-ratelimit-unified-5h-utilization", "0.42")
+	headers.Set("anthropic-ratelimit-unified-5h-reset", "1791000000")
+
+	windows := parseAnthropicRateLimits(headers, time.Now())
+	requests := windows["requests"]
+	if requests.Limit != 50 || requests.Remaining == nil || *requests.Remaining != 0 || !requests.ResetAt.Equal(time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("requests window = %+v", requests)
+	}
+	if requests.AccountWide {
+		t.Fatal("anthropic requests window should be scoped to the model")
+	}
+	if output := windows["output_tokens"]; output.Remaining == nil || *output.Remaining != 8000 {
+		t.Fatalf("output_tokens window = %+v", output)
+	}
+	unified := windows["unified_5h"]
+	if unified.UsedPercent == nil || *unified.UsedPercent != 42 || unified.ResetAt.Unix() != 1791000000 || !unified.AccountWide {
+		t.Fatalf("unified_5h window = %+v", unified)
+	}
+	if len(windows) != 3 {
+		t.Fatalf("windows = %v, want 3", windows)
+	}
+}
+
+func TestParseOpenAIAndCodexRateLimits(t *testing.T) {
+	now := time.Now()
+	headers := http.Header{}
+	headers.Set("x-ratelimit-limit-requests", "500")
+	headers.Set("x-ratelimit-remaining-requests", "499")
+	headers.Set("x-ratelimit-reset-requests", "120ms")
+	headers.Set("x-ratelimit-remaining-tokens", "not-a-number")
+	headers.Set("x-codex-primary-used-percent", "100")
+	headers.Set("x-codex-primary-reset-after-seconds", "600")
+	headers.Set("x-codex-secondary-used-percent", "12.5")
+	headers.Set("x-codex-secondary-reset-at", "1791000000")
+
+	openai := parseOpenAIRateLimits(headers, now)
+	requests := openai["requests"]
+	if requests.Limit != 500 || *requests.Remaining != 499 || !requests.ResetAt.Equal(now.Add(120*time.Millisecond)) {
+		t.Fatalf("requests window = %+v", requests)
+	}
+	if _, ok := openai["tokens"]; ok {
+		t.Fatal("unparseable tokens window should be skipped")
+	}
+
+	codex := parseCodexRateLimits(headers, now)
+	if primary := codex["primary"]; *primary.UsedPercent != 100 || !primary.ResetAt.Equal(now.Add(10*time.Minute)) || !primary.AccountWide {
+		t.Fatalf("primary window = %+v", primary)
+	}
+	if secondary := codex["secondary"]; *secondary.UsedPercent != 12.5 || secondary.ResetAt.Unix() != 1791000000 {
+		t.Fatalf("secondary window = %+v", secondary)
+	}
+}
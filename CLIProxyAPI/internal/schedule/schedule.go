@@ -0,0 +1,275 @@
+// Package schedule parses the time windows that restrict when a credential may be used.
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookahead bounds the search for the next active instant.
+const lookahead = 8 * 24 * time.Hour
+
+// Schedule restricts when a credential may be selected. Rules starting with "never" exclude
+// time; all other rules are active windows. A credential is active when no exclusion matches
+// and, if any window is declared, at least one window matches.
+//
+// A rule is made of optional "never" ("never on" reads better), an optional day set, an
+// optional HH:MM-HH:MM range and an optional time zone (default UTC), for example
+// "weekdays 18:00-08:00 UTC", "never on mondays", "sat,sun" or "mon-fri 09:00-17:00 Europe/Berlin".
+// Day sets accept day names, ranges such as mon-fri, "weekdays", "weekends" and "daily".
+// A range that ends before it starts runs past midnight and belongs to the day it starts on.
+type Schedule struct {
+	rules []scheduleRule
+}
+
+type scheduleRule struct {
+	exclude  bool
+	days     [7]bool
+	hasRange bool
+	start    int // minutes after midnight
+	end      int
+	loc      *time.Location
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse parses schedule rules. Empty rules are ignored; a schedule without rules is nil.
+func Parse(rules []string) (*Schedule, error) {
+	schedule := &Schedule{}
+	for _, raw := range rules {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		rule, err := parseRule(raw)
+		if err != nil {
+			return nil, fmt.Errorf("schedule rule %q: %w", raw, err)
+		}
+		schedule.rules = append(schedule.rules, rule)
+	}
+	if len(schedule.rules) == 0 {
+		return nil, nil
+	}
+	return schedule, nil
+}
+
+func parseRule(raw string) (scheduleRule, error) {
+	rule := scheduleRule{loc: time.UTC}
+	tokens := strings.Fields(raw)
+	if len(tokens) > 0 && strings.EqualFold(tokens[0], "never") {
+		rule.exclude = true
+		tokens = tokens[1:]
+		if len(tokens) > 0 && strings.EqualFold(tokens[0], "on") {
+			tokens = tokens[1:]
+		}
+		if len(tokens) == 0 {
+			return rule, fmt.Errorf("never needs days or a time range")
+		}
+	}
+	var hasDays, hasZone bool
+	for _, token := range tokens {
+		switch {
+		case strings.Contains(token, ":"):
+			if rule.hasRange {
+				return rule, fmt.Errorf("more than one time range")
+			}
+			start, end, err := parseTimeRange(token)
+			if err != nil {
+				return rule, err
+			}
+			rule.hasRange, rule.start, rule.end = true, start, end
+		case !hasDays && parseDaySet(token, &rule.days):
+			hasDays = true
+		case !hasZone:
+			loc, err := loadLocation(token)
+			if err != nil {
+				return rule, fmt.Errorf("unknown token %q", token)
+			}
+			rule.loc, hasZone = loc, true
+		default:
+			return rule, fmt.Errorf("unknown token %q", token)
+		}
+	}
+	if !hasDays {
+		for i := range rule.days {
+			rule.days[i] = true
+		}
+	}
+	return rule, nil
+}
+
+// parseTimeRange parses HH:MM-HH:MM. 24:00 is accepted as an end time; equal bounds mean all day.
+func parseTimeRange(token string) (int, int, error) {
+	parts := strings.SplitN(token, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("time range %q must look like 18:00-08:00", token)
+	}
+	start, errStart := parseClock(parts[0])
+	end, errEnd := parseClock(parts[1])
+	if errStart != nil || errEnd != nil || start == 24*60 {
+		return 0, 0, fmt.Errorf("time range %q must look like 18:00-08:00", token)
+	}
+	return start, end, nil
+}
+
+func parseClock(raw string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(raw), ":")
+	if !ok {
+		return 0, fmt.Errorf("missing ':'")
+	}
+	hours, errHours := strconv.Atoi(hh)
+	minutes, errMinutes := strconv.Atoi(mm)
+	if errHours != nil || errMinutes != nil || hours < 0 || minutes < 0 || minutes > 59 || hours > 24 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("invalid clock %q", raw)
+	}
+	return hours*60 + minutes, nil
+}
+
+// parseDaySet fills days from a comma separated list of day names, ranges and keywords.
+func parseDaySet(token string, days *[7]bool) bool {
+	var parsed [7]bool
+	for _, item := range strings.Split(strings.ToLower(token), ",") {
+		switch item {
+		case "daily", "everyday":
+			for i := range parsed {
+				parsed[i] = true
+			}
+			continue
+		case "weekdays":
+			for d := time.Monday; d <= time.Friday; d++ {
+				parsed[d] = true
+			}
+			continue
+		case "weekends":
+			parsed[time.Saturday], parsed[time.Sunday] = true, true
+			continue
+		}
+		from, to, isRange := strings.Cut(item, "-")
+		first, ok := parseWeekday(from)
+		if !ok {
+			return false
+		}
+		last := first
+		if isRange {
+			if last, ok = parseWeekday(to); !ok {
+				return false
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			parsed[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	*days = parsed
+	return true
+}
+
+// parseWeekday accepts "mon", "monday" and "mondays".
+func parseWeekday(raw string) (time.Weekday, bool) {
+	raw = strings.TrimSuffix(strings.TrimSpace(raw), "s")
+	if len(raw) < 3 {
+		return 0, false
+	}
+	day, ok := weekdayNames[raw[:3]]
+	if !ok || !strings.HasPrefix(strings.ToLower(day.String()), raw) {
+		return 0, false
+	}
+	return day, true
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if strings.EqualFold(name, "utc") || strings.EqualFold(name, "z") {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
+func (r scheduleRule) matches(t time.Time) bool {
+	local := t.In(r.loc)
+	day := local.Weekday()
+	if !r.hasRange {
+		return r.days[day]
+	}
+	minute := local.Hour()*60 + local.Minute()
+	switch {
+	case r.start == r.end:
+		return r.days[day]
+	case r.start < r.end:
+		return r.days[day] && minute >= r.start && minute < r.end
+	default:
+		// Past midnight, the window belongs to the previous day.
+		return (r.days[day] && minute >= r.start) || (r.days[(day+6)%7] && minute < r.end)
+	}
+}
+
+// Active reports whether the schedule allows use of the credential at t. A nil schedule is
+// always active.
+func (s *Schedule) Active(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	hasWindow, inWindow := false, false
+	for _, rule := range s.rules {
+		if rule.exclude {
+			if rule.matches(t) {
+				return false
+			}
+			continue
+		}
+		hasWindow = true
+		if !inWindow && rule.matches(t) {
+			inWindow = true
+		}
+	}
+	return !hasWindow || inWindow
+}
+
+// NextActive returns the first instant after now at which the schedule is active, or false
+// when it stays inactive for more than a week.
+func (s *Schedule) NextActive(now time.Time) (time.Time, bool) {
+	if s.Active(now) {
+		return now, true
+	}
+	var boundaries []time.Time
+	for _, rule := range s.rules {
+		local := now.In(rule.loc)
+		for offset := 0; offset <= 8; offset++ {
+			year, month, date := local.Year(), local.Month(), local.Day()+offset
+			boundaries = append(boundaries, time.Date(year, month, date, 0, 0, 0, 0, rule.loc))
+			if rule.hasRange {
+				// Build boundaries from the wall clock so days with a DST change still land on
+				// the configured times.
+				boundaries = append(boundaries,
+					time.Date(year, month, date, rule.start/60, rule.start%60, 0, 0, rule.loc),
+					time.Date(year, month, date, rule.end/60, rule.end%60, 0, 0, rule.loc))
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+	limit := now.Add(lookahead)
+	for _, boundary := range boundaries {
+		if boundary.After(now) && !boundary.After(limit) && s.Active(boundary) {
+			return boundary, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// SplitRules splits a ';' separated schedule into its rules.
+func SplitRules(raw string) []string {
+	var rules []string
+	for _, rule := range strings.Split(raw, ";") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/db"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers/openai"
+)
+
+const responseCacheKeyPrefix = "responses:"
+
+// PostgresResponseStore keeps Responses API results in the cache table of a db.Repo so that
+// previous_response_id chaining survives restarts and works across replicas.
+type PostgresResponseStore struct {
+	repo *db.Repo
+	ttl  time.Duration
+}
+
+// NewPostgresResponseStore ensures the cache table exists and returns a store backed by repo.
+// A non-positive ttl selects openai.DefaultResponseStoreTTL.
+func NewPostgresResponseStore(ctx context.Context, repo *db.Repo, ttl time.Duration) (*PostgresResponseStore, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("response store: repo is nil")
+	}
+	if ttl <= 0 {
+		ttl = openai.DefaultResponseStoreTTL
+	}
+	if err := repo.Schema().EnsureCacheTable(ctx); err != nil {
+		return nil, fmt.Errorf("response store: ensure cache table: %w", err)
+	}
+	return &PostgresResponseStore{repo: repo, ttl: ttl}, nil
+}
+
+// Get implements openai.ResponseStore.
+func (s *PostgresResponseStore) Get(ctx context.Context, id string) (*openai.StoredResponse, error) {
+	data, err := s.repo.Queries().GetCache(ctx, responseCacheKeyPrefix+id)
+	if errors.Is(err, db.ErrCacheMiss) {
+		return nil, openai.ErrResponseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("response store: %w", err)
+	}
+	var resp openai.StoredResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("response store: decode %s: %w", id, err)
+	}
+	return &resp, nil
+}
+
+// Put implements openai.ResponseStore.
+func (s *PostgresResponseStore) Put(ctx context.Context, resp *openai.StoredResponse) error {
+	if resp == nil || resp.ID == "" {
+		return fmt.Errorf("response store: id is required")
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("response store: encode %s: %w", resp.ID, err)
+	}
+	if err = s.repo.Queries().SetCache(ctx, responseCacheKeyPrefix+resp.ID, data, s.ttl, "application/json", []string{"responses"}); err != nil {
+		return fmt.Errorf("response store: %w", err)
+	}
+	return nil
+}
+
+// Delete implements openai.ResponseStore.
+func (s *PostgresResponseStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Queries().DeleteCache(ctx, responseCacheKeyPrefix+id); err != nil {
+		return fmt.Errorf("response store: %w", err)
+	}
+	return nil
+}
@@ -26,11 +26,14 @@ var (
 // It supports:
 // - instructions -> system message
 // - input[].type==message with input_text/output_text -> user/assistant messages
-// - function_call -> assistant tool_use
+// - reasoning (with encrypted_content) -> assistant thinking / redacted_thinking
+// - function_call -> assistant tool_use, keeping call_id as the tool_use id
 // - function_call_output -> user tool_result
 // - tools[].parameters -> tools[].input_schema
 // - max_output_tokens -> max_tokens
 // - stream passthrough via parameter
+// Consecutive items of the same role are merged so parallel tool calls and their results
+// form single turns. previous_response_id is not sent upstream; Claude keeps no server state.
 func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte, stream bool) []byte {
 	rawJSON := bytes.Clone(inputRawJSON)
 
@@ -95,15 +98,15 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 	// Stream
 	out, _ = sjson.Set(out, "stream", stream)
 
+	messages := &claudeMessageBuilder{}
+
 	// instructions -> as a leading message (use role user for Claude API compatibility)
 	instructionsText := ""
 	extractedFromSystem := false
 	if instr := root.Get("instructions"); instr.Exists() && instr.Type == gjson.String {
 		instructionsText = instr.String()
 		if instructionsText != "" {
-			sysMsg := `{"role":"user","content":""}`
-			sysMsg, _ = sjson.Set(sysMsg, "content", instructionsText)
-			out, _ = sjson.SetRaw(out, "messages.-1", sysMsg)
+			messages.addText("user", instructionsText)
 		}
 	}
 
@@ -127,9 +130,7 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 					}
 					instructionsText = builder.String()
 					if instructionsText != "" {
-						sysMsg := `{"role":"user","content":""}`
-						sysMsg, _ = sjson.Set(sysMsg, "content", instructionsText)
-						out, _ = sjson.SetRaw(out, "messages.-1", sysMsg)
+						messages.addText("user", instructionsText)
 						extractedFromSystem = true
 					}
 				}
@@ -154,7 +155,6 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 				var role string
 				var textAggregate strings.Builder
 				var partsJSON []string
-				hasImage := false
 				if parts := item.Get("content"); parts.Exists() && parts.IsArray() {
 					parts.ForEach(func(_, part gjson.Result) bool {
 						ptype := part.Get("type").String()
@@ -173,38 +173,10 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 								role = "assistant"
 							}
 						case "input_image":
-							url := part.Get("image_url").String()
-							if url == "" {
-								url = part.Get("url").String()
-							}
-							if url != "" {
-								var contentPart string
-								if strings.HasPrefix(url, "data:") {
-									trimmed := strings.TrimPrefix(url, "data:")
-									mediaAndData := strings.SplitN(trimmed, ";base64,", 2)
-									mediaType := "application/octet-stream"
-									data := ""
-									if len(mediaAndData) == 2 {
-										if mediaAndData[0] != "" {
-											mediaType = mediaAndData[0]
-										}
-										data = mediaAndData[1]
-									}
-									if data != "" {
-										contentPart = `{"type":"image","source":{"type":"base64","media_type":"","data":""}}`
-										contentPart, _ = sjson.Set(contentPart, "source.media_type", mediaType)
-										contentPart, _ = sjson.Set(contentPart, "source.data", data)
-									}
-								} else {
-									contentPart = `{"type":"image","source":{"type":"url","url":""}}`
-									contentPart, _ = sjson.Set(contentPart, "source.url", url)
-								}
-								if contentPart != "" {
-									partsJSON = append(partsJSON, contentPart)
-									if role == "" {
-										role = "user"
-									}
-									hasImage = true
+							if contentPart := convertResponsesImagePart(part); contentPart != "" {
+								partsJSON = append(partsJSON, contentPart)
+								if role == "" {
+									role = "user"
 								}
 							}
 						}
@@ -214,41 +186,35 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 					textAggregate.WriteString(parts.String())
 				}
 
-				// Fallback to given role if content types not decisive
+				// Fallback to given role if content types not decisive.
+				// Claude has no system role inside messages, so leftover system input becomes user input.
 				if role == "" {
-					r := item.Get("role").String()
-					switch r {
-					case "user", "assistant", "system":
-						role = r
+					switch item.Get("role").String() {
+					case "assistant":
+						role = "assistant"
 					default:
 						role = "user"
 					}
 				}
 
 				if len(partsJSON) > 0 {
-					msg := `{"role":"","content":[]}`
-					msg, _ = sjson.Set(msg, "role", role)
-					if len(partsJSON) == 1 && !hasImage {
-						// Preserve legacy behavior for single text content
-						msg, _ = sjson.Delete(msg, "content")
-						textPart := gjson.Parse(partsJSON[0])
-						msg, _ = sjson.Set(msg, "content", textPart.Get("text").String())
-					} else {
-						for _, partJSON := range partsJSON {
-							msg, _ = sjson.SetRaw(msg, "content.-1", partJSON)
-						}
+					for _, partJSON := range partsJSON {
+						messages.add(role, partJSON)
 					}
-					out, _ = sjson.SetRaw(out, "messages.-1", msg)
-				} else if textAggregate.Len() > 0 || role == "system" {
-					msg := `{"role":"","content":""}`
-					msg, _ = sjson.Set(msg, "role", role)
-					msg, _ = sjson.Set(msg, "content", textAggregate.String())
-					out, _ = sjson.SetRaw(out, "messages.-1", msg)
+				} else if textAggregate.Len() > 0 {
+					messages.addText(role, textAggregate.String())
+				}
+
+			case "reasoning":
+				// Claude only accepts thinking blocks it signed itself; the signature travels in
+				// encrypted_content. Unsigned reasoning from other providers cannot be replayed.
+				if block := convertResponsesReasoningItem(item); block != "" {
+					messages.add("assistant", block)
 				}
 
 			case "function_call":
 				// Map to assistant tool_use
-				callID := item.Get("call_id").String()
+				callID := sanitizeToolUseID(item.Get("call_id").String())
 				if callID == "" {
 					callID = genToolCallID()
 				}
@@ -264,27 +230,35 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 						toolUse, _ = sjson.SetRaw(toolUse, "input", argsJSON.Raw)
 					}
 				}
-
-				asst := `{"role":"assistant","content":[]}`
-				asst, _ = sjson.SetRaw(asst, "content.-1", toolUse)
-				out, _ = sjson.SetRaw(out, "messages.-1", asst)
+				messages.addToolUse(callID, toolUse)
 
 			case "function_call_output":
-				// Map to user tool_result
-				callID := item.Get("call_id").String()
-				outputStr := item.Get("output").String()
+				// Map to user tool_result. Outputs whose call is not part of this request (for example
+				// when the client relies on previous_response_id) cannot be sent as tool_result blocks,
+				// because Claude rejects results without a matching tool_use; they degrade to text.
+				callID := sanitizeToolUseID(item.Get("call_id").String())
+				output := item.Get("output")
+				if !messages.hasToolUse(callID) {
+					messages.addText("user", fmt.Sprintf("Tool result for call %s:\n%s", item.Get("call_id").String(), responsesOutputText(output)))
+					return true
+				}
 				toolResult := `{"type":"tool_result","tool_use_id":"","content":""}`
 				toolResult, _ = sjson.Set(toolResult, "tool_use_id", callID)
-				toolResult, _ = sjson.Set(toolResult, "content", outputStr)
-
-				usr := `{"role":"user","content":[]}`
-				usr, _ = sjson.SetRaw(usr, "content.-1", toolResult)
-				out, _ = sjson.SetRaw(out, "messages.-1", usr)
+				if output.IsArray() {
+					toolResult, _ = sjson.SetRaw(toolResult, "content", convertResponsesToolOutputParts(output))
+				} else {
+					toolResult, _ = sjson.Set(toolResult, "content", output.String())
+				}
+				messages.add("user", toolResult)
 			}
 			return true
 		})
 	}
 
+	for _, msg := range messages.render() {
+		out, _ = sjson.SetRaw(out, "messages.-1", msg)
+	}
+
 	// tools mapping: parameters -> input_schema
 	if tools := root.Get("tools"); tools.Exists() && tools.IsArray() {
 		toolsJSON := "[]"
@@ -337,3 +311,186 @@ func ConvertOpenAIResponsesRequestToClaude(modelName string, inputRawJSON []byte
 
 	return []byte(out)
 }
+
+// redactedThinkingPrefix marks encrypted_content that carries a Claude redacted_thinking payload
+// rather than a thinking signature.
+const redactedThinkingPrefix = "redacted_thinking:"
+
+// claudeMessageBuilder accumulates Claude messages, merging consecutive blocks of the same role.
+type claudeMessageBuilder struct {
+	messages   []claudeMessage
+	toolUseIDs map[string]struct{}
+}
+
+type claudeMessage struct {
+	role   string
+	blocks []string
+}
+
+func (b *claudeMessageBuilder) add(role, block string) {
+	if n := len(b.messages); n > 0 && b.messages[n-1].role == role {
+		b.messages[n-1].blocks = append(b.messages[n-1].blocks, block)
+		return
+	}
+	b.messages = append(b.messages, claudeMessage{role: role, blocks: []string{block}})
+}
+
+func (b *claudeMessageBuilder) addText(role, text string) {
+	block := `{"type":"text","text":""}`
+	block, _ = sjson.Set(block, "text", text)
+	b.add(role, block)
+}
+
+func (b *claudeMessageBuilder) addToolUse(id, block string) {
+	if b.toolUseIDs == nil {
+		b.toolUseIDs = make(map[string]struct{})
+	}
+	b.toolUseIDs[id] = struct{}{}
+	b.add("assistant", block)
+}
+
+func (b *claudeMessageBuilder) hasToolUse(id string) bool {
+	_, ok := b.toolUseIDs[id]
+	return ok
+}
+
+// render returns the messages as JSON. Tool results lead their user turn as Claude requires,
+// and a turn holding a single text block keeps the plain string content form.
+func (b *claudeMessageBuilder) render() []string {
+	rendered := make([]string, 0, len(b.messages))
+	for _, msg := range b.messages {
+		blocks := msg.blocks
+		if msg.role == "user" {
+			ordered := make([]string, 0, len(blocks))
+			var rest []string
+			for _, block := range blocks {
+				if gjson.Get(block, "type").String() == "tool_result" {
+					ordered = append(ordered, block)
+				} else {
+					rest = append(rest, block)
+				}
+			}
+			blocks = append(ordered, rest...)
+		}
+		out := `{"role":"","content":[]}`
+		out, _ = sjson.Set(out, "role", msg.role)
+		if len(blocks) == 1 && gjson.Get(blocks[0], "type").String() == "text" {
+			out, _ = sjson.Set(out, "content", gjson.Get(blocks[0], "text").String())
+		} else {
+			for _, block := range blocks {
+				out, _ = sjson.SetRaw(out, "content.-1", block)
+			}
+		}
+		rendered = append(rendered, out)
+	}
+	return rendered
+}
+
+// convertResponsesReasoningItem maps a Responses reasoning item to a Claude thinking block.
+// It returns "" when the item carries no Claude signature.
+func convertResponsesReasoningItem(item gjson.Result) string {
+	encrypted := item.Get("encrypted_content").String()
+	if encrypted == "" {
+		return ""
+	}
+	if data, ok := strings.CutPrefix(encrypted, redactedThinkingPrefix); ok {
+		block := `{"type":"redacted_thinking","data":""}`
+		block, _ = sjson.Set(block, "data", data)
+		return block
+	}
+	// The signature covers the full reasoning text, so prefer reasoning_text content and only fall
+	// back to the summary when the item carries no content. Mixing both would break the signature.
+	var thinking strings.Builder
+	item.Get("content").ForEach(func(_, part gjson.Result) bool {
+		if part.Get("type").String() == "reasoning_text" {
+			thinking.WriteString(part.Get("text").String())
+		}
+		return true
+	})
+	if thinking.Len() == 0 {
+		item.Get("summary").ForEach(func(_, part gjson.Result) bool {
+			thinking.WriteString(part.Get("text").String())
+			return true
+		})
+	}
+	block := `{"type":"thinking","thinking":"","signature":""}`
+	block, _ = sjson.Set(block, "thinking", thinking.String())
+	block, _ = sjson.Set(block, "signature", encrypted)
+	return block
+}
+
+// convertResponsesImagePart maps an input_image part to a Claude image block, or "" when it has no URL.
+func convertResponsesImagePart(part gjson.Result) string {
+	url := part.Get("image_url").String()
+	if url == "" {
+		url = part.Get("url").String()
+	}
+	if url == "" {
+		return ""
+	}
+	if !strings.HasPrefix(url, "data:") {
+		contentPart := `{"type":"image","source":{"type":"url","url":""}}`
+		contentPart, _ = sjson.Set(contentPart, "source.url", url)
+		return contentPart
+	}
+	mediaAndData := strings.SplitN(strings.TrimPrefix(url, "data:"), ";base64,", 2)
+	if len(mediaAndData) != 2 || mediaAndData[1] == "" {
+		return ""
+	}
+	mediaType := "application/octet-stream"
+	if mediaAndData[0] != "" {
+		mediaType = mediaAndData[0]
+	}
+	contentPart := `{"type":"image","source":{"type":"base64","media_type":"","data":""}}`
+	contentPart, _ = sjson.Set(contentPart, "source.media_type", mediaType)
+	contentPart, _ = sjson.Set(contentPart, "source.data", mediaAndData[1])
+	return contentPart
+}
+
+// convertResponsesToolOutputParts maps a function_call_output content array to tool_result content.
+func convertResponsesToolOutputParts(output gjson.Result) string {
+	content := "[]"
+	output.ForEach(func(_, part gjson.Result) bool {
+		switch part.Get("type").String() {
+		case "input_text", "output_text":
+			block := `{"type":"text","text":""}`
+			block, _ = sjson.Set(block, "text", part.Get("text").String())
+			content, _ = sjson.SetRaw(content, "-1", block)
+		case "input_image":
+			if block := convertResponsesImagePart(part); block != "" {
+				content, _ = sjson.SetRaw(content, "-1", block)
+			}
+		}
+		return true
+	})
+	return content
+}
+
+// responsesOutputText flattens a function_call_output value to text.
+func responsesOutputText(output gjson.Result) string {
+	if !output.IsArray() {
+		return output.String()
+	}
+	var builder strings.Builder
+	output.ForEach(func(_, part gjson.Result) bool {
+		if text := part.Get("text"); text.Exists() {
+			if builder.Len() > 0 {
+				builder.WriteByte('\n')
+			}
+			builder.WriteString(text.String())
+		}
+		return true
+	})
+	return builder.String()
+}
+
+// sanitizeToolUseID maps a Responses call_id onto the character set Claude accepts for tool_use ids.
+// Valid ids pass through unchanged so call ids round-trip between turns.
+func sanitizeToolUseID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, id)
+}
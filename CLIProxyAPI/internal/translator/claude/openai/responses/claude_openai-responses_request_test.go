@@ -0,0 +1,101 @@
+package responses
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestConvertOpenAIResponsesRequestToClaude_ReasoningAndParallelTools(t *testing.T) {
+	input := `{
+		"model": "claude-sonnet-4-5",
+		"input": [
+			{"type": "message", "role": "user", "content": [{"type": "input_text", "text": "list files"}]},
+			{"type": "reasoning", "id": "rs_1", "summary": [{"type": "summary_text", "text": "Need two tools."}], "encrypted_content": "sig-abc"},
+			{"type": "function_call", "call_id": "toolu_01", "name": "ls", "arguments": "{\"path\":\".\"}"},
+			{"type": "function_call", "call_id": "toolu_02", "name": "pwd", "arguments": "{}"},
+			{"type": "function_call_output", "call_id": "toolu_01", "output": "a.go"},
+			{"type": "function_call_output", "call_id": "toolu_02", "output": [{"type": "input_text", "text": "/src"}]}
+		]
+	}`
+	out := gjson.ParseBytes(ConvertOpenAIResponsesRequestToClaude("claude-sonnet-4-5", []byte(input), false))
+
+	if got := out.Get("messages.#").Int(); got != 3 {
+		t.Fatalf("messages.# = %d, want 3: %s", got, out.Get("messages").Raw)
+	}
+	assistant := out.Get("messages.1")
+	if assistant.Get("role").String() != "assistant" || assistant.Get("content.#").Int() != 3 {
+		t.Fatalf("assistant turn = %s, want thinking plus two tool_use blocks", assistant.Raw)
+	}
+	if got := assistant.Get("content.0.type").String(); got != "thinking" {
+		t.Fatalf("assistant content.0.type = %q, want thinking", got)
+	}
+	if got := assistant.Get("content.0.signature").String(); got != "sig-abc" {
+		t.Fatalf("thinking signature = %q, want sig-abc", got)
+	}
+	if got := assistant.Get("content.0.thinking").String(); got != "Need two tools." {
+		t.Fatalf("thinking text = %q", got)
+	}
+	if got := assistant.Get("content.2.id").String(); got != "toolu_02" {
+		t.Fatalf("second tool_use id = %q, want toolu_02", got)
+	}
+
+	results := out.Get("messages.2")
+	if results.Get("content.#").Int() != 2 {
+		t.Fatalf("tool result turn = %s, want two tool_result blocks", results.Raw)
+	}
+	if got := results.Get("content.0.tool_use_id").String(); got != "toolu_01" {
+		t.Fatalf("tool_result tool_use_id = %q, want toolu_01", got)
+	}
+	if got := results.Get("content.1.content.0.text").String(); got != "/src" {
+		t.Fatalf("array tool output = %s", results.Get("content.1.content").Raw)
+	}
+}
+
+func TestConvertOpenAIResponsesRequestToClaude_OrphanToolOutputBecomesText(t *testing.T) {
+	input := `{
+		"previous_response_id": "resp_1",
+		"input": [{"type": "function_call_output", "call_id": "call_9", "output": "done"}]
+	}`
+	out := gjson.ParseBytes(ConvertOpenAIResponsesRequestToClaude("claude-sonnet-4-5", []byte(input), false))
+
+	if out.Get("previous_response_id").Exists() {
+		t.Fatal("previous_response_id must not be sent to Claude")
+	}
+	msg := out.Get("messages.0")
+	if msg.Get("role").String() != "user" || msg.Get("content").Type != gjson.String {
+		t.Fatalf("orphan output message = %s, want plain user text", msg.Raw)
+	}
+	if got := msg.Get("content").String(); got != "Tool result for call call_9:\ndone" {
+		t.Fatalf("orphan output text = %q", got)
+	}
+}
+
+func TestConvertOpenAIResponsesRequestToClaude_RedactedThinking(t *testing.T) {
+	input := `{"input": [
+		{"type": "message", "role": "user", "content": "hi"},
+		{"type": "reasoning", "summary": [], "encrypted_content": "redacted_thinking:opaque"},
+		{"type": "reasoning", "summary": [{"type": "summary_text", "text": "unsigned"}]},
+		{"type": "message", "role": "assistant", "content": [{"type": "output_text", "text": "hello"}]}
+	]}`
+	out := gjson.ParseBytes(ConvertOpenAIResponsesRequestToClaude("claude-sonnet-4-5", []byte(input), false))
+
+	assistant := out.Get("messages.1.content")
+	if assistant.Get("#").Int() != 2 {
+		t.Fatalf("assistant content = %s, want redacted_thinking and text", assistant.Raw)
+	}
+	if assistant.Get("0.type").String() != "redacted_thinking" || assistant.Get("0.data").String() != "opaque" {
+		t.Fatalf("assistant content.0 = %s", assistant.Get("0").Raw)
+	}
+}
+
+func TestConvertResponsesReasoningItemPrefersReasoningText(t *testing.T) {
+	both := gjson.Parse(`{"type": "reasoning", "encrypted_content": "sig", "summary": [{"type": "summary_text", "text": "Short."}], "content": [{"type": "reasoning_text", "text": "Full reasoning."}]}`)
+	if got := gjson.Get(convertResponsesReasoningItem(both), "thinking").String(); got != "Full reasoning." {
+		t.Fatalf("thinking with summary and content = %q, want reasoning_text only", got)
+	}
+	summaryOnly := gjson.Parse(`{"type": "reasoning", "encrypted_content": "sig", "summary": [{"type": "summary_text", "text": "Short."}]}`)
+	if got := gjson.Get(convertResponsesReasoningItem(summaryOnly), "thinking").String(); got != "Short." {
+		t.Fatalf("thinking with summary only = %q, want summary", got)
+	}
+}
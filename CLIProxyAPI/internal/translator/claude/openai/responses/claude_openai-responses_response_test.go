@@ -0,0 +1,92 @@
+package responses
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+var claudeThinkingToolStream = []string{
+	`data: {"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":12,"output_tokens":1}}}`,
+	`data: {"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":"","signature":""}}`,
+	`data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Check the dir."}}`,
+	`data: {"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig-xyz"}}`,
+	`data: {"type":"content_block_stop","index":0}`,
+	`data: {"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+	`data: {"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Listing."}}`,
+	`data: {"type":"content_block_stop","index":1}`,
+	`data: {"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_01","name":"ls","input":{}}}`,
+	`data: {"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"path\":\".\"}"}}`,
+	`data: {"type":"content_block_stop","index":2}`,
+	`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":30}}`,
+	`data: {"type":"message_stop"}`,
+}
+
+func checkClaudeThinkingToolOutput(t *testing.T, output gjson.Result) {
+	t.Helper()
+	if got := output.Get("#").Int(); got != 3 {
+		t.Fatalf("output.# = %d, want 3: %s", got, output.Raw)
+	}
+	if output.Get("0.type").String() != "reasoning" || output.Get("0.encrypted_content").String() != "sig-xyz" {
+		t.Fatalf("output.0 = %s, want reasoning with signature", output.Get("0").Raw)
+	}
+	if got := output.Get("0.summary.0.text").String(); got != "Check the dir." {
+		t.Fatalf("reasoning summary = %q", got)
+	}
+	if got := output.Get("1.content.0.text").String(); got != "Listing." {
+		t.Fatalf("message text = %q", got)
+	}
+	if output.Get("2.call_id").String() != "toolu_01" || output.Get("2.name").String() != "ls" {
+		t.Fatalf("output.2 = %s, want function_call toolu_01", output.Get("2").Raw)
+	}
+}
+
+func TestConvertClaudeResponseToOpenAIResponses_StreamKeepsSignatureAndOrder(t *testing.T) {
+	var param any
+	var events []string
+	for _, line := range claudeThinkingToolStream {
+		events = append(events, ConvertClaudeResponseToOpenAIResponses(context.Background(), "claude-sonnet-4-5", nil, nil, []byte(line), &param)...)
+	}
+
+	var completed gjson.Result
+	reasoningDone := false
+	for _, event := range events {
+		data := gjson.Parse(event[strings.Index(event, "data: ")+len("data: "):])
+		switch data.Get("type").String() {
+		case "response.output_item.done":
+			if data.Get("item.type").String() == "reasoning" {
+				reasoningDone = data.Get("item.encrypted_content").String() == "sig-xyz"
+			}
+		case "response.completed":
+			completed = data
+		}
+	}
+	if !reasoningDone {
+		t.Fatal("reasoning output_item.done did not carry the thinking signature")
+	}
+	if !completed.Exists() {
+		t.Fatal("response.completed was not emitted")
+	}
+	checkClaudeThinkingToolOutput(t, completed.Get("response.output"))
+	if got := completed.Get("response.usage.output_tokens").Int(); got != 30 {
+		t.Fatalf("usage.output_tokens = %d, want 30", got)
+	}
+}
+
+func TestConvertClaudeResponseToOpenAIResponsesNonStream(t *testing.T) {
+	raw := []byte(strings.Join(claudeThinkingToolStream, "\n"))
+	out := gjson.Parse(ConvertClaudeResponseToOpenAIResponsesNonStream(context.Background(), "claude-sonnet-4-5", []byte(`{"model":"claude-sonnet-4-5"}`), nil, raw, nil))
+
+	if got := out.Get("id").String(); got != "msg_1" {
+		t.Fatalf("id = %q, want msg_1", got)
+	}
+	if got := out.Get("model").String(); got != "claude-sonnet-4-5" {
+		t.Fatalf("model = %q", got)
+	}
+	checkClaudeThinkingToolOutput(t, out.Get("output"))
+	if got := out.Get("usage.total_tokens").Int(); got != 42 {
+		t.Fatalf("usage.total_tokens = %d, want 42", got)
+	}
+}
@@ -0,0 +1,242 @@
+// Package transport maintains shared HTTP transports for upstream requests so that
+// connections, HTTP/2 sessions and TLS session tickets are reused across requests.
+// Transports are keyed by proxy URL and, for credential traffic, by auth ID, so that
+// two credentials never share a connection or TLS session that would let the upstream
+// link them. Proxy credentials are part of the URL and therefore part of the key.
+// Per-credential transports left idle for a while are dropped, so removed credentials and
+// old proxy URLs do not keep theirs until the next config reload.
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	defaultDialTimeout         = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultMaxIdleConnsPerHost = 32
+
+	// keyEnvironment selects a transport honouring HTTP_PROXY/HTTPS_PROXY/NO_PROXY.
+	keyEnvironment = ""
+	// keyDirect selects a transport that never uses a proxy.
+	keyDirect = "direct://"
+
+	// authIdleTTL retires per-credential transports that no request used for this long.
+	authIdleTTL = 10 * time.Minute
+	// sweepInterval bounds how often the pool scans for idle per-credential transports.
+	sweepInterval = time.Minute
+)
+
+// Pool caches one http.Transport per proxy URL and isolation key.
+type Pool struct {
+	mu        sync.Mutex
+	settings  config.TransportConfig
+	entries   map[poolKey]*poolEntry
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// poolKey identifies a cached transport. isolation is empty for shared transports and holds
+// the auth ID for transports used by a single credential.
+type poolKey struct {
+	isolation string
+	proxy     string
+}
+
+type poolEntry struct {
+	transport *http.Transport
+	used      bool
+	lastUsed  time.Time
+}
+
+var defaultPool = NewPool(config.TransportConfig{})
+
+// Default returns the process-wide pool used by executors and management API calls.
+func Default() *Pool { return defaultPool }
+
+// NewPool creates an empty pool using settings for new transports.
+func NewPool(settings config.TransportConfig) *Pool {
+	return &Pool{settings: settings, entries: make(map[poolKey]*poolEntry), now: time.Now}
+}
+
+// Environment returns the shared transport that follows the proxy environment variables,
+// matching http.DefaultTransport.
+func (p *Pool) Environment() *http.Transport {
+	t, _ := p.get(poolKey{proxy: keyEnvironment})
+	return t
+}
+
+// Direct returns the shared transport that bypasses every proxy.
+func (p *Pool) Direct() *http.Transport {
+	t, _ := p.get(poolKey{proxy: keyDirect})
+	return t
+}
+
+// ForProxy returns the shared transport for proxyURL. Supported schemes are socks5, http and https.
+func (p *Pool) ForProxy(proxyURL string) (*http.Transport, error) {
+	proxyURL = strings.TrimSpace(proxyURL)
+	if proxyURL == "" {
+		return nil, fmt.Errorf("transport: proxy URL is empty")
+	}
+	return p.get(poolKey{proxy: proxyURL})
+}
+
+// ForAuth returns the transport reserved for the credential authID. It uses proxyURL, or the
+// proxy environment variables when proxyURL is empty, and shares no connections with other
+// credentials. An empty authID falls back to the shared transports.
+func (p *Pool) ForAuth(authID, proxyURL string) (*http.Transport, error) {
+	authID = strings.TrimSpace(authID)
+	proxyURL = strings.TrimSpace(proxyURL)
+	if authID == "" {
+		if proxyURL == "" {
+			return p.Environment(), nil
+		}
+		return p.ForProxy(proxyURL)
+	}
+	if proxyURL == "" {
+		proxyURL = keyEnvironment
+	}
+	return p.get(poolKey{isolation: authID, proxy: proxyURL})
+}
+
+// Reload applies new settings. When they change every cached transport is dropped; otherwise
+// only transports unused since the previous reload are, which also retires the transports of
+// removed credentials. Dropped transports close their idle
+// connections while in-flight requests finish normally.
+func (p *Pool) Reload(settings config.TransportConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := p.settings != settings
+	p.settings = settings
+	for key, entry := range p.entries {
+		if changed || !entry.used {
+			entry.transport.CloseIdleConnections()
+			delete(p.entries, key)
+			continue
+		}
+		entry.used = false
+	}
+}
+
+// Len reports the number of cached transports.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
+
+func (p *Pool) get(key poolKey) (*http.Transport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if now.Sub(p.lastSweep) >= sweepInterval {
+		p.sweepLocked(now)
+	}
+	if entry, ok := p.entries[key]; ok {
+		entry.used = true
+		entry.lastUsed = now
+		return entry.transport, nil
+	}
+	t, err := newTransport(key.proxy, p.settings)
+	if err != nil {
+		return nil, err
+	}
+	p.entries[key] = &poolEntry{transport: t, used: true, lastUsed: now}
+	return t, nil
+}
+
+// sweepLocked drops the per-credential transports idle for longer than authIdleTTL. Shared
+// transports are kept until a reload.
+func (p *Pool) sweepLocked(now time.Time) {
+	for key, entry := range p.entries {
+		if key.isolation != "" && now.Sub(entry.lastUsed) > authIdleTTL {
+			entry.transport.CloseIdleConnections()
+			delete(p.entries, key)
+		}
+	}
+	p.lastSweep = now
+}
+
+func newTransport(key string, settings config.TransportConfig) (*http.Transport, error) {
+	dialer := &net.Dialer{
+		Timeout:   seconds(settings.DialTimeoutSeconds, defaultDialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+	t := &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !settings.DisableHTTP2,
+		MaxIdleConnsPerHost:   defaultMaxIdleConnsPerHost,
+		MaxConnsPerHost:       settings.MaxConnsPerHost,
+		IdleConnTimeout:       seconds(settings.IdleConnTimeoutSeconds, defaultIdleConnTimeout),
+		TLSHandshakeTimeout:   seconds(settings.TLSHandshakeTimeoutSeconds, defaultTLSHandshakeTimeout),
+		ExpectContinueTimeout: time.Second,
+		TLSClientConfig:       &tls.Config{ClientSessionCache: tls.NewLRUClientSessionCache(0)},
+	}
+	if settings.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = settings.MaxIdleConnsPerHost
+	}
+	if settings.DisableHTTP2 {
+		// A non-nil empty map disables the transport's built-in HTTP/2 upgrade.
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+
+	switch key {
+	case keyEnvironment:
+		t.Proxy = http.ProxyFromEnvironment
+		return t, nil
+	case keyDirect:
+		return t, nil
+	}
+
+	proxyURL, err := url.Parse(key)
+	if err != nil {
+		return nil, fmt.Errorf("transport: parse proxy URL: %w", err)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("transport: proxy URL missing host")
+	}
+	switch proxyURL.Scheme {
+	case "socks5":
+		var proxyAuth *proxy.Auth
+		if proxyURL.User != nil {
+			username := proxyURL.User.Username()
+			password, _ := proxyURL.User.Password()
+			proxyAuth = &proxy.Auth{User: username, Password: password}
+		}
+		socks, errSOCKS5 := proxy.SOCKS5("tcp", proxyURL.Host, proxyAuth, dialer)
+		if errSOCKS5 != nil {
+			return nil, fmt.Errorf("transport: create SOCKS5 dialer: %w", errSOCKS5)
+		}
+		if contextDialer, ok := socks.(proxy.ContextDialer); ok {
+			t.DialContext = contextDialer.DialContext
+		} else {
+			t.DialContext = func(_ context.Context, network, addr string) (net.Conn, error) {
+				return socks.Dial(network, addr)
+			}
+		}
+	case "http", "https":
+		t.Proxy = http.ProxyURL(proxyURL)
+	default:
+		return nil, fmt.Errorf("transport: unsupported proxy scheme: %s", proxyURL.Scheme)
+	}
+	return t, nil
+}
+
+func seconds(value int, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return time.Duration(value) * time.Second
+	}
+	return fallback
+}
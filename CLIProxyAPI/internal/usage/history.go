@@ -0,0 +1,103 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// History dimensions accepted by HistoryQuery.GroupBy.
+const (
+	HistoryGroupAPIKey   = "api-key"
+	HistoryGroupModel    = "model"
+	HistoryGroupProvider = "provider"
+	HistoryGroupAuth     = "auth"
+)
+
+// History bucket sizes accepted by HistoryQuery.Bucket. An empty bucket aggregates the whole range.
+const (
+	HistoryBucketHour = "hour"
+	HistoryBucketDay  = "day"
+)
+
+// HistoryQuery selects persisted usage in the half-open range [From, To). Usage is persisted
+// in hourly buckets, so a range starting mid-hour includes that whole hour.
+type HistoryQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy []string
+	Bucket  string
+}
+
+// Validate reports whether the query names a non-empty range and known dimensions.
+func (q HistoryQuery) Validate() error {
+	if q.From.IsZero() || q.To.IsZero() {
+		return errors.New("from and to are required")
+	}
+	if !q.From.Before(q.To) {
+		return errors.New("from must be before to")
+	}
+	seen := make(map[string]struct{}, len(q.GroupBy))
+	for _, dim := range q.GroupBy {
+		switch dim {
+		case HistoryGroupAPIKey, HistoryGroupModel, HistoryGroupProvider, HistoryGroupAuth:
+		default:
+			return fmt.Errorf("unsupported group-by %q", dim)
+		}
+		if _, dup := seen[dim]; dup {
+			return fmt.Errorf("duplicate group-by %q", dim)
+		}
+		seen[dim] = struct{}{}
+	}
+	switch q.Bucket {
+	case "", HistoryBucketHour, HistoryBucketDay:
+	default:
+		return fmt.Errorf("unsupported bucket %q", q.Bucket)
+	}
+	return nil
+}
+
+// HistoryRow is one aggregated group of persisted usage. Only the dimensions named in the
+// query's GroupBy, and the bucket when one was requested, are set.
+type HistoryRow struct {
+	Bucket          *time.Time `json:"bucket,omitempty"`
+	APIKey          string     `json:"api_key,omitempty"`
+	Model           string     `json:"model,omitempty"`
+	Provider        string     `json:"provider,omitempty"`
+	AuthID          string     `json:"auth_id,omitempty"`
+	TotalRequests   int64      `json:"total_requests"`
+	SuccessCount    int64      `json:"success_count"`
+	FailureCount    int64      `json:"failure_count"`
+	InputTokens     int64      `json:"input_tokens"`
+	OutputTokens    int64      `json:"output_tokens"`
+	ReasoningTokens int64      `json:"reasoning_tokens"`
+	CachedTokens    int64      `json:"cached_tokens"`
+	TotalTokens     int64      `json:"total_tokens"`
+	TotalCostUSD    float64    `json:"total_cost_usd"`
+}
+
+// HistoryStore answers time range queries over usage that outlives the process.
+type HistoryStore interface {
+	QueryUsageHistory(ctx context.Context, query HistoryQuery) ([]HistoryRow, error)
+}
+
+var (
+	historyStoreMu sync.RWMutex
+	historyStore   HistoryStore
+)
+
+// SetHistoryStore installs the persisted usage store used by the management query endpoint.
+func SetHistoryStore(s HistoryStore) {
+	historyStoreMu.Lock()
+	historyStore = s
+	historyStoreMu.Unlock()
+}
+
+// GetHistoryStore returns the persisted usage store, or nil when none is configured.
+func GetHistoryStore() HistoryStore {
+	historyStoreMu.RLock()
+	defer historyStoreMu.RUnlock()
+	return historyStore
+}
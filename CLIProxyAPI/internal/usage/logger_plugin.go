@@ -64,9 +64,12 @@ type RequestStatistics struct {
 	successCount  int64
 	failureCount  int64
 	totalTokens   int64
+	totalCost     float64
 
 	apis map[string]*apiStats
 
+	costByAuth map[string]float64
+
 	requestsByDay  map[string]int64
 	requestsByHour map[int]int64
 	tokensByDay    map[string]int64
@@ -77,6 +80,7 @@ type RequestStatistics struct {
 type apiStats struct {
 	TotalRequests int64
 	TotalTokens   int64
+	TotalCostUSD  float64
 	Models        map[string]*modelStats
 }
 
@@ -84,15 +88,18 @@ type apiStats struct {
 type modelStats struct {
 	TotalRequests int64
 	TotalTokens   int64
+	TotalCostUSD  float64
 	Details       []RequestDetail
 }
 
-// RequestDetail stores the timestamp and token usage for a single request.
+// RequestDetail stores the timestamp, token usage and list-price cost for a single request.
 type RequestDetail struct {
 	Timestamp time.Time  `json:"timestamp"`
 	Source    string     `json:"source"`
 	AuthIndex string     `json:"auth_index"`
+	Provider  string     `json:"provider,omitempty"`
 	Tokens    TokenStats `json:"tokens"`
+	CostUSD   float64    `json:"cost_usd"`
 	Failed    bool       `json:"failed"`
 }
 
@@ -112,8 +119,14 @@ type StatisticsSnapshot struct {
 	FailureCount  int64 `json:"failure_count"`
 	TotalTokens   int64 `json:"total_tokens"`
 
+	// TotalCostUSD is the list-price cost of all requests according to the pricing table.
+	TotalCostUSD float64 `json:"total_cost_usd"`
+
 	APIs map[string]APISnapshot `json:"apis"`
 
+	// CostByAuth maps upstream credential indexes to their list-price cost.
+	CostByAuth map[string]float64 `json:"cost_by_auth"`
+
 	RequestsByDay  map[string]int64 `json:"requests_by_day"`
 	RequestsByHour map[string]int64 `json:"requests_by_hour"`
 	TokensByDay    map[string]int64 `json:"tokens_by_day"`
@@ -124,6 +137,7 @@ type StatisticsSnapshot struct {
 type APISnapshot struct {
 	TotalRequests int64                    `json:"total_requests"`
 	TotalTokens   int64                    `json:"total_tokens"`
+	TotalCostUSD  float64                  `json:"total_cost_usd"`
 	Models        map[string]ModelSnapshot `json:"models"`
 }
 
@@ -131,6 +145,7 @@ type APISnapshot struct {
 type ModelSnapshot struct {
 	TotalRequests int64           `json:"total_requests"`
 	TotalTokens   int64           `json:"total_tokens"`
+	TotalCostUSD  float64         `json:"total_cost_usd"`
 	Details       []RequestDetail `json:"details"`
 }
 
@@ -143,6 +158,7 @@ func GetRequestStatistics() *RequestStatistics { return defaultRequestStatistics
 func NewRequestStatistics() *RequestStatistics {
 	return &RequestStatistics{
 		apis:           make(map[string]*apiStats),
+		costByAuth:     make(map[string]float64),
 		requestsByDay:  make(map[string]int64),
 		requestsByHour: make(map[int]int64),
 		tokensByDay:    make(map[string]int64),
@@ -179,6 +195,7 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	}
 	dayKey := timestamp.Format("2006-01-02")
 	hourKey := timestamp.Hour()
+	cost := Pricing().Cost(record.Provider, modelName, detail)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -200,7 +217,9 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		Timestamp: timestamp,
 		Source:    record.Source,
 		AuthIndex: record.AuthIndex,
+		Provider:  record.Provider,
 		Tokens:    detail,
+		CostUSD:   cost,
 		Failed:    failed,
 	})
 
@@ -213,6 +232,7 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 func (s *RequestStatistics) updateAPIStats(stats *apiStats, model string, detail RequestDetail) {
 	stats.TotalRequests++
 	stats.TotalTokens += detail.Tokens.TotalTokens
+	stats.TotalCostUSD += detail.CostUSD
 	modelStatsValue, ok := stats.Models[model]
 	if !ok {
 		modelStatsValue = &modelStats{}
@@ -220,6 +240,14 @@ func (s *RequestStatistics) updateAPIStats(stats *apiStats, model string, detail
 	}
 	modelStatsValue.TotalRequests++
 	modelStatsValue.TotalTokens += detail.Tokens.TotalTokens
+	modelStatsValue.TotalCostUSD += detail.CostUSD
+
+	s.totalCost += detail.CostUSD
+	authKey := detail.AuthIndex
+	if authKey == "" {
+		authKey = "unknown"
+	}
+	s.costByAuth[authKey] += detail.CostUSD
 	modelStatsValue.Details = append(modelStatsValue.Details, detail)
 }
 
@@ -237,12 +265,14 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 	result.SuccessCount = s.successCount
 	result.FailureCount = s.failureCount
 	result.TotalTokens = s.totalTokens
+	result.TotalCostUSD = s.totalCost
 
 	result.APIs = make(map[string]APISnapshot, len(s.apis))
 	for apiName, stats := range s.apis {
 		apiSnapshot := APISnapshot{
 			TotalRequests: stats.TotalRequests,
 			TotalTokens:   stats.TotalTokens,
+			TotalCostUSD:  stats.TotalCostUSD,
 			Models:        make(map[string]ModelSnapshot, len(stats.Models)),
 		}
 		for modelName, modelStatsValue := range stats.Models {
@@ -251,12 +281,18 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 			apiSnapshot.Models[modelName] = ModelSnapshot{
 				TotalRequests: modelStatsValue.TotalRequests,
 				TotalTokens:   modelStatsValue.TotalTokens,
+				TotalCostUSD:  modelStatsValue.TotalCostUSD,
 				Details:       requestDetails,
 			}
 		}
 		result.APIs[apiName] = apiSnapshot
 	}
 
+	result.CostByAuth = make(map[string]float64, len(s.costByAuth))
+	for k, v := range s.costByAuth {
+		result.CostByAuth[k] = v
+	}
+
 	result.RequestsByDay = make(map[string]int64, len(s.requestsByDay))
 	for k, v := range s.requestsByDay {
 		result.RequestsByDay[k] = v
@@ -332,6 +368,11 @@ func (s *RequestStatistics) MergeSnapshot(snapshot StatisticsSnapshot) MergeResu
 			}
 			for _, detail := range modelSnapshot.Details {
 				detail.Tokens = normaliseTokenStats(detail.Tokens)
+				if detail.CostUSD == 0 && (detail.Provider != "" || providerIndependent(detail.Tokens)) {
+					// Snapshots exported before cost accounting carry no cost; price them now unless
+					// the price depends on a provider the snapshot does not record.
+					detail.CostUSD = Pricing().Cost(detail.Provider, modelName, detail.Tokens)
+				}
 				if detail.Timestamp.IsZero() {
 					detail.Timestamp = time.Now()
 				}
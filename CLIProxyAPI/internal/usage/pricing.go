@@ -0,0 +1,174 @@
+package usage
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+// defaultModelPrices lists public list prices in USD per million tokens for the models in the
+// registry. Entries in the pricing config override or extend them.
+var defaultModelPrices = []config.ModelPrice{
+	{Model: "gpt-5", Input: 1.25, CachedInput: 0.125, Output: 10},
+	{Model: "gpt-5-codex", Input: 1.25, CachedInput: 0.125, Output: 10},
+	{Model: "gpt-5-codex-mini", Input: 0.25, CachedInput: 0.025, Output: 2},
+	{Model: "gpt-5.1", Input: 1.25, CachedInput: 0.125, Output: 10},
+	{Model: "gpt-5.1-codex", Input: 1.25, CachedInput: 0.125, Output: 10},
+	{Model: "gpt-5.1-codex-max", Input: 1.25, CachedInput: 0.125, Output: 10},
+	{Model: "gpt-5.1-codex-mini", Input: 0.25, CachedInput: 0.025, Output: 2},
+	{Model: "gpt-5.2", Input: 1.75, CachedInput: 0.175, Output: 14},
+	{Model: "gpt-5.2-codex", Input: 1.75, CachedInput: 0.175, Output: 14},
+
+	{Model: "claude-opus-4-5*", Input: 5, CachedInput: 0.5, Output: 25},
+	{Model: "claude-opus-4*", Input: 15, CachedInput: 1.5, Output: 75},
+	{Model: "claude-sonnet-4*", Input: 3, CachedInput: 0.3, Output: 15},
+	{Model: "claude-3-7-sonnet*", Input: 3, CachedInput: 0.3, Output: 15},
+	{Model: "claude-haiku-4-5*", Input: 1, CachedInput: 0.1, Output: 5},
+	{Model: "claude-3-5-haiku*", Input: 0.8, CachedInput: 0.08, Output: 4},
+
+	{Model: "gemini-3-pro*", Input: 2, CachedInput: 0.2, Output: 12},
+	{Model: "gemini-3-flash*", Input: 0.5, CachedInput: 0.05, Output: 3},
+	{Model: "gemini-2.5-pro*", Input: 1.25, CachedInput: 0.125, Output: 10},
+	{Model: "gemini-pro-latest", Input: 1.25, CachedInput: 0.125, Output: 10},
+	{Model: "gemini-2.5-flash*", Input: 0.3, CachedInput: 0.03, Output: 2.5},
+	{Model: "gemini-flash-latest", Input: 0.3, CachedInput: 0.03, Output: 2.5},
+	{Model: "gemini-2.5-flash-lite*", Input: 0.1, CachedInput: 0.01, Output: 0.4},
+	{Model: "gemini-flash-lite-latest", Input: 0.1, CachedInput: 0.01, Output: 0.4},
+}
+
+// PricingTable resolves model list prices. Exact names win over wildcard patterns, and longer
+// patterns win over shorter ones.
+type PricingTable struct {
+	exact    map[string]config.ModelPrice
+	patterns []config.ModelPrice
+}
+
+// NewPricingTable builds a table from the built-in prices and the given overrides.
+func NewPricingTable(overrides []config.ModelPrice) *PricingTable {
+	merged := make(map[string]config.ModelPrice, len(defaultModelPrices)+len(overrides))
+	for _, list := range [][]config.ModelPrice{defaultModelPrices, overrides} {
+		for _, price := range list {
+			name := strings.ToLower(strings.TrimSpace(price.Model))
+			if name == "" {
+				continue
+			}
+			price.Model = name
+			merged[name] = price
+		}
+	}
+	table := &PricingTable{exact: make(map[string]config.ModelPrice, len(merged))}
+	for name, price := range merged {
+		if strings.Contains(name, "*") {
+			table.patterns = append(table.patterns, price)
+		} else {
+			table.exact[name] = price
+		}
+	}
+	sort.Slice(table.patterns, func(i, j int) bool {
+		if len(table.patterns[i].Model) != len(table.patterns[j].Model) {
+			return len(table.patterns[i].Model) > len(table.patterns[j].Model)
+		}
+		return table.patterns[i].Model < table.patterns[j].Model
+	})
+	return table
+}
+
+// Lookup returns the price of model. Thinking suffixes such as "(high)" and path prefixes such
+// as "models/" are ignored.
+func (t *PricingTable) Lookup(model string) (config.ModelPrice, bool) {
+	if t == nil {
+		return config.ModelPrice{}, false
+	}
+	name, _ := util.NormalizeThinkingModel(strings.ToLower(strings.TrimSpace(model)))
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if name == "" {
+		return config.ModelPrice{}, false
+	}
+	if price, ok := t.exact[name]; ok {
+		return price, true
+	}
+	for _, price := range t.patterns {
+		if util.MatchWildcard(price.Model, name) {
+			return price, true
+		}
+	}
+	return config.ModelPrice{}, false
+}
+
+// Cost returns the list price in USD of a request, or zero when the model has no price.
+//
+// Upstreams count tokens differently: Claude reports cache reads separately from input tokens,
+// while the OpenAI and Gemini formats include them in the input count; the Gemini family reports
+// reasoning tokens separately from output tokens, while the OpenAI format includes them.
+func (t *PricingTable) Cost(provider, model string, tokens TokenStats) float64 {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return 0
+	}
+	cachedPrice := price.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = price.Input
+	}
+	reasoningPrice := price.Reasoning
+	if reasoningPrice == 0 {
+		reasoningPrice = price.Output
+	}
+
+	input := tokens.InputTokens
+	if !cachedReportedSeparately(provider) {
+		input -= tokens.CachedTokens
+	}
+	output := tokens.OutputTokens
+	if !reasoningReportedSeparately(provider) {
+		output -= tokens.ReasoningTokens
+	}
+	if input < 0 {
+		input = 0
+	}
+	if output < 0 {
+		output = 0
+	}
+
+	cost := float64(input)*price.Input +
+		float64(tokens.CachedTokens)*cachedPrice +
+		float64(output)*price.Output +
+		float64(tokens.ReasoningTokens)*reasoningPrice
+	return cost / 1_000_000
+}
+
+func cachedReportedSeparately(provider string) bool {
+	return strings.EqualFold(provider, "claude")
+}
+
+// providerIndependent reports whether tokens cost the same whichever upstream format reported
+// them, which holds when they include no cached or reasoning tokens.
+func providerIndependent(tokens TokenStats) bool {
+	return tokens.CachedTokens == 0 && tokens.ReasoningTokens == 0
+}
+
+func reasoningReportedSeparately(provider string) bool {
+	switch strings.ToLower(provider) {
+	case "gemini", "gemini-cli", "vertex", "aistudio", "antigravity":
+		return true
+	}
+	return false
+}
+
+var pricing atomic.Pointer[PricingTable]
+
+func init() {
+	pricing.Store(NewPricingTable(nil))
+}
+
+// SetPricing replaces the shared pricing table with the built-in prices plus overrides.
+func SetPricing(overrides []config.ModelPrice) {
+	pricing.Store(NewPricingTable(overrides))
+}
+
+// Pricing returns the shared pricing table.
+func Pricing() *PricingTable { return pricing.Load() }
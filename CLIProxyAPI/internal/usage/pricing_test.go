@@ -0,0 +1,110 @@
+package usage
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func approxEqual(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestPricingTableLookup(t *testing.T) {
+	table := NewPricingTable([]config.ModelPrice{
+		{Model: "gpt-5", Input: 2, Output: 20},
+		{Model: "internal-*", Input: 1, Output: 1},
+	})
+	cases := map[string]float64{
+		"gpt-5":                    2,    // override wins over the built-in price
+		"gpt-5.1(high)":            1.25, // thinking suffix is ignored
+		"claude-opus-4-5-20251101": 5,    // longest pattern wins
+		"claude-opus-4-1-20250805": 15,
+		"models/gemini-2.5-pro":    1.25,
+		"gemini-2.5-flash-lite":    0.1,
+		"internal-llama":           1,
+	}
+	for model, want := range cases {
+		price, ok := table.Lookup(model)
+		if !ok || price.Input != want {
+			t.Errorf("Lookup(%q) = %+v, %v; want input %v", model, price, ok, want)
+		}
+	}
+	if _, ok := table.Lookup("unknown-model"); ok {
+		t.Error("Lookup(unknown-model) found a price")
+	}
+}
+
+func TestPricingTableCost(t *testing.T) {
+	table := NewPricingTable([]config.ModelPrice{{Model: "m", Input: 1, CachedInput: 0.1, Output: 10, Reasoning: 5}})
+	tokens := TokenStats{InputTokens: 1_000_000, CachedTokens: 400_000, OutputTokens: 300_000, ReasoningTokens: 100_000}
+
+	// OpenAI format: cached tokens are part of input and reasoning tokens part of output.
+	if got, want := table.Cost("codex", "m", tokens), 0.6+0.04+2.0+0.5; !approxEqual(got, want) {
+		t.Errorf("openai cost = %v, want %v", got, want)
+	}
+	// Claude reports cache reads next to the input tokens.
+	if got, want := table.Cost("claude", "m", tokens), 1.0+0.04+2.0+0.5; !approxEqual(got, want) {
+		t.Errorf("claude cost = %v, want %v", got, want)
+	}
+	// Gemini reports reasoning tokens next to the output tokens.
+	if got, want := table.Cost("gemini", "m", tokens), 0.6+0.04+3.0+0.5; !approxEqual(got, want) {
+		t.Errorf("gemini cost = %v, want %v", got, want)
+	}
+	if got := table.Cost("codex", "unpriced", tokens); got != 0 {
+		t.Errorf("unpriced cost = %v, want 0", got)
+	}
+}
+
+func TestRequestStatisticsCost(t *testing.T) {
+	SetPricing([]config.ModelPrice{{Model: "m", Input: 1, Output: 2}})
+	t.Cleanup(func() { SetPricing(nil) })
+
+	stats := NewRequestStatistics()
+	record := func(apiKey, authIndex string, input, output int64) {
+		stats.Record(context.Background(), coreusage.Record{
+			Provider:  "codex",
+			Model:     "m",
+			APIKey:    apiKey,
+			AuthIndex: authIndex,
+			Detail:    coreusage.Detail{InputTokens: input, OutputTokens: output},
+		})
+	}
+	record("team-a", "1", 1_000_000, 500_000)
+	record("team-a", "2", 500_000, 0)
+	record("team-b", "1", 0, 1_000_000)
+
+	snapshot := stats.Snapshot()
+	if !approxEqual(snapshot.TotalCostUSD, 4.5) {
+		t.Fatalf("total cost = %v, want 4.5", snapshot.TotalCostUSD)
+	}
+	if got := snapshot.APIs["team-a"]; !approxEqual(got.TotalCostUSD, 2.5) || !approxEqual(got.Models["m"].TotalCostUSD, 2.5) {
+		t.Fatalf("team-a cost = %+v", got)
+	}
+	if !approxEqual(snapshot.CostByAuth["1"], 4) || !approxEqual(snapshot.CostByAuth["2"], 0.5) {
+		t.Fatalf("cost by auth = %v", snapshot.CostByAuth)
+	}
+
+	// Details exported without a cost are priced on import.
+	legacy := StatisticsSnapshot{APIs: map[string]APISnapshot{"team-c": {Models: map[string]ModelSnapshot{
+		"m": {Details: []RequestDetail{{Tokens: TokenStats{InputTokens: 1_000_000}}}},
+	}}}}
+	stats.MergeSnapshot(legacy)
+	if got := stats.Snapshot().APIs["team-c"].TotalCostUSD; !approxEqual(got, 1) {
+		t.Fatalf("imported cost = %v, want 1", got)
+	}
+
+	// Cached tokens price differently per provider, so they are only priced when it is known.
+	cached := StatisticsSnapshot{APIs: map[string]APISnapshot{"team-d": {Models: map[string]ModelSnapshot{
+		"m": {Details: []RequestDetail{
+			{Timestamp: time.Unix(1, 0), Tokens: TokenStats{InputTokens: 1_000_000, CachedTokens: 1_000_000}},
+			{Timestamp: time.Unix(2, 0), Provider: "claude", Tokens: TokenStats{InputTokens: 1_000_000, CachedTokens: 1_000_000}},
+		}},
+	}}}}
+	stats.MergeSnapshot(cached)
+	if got := stats.Snapshot().APIs["team-d"].TotalCostUSD; !approxEqual(got, 2) {
+		t.Fatalf("imported cached cost = %v, want 2 from the claude detail only", got)
+	}
+}
@@ -33,6 +33,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.UsageStatisticsEnabled != newCfg.UsageStatisticsEnabled {
 		changes = append(changes, fmt.Sprintf("usage-statistics-enabled: %t -> %t", oldCfg.UsageStatisticsEnabled, newCfg.UsageStatisticsEnabled))
 	}
+	if !reflect.DeepEqual(oldCfg.Pricing, newCfg.Pricing) {
+		changes = append(changes, fmt.Sprintf("pricing: updated (%d -> %d entries)", len(oldCfg.Pricing), len(newCfg.Pricing)))
+	}
 	if oldCfg.DisableCooling != newCfg.DisableCooling {
 		changes = append(changes, fmt.Sprintf("disable-cooling: %t -> %t", oldCfg.DisableCooling, newCfg.DisableCooling))
 	}
@@ -87,6 +90,18 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
 				changes = append(changes, fmt.Sprintf("gemini[%d].api-key: updated", i))
 			}
+			if o.Priority != n.Priority {
+				changes = append(changes, fmt.Sprintf("gemini[%d].priority: %d -> %d", i, o.Priority, n.Priority))
+			}
+			if o.Weight != n.Weight {
+				changes = append(changes, fmt.Sprintf("gemini[%d].weight: %d -> %d", i, o.Weight, n.Weight))
+			}
+			if o.MaxConcurrency != n.MaxConcurrency {
+				changes = append(changes, fmt.Sprintf("gemini[%d].max-concurrency: %d -> %d", i, o.MaxConcurrency, n.MaxConcurrency))
+			}
+			if !equalStringSet(o.Schedule, n.Schedule) {
+				changes = append(changes, fmt.Sprintf("gemini[%d].schedule: updated", i))
+			}
 			if !equalStringMap(o.Headers, n.Headers) {
 				changes = append(changes, fmt.Sprintf("gemini[%d].headers: updated", i))
 			}
@@ -122,6 +137,18 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
 				changes = append(changes, fmt.Sprintf("claude[%d].api-key: updated", i))
 			}
+			if o.Priority != n.Priority {
+				changes = append(changes, fmt.Sprintf("claude[%d].priority: %d -> %d", i, o.Priority, n.Priority))
+			}
+			if o.Weight != n.Weight {
+				changes = append(changes, fmt.Sprintf("claude[%d].weight: %d -> %d", i, o.Weight, n.Weight))
+			}
+			if o.MaxConcurrency != n.MaxConcurrency {
+				changes = append(changes, fmt.Sprintf("claude[%d].max-concurrency: %d -> %d", i, o.MaxConcurrency, n.MaxConcurrency))
+			}
+			if !equalStringSet(o.Schedule, n.Schedule) {
+				changes = append(changes, fmt.Sprintf("claude[%d].schedule: updated", i))
+			}
 			if !equalStringMap(o.Headers, n.Headers) {
 				changes = append(changes, fmt.Sprintf("claude[%d].headers: updated", i))
 			}
@@ -157,6 +184,18 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
 				changes = append(changes, fmt.Sprintf("codex[%d].api-key: updated", i))
 			}
+			if o.Priority != n.Priority {
+				changes = append(changes, fmt.Sprintf("codex[%d].priority: %d -> %d", i, o.Priority, n.Priority))
+			}
+			if o.Weight != n.Weight {
+				changes = append(changes, fmt.Sprintf("codex[%d].weight: %d -> %d", i, o.Weight, n.Weight))
+			}
+			if o.MaxConcurrency != n.MaxConcurrency {
+				changes = append(changes, fmt.Sprintf("codex[%d].max-concurrency: %d -> %d", i, o.MaxConcurrency, n.MaxConcurrency))
+			}
+			if !equalStringSet(o.Schedule, n.Schedule) {
+				changes = append(changes, fmt.Sprintf("codex[%d].schedule: updated", i))
+			}
 			if !equalStringMap(o.Headers, n.Headers) {
 				changes = append(changes, fmt.Sprintf("codex[%d].headers: updated", i))
 			}
@@ -206,6 +245,10 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 		changes = append(changes, fmt.Sprintf("ampcode.upstream-api-keys: updated (%d -> %d entries)", oldUpstreamAPIKeysCount, newUpstreamAPIKeysCount))
 	}
 
+	if !reflect.DeepEqual(oldCfg.ModelFallbacks, newCfg.ModelFallbacks) {
+		changes = append(changes, fmt.Sprintf("model-fallbacks: updated (%d -> %d entries)", len(oldCfg.ModelFallbacks), len(newCfg.ModelFallbacks)))
+	}
+
 	if entries, _ := DiffOAuthExcludedModelChanges(oldCfg.OAuthExcludedModels, newCfg.OAuthExcludedModels); len(entries) > 0 {
 		changes = append(changes, entries...)
 	}
@@ -263,6 +306,18 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
 				changes = append(changes, fmt.Sprintf("vertex[%d].api-key: updated", i))
 			}
+			if o.Priority != n.Priority {
+				changes = append(changes, fmt.Sprintf("vertex[%d].priority: %d -> %d", i, o.Priority, n.Priority))
+			}
+			if o.Weight != n.Weight {
+				changes = append(changes, fmt.Sprintf("vertex[%d].weight: %d -> %d", i, o.Weight, n.Weight))
+			}
+			if o.MaxConcurrency != n.MaxConcurrency {
+				changes = append(changes, fmt.Sprintf("vertex[%d].max-concurrency: %d -> %d", i, o.MaxConcurrency, n.MaxConcurrency))
+			}
+			if !equalStringSet(o.Schedule, n.Schedule) {
+				changes = append(changes, fmt.Sprintf("vertex[%d].schedule: updated", i))
+			}
 			oldModels := SummarizeVertexModels(o.Models)
 			newModels := SummarizeVertexModels(n.Models)
 			if oldModels.hash != newModels.hash {
@@ -175,6 +175,26 @@ func TestBuildConfigChangeDetails_ModelPrefixes(t *testing.T) {
 	expectContains(t, changes, "vertex[0].prefix: old-v -> new-v")
 }
 
+func TestBuildConfigChangeDetails_RoutingTiers(t *testing.T) {
+	oldCfg := &config.Config{
+		ClaudeKey: []config.ClaudeKey{{APIKey: "c1"}},
+		OpenAICompatibility: []config.OpenAICompatibility{
+			{Name: "router", APIKeyEntries: []config.OpenAICompatibilityAPIKey{{APIKey: "k1"}}},
+		},
+	}
+	newCfg := &config.Config{
+		ClaudeKey: []config.ClaudeKey{{APIKey: "c1", Priority: -10, Weight: 2}},
+		OpenAICompatibility: []config.OpenAICompatibility{
+			{Name: "router", APIKeyEntries: []config.OpenAICompatibilityAPIKey{{APIKey: "k1", Priority: 1}}},
+		},
+	}
+
+	changes := BuildConfigChangeDetails(oldCfg, newCfg)
+	expectContains(t, changes, "claude[0].priority: 0 -> -10")
+	expectContains(t, changes, "claude[0].weight: 0 -> 2")
+	expectContains(t, changes, "  provider updated: router (api-key routing updated)")
+}
+
 func TestBuildConfigChangeDetails_NilSafe(t *testing.T) {
 	if details := BuildConfigChangeDetails(nil, &config.Config{}); len(details) != 0 {
 		t.Fatalf("expected empty change list when old nil, got %v", details)
@@ -75,6 +75,9 @@ func describeOpenAICompatibilityUpdate(oldEntry, newEntry config.OpenAICompatibi
 	if !equalStringMap(oldEntry.Headers, newEntry.Headers) {
 		details = append(details, "headers updated")
 	}
+	if !equalAPIKeyRouting(oldEntry.APIKeyEntries, newEntry.APIKeyEntries) {
+		details = append(details, "api-key routing updated")
+	}
 	if len(details) == 0 {
 		return ""
 	}
@@ -91,6 +94,20 @@ func countAPIKeys(entry config.OpenAICompatibility) int {
 	return count
 }
 
+// equalAPIKeyRouting reports whether two key lists carry the same priority, weight, concurrency and schedule settings.
+// Lists of different length are treated as equal because the key count change is reported separately.
+func equalAPIKeyRouting(a, b []config.OpenAICompatibilityAPIKey) bool {
+	if len(a) != len(b) {
+		return true
+	}
+	for i := range a {
+		if a[i].Priority != b[i].Priority || a[i].Weight != b[i].Weight || a[i].MaxConcurrency != b[i].MaxConcurrency || !equalStringSet(a[i].Schedule, b[i].Schedule) {
+			return false
+		}
+	}
+	return true
+}
+
 func countOpenAIModels(models []config.OpenAICompatibilityModel) int {
 	count := 0
 	for _, model := range models {
@@ -66,6 +66,8 @@ func (s *ConfigSynthesizer) synthesizeGeminiKeys(ctx *SynthesisContext) []*corea
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(entry.Headers, attrs)
+		addRoutingAttrs(entry.Priority, entry.Weight, entry.MaxConcurrency, attrs)
+		addScheduleAttrs(entry.Schedule, attrs)
 		a := &coreauth.Auth{
 			ID:         id,
 			Provider:   "gemini",
@@ -110,6 +112,8 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(ck.Headers, attrs)
+		addRoutingAttrs(ck.Priority, ck.Weight, ck.MaxConcurrency, attrs)
+		addScheduleAttrs(ck.Schedule, attrs)
 		proxyURL := strings.TrimSpace(ck.ProxyURL)
 		a := &coreauth.Auth{
 			ID:         id,
@@ -154,6 +158,8 @@ func (s *ConfigSynthesizer) synthesizeCodexKeys(ctx *SynthesisContext) []*coreau
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(ck.Headers, attrs)
+		addRoutingAttrs(ck.Priority, ck.Weight, ck.MaxConcurrency, attrs)
+		addScheduleAttrs(ck.Schedule, attrs)
 		proxyURL := strings.TrimSpace(ck.ProxyURL)
 		a := &coreauth.Auth{
 			ID:         id,
@@ -209,6 +215,8 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 				attrs["models_hash"] = hash
 			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
+			addRoutingAttrs(entry.Priority, entry.Weight, entry.MaxConcurrency, attrs)
+			addScheduleAttrs(entry.Schedule, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
 				Provider:   providerName,
@@ -282,6 +290,8 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 			attrs["models_hash"] = hash
 		}
 		addConfigHeadersToAttrs(compat.Headers, attrs)
+		addRoutingAttrs(compat.Priority, compat.Weight, compat.MaxConcurrency, attrs)
+		addScheduleAttrs(compat.Schedule, attrs)
 		a := &coreauth.Auth{
 			ID:         id,
 			Provider:   providerName,
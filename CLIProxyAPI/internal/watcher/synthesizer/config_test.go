@@ -225,6 +225,53 @@ func TestConfigSynthesizer_ClaudeKeys_SkipsEmptyAndHeaders(t *testing.T) {
 	}
 }
 
+func TestConfigSynthesizer_RoutingAttributes(t *testing.T) {
+	synth := NewConfigSynthesizer()
+	ctx := &SynthesisContext{
+		Config: &config.Config{
+			ClaudeKey: []config.ClaudeKey{
+				{APIKey: "paid-key", Priority: -10, Weight: 3, MaxConcurrency: 4, Schedule: []string{"weekdays 18:00-08:00", " ", "weekends"}},
+				{APIKey: "default-key"},
+			},
+			OpenAICompatibility: []config.OpenAICompatibility{
+				{
+					Name:          "router",
+					BaseURL:       "https://router.example.com/v1",
+					APIKeyEntries: []config.OpenAICompatibilityAPIKey{{APIKey: "compat-key", Priority: 5}},
+				},
+			},
+		},
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	}
+
+	auths, err := synth.Synthesize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auths) != 3 {
+		t.Fatalf("expected 3 auths, got %d", len(auths))
+	}
+	if auths[0].Priority() != -10 || auths[0].Weight() != 3 {
+		t.Errorf("expected priority -10 weight 3, got priority %d weight %d", auths[0].Priority(), auths[0].Weight())
+	}
+	if auths[0].MaxConcurrency() != 4 || auths[1].MaxConcurrency() != 0 {
+		t.Errorf("expected max concurrency 4 and 0, got %d and %d", auths[0].MaxConcurrency(), auths[1].MaxConcurrency())
+	}
+	if auths[0].Attributes["schedule"] != "weekdays 18:00-08:00; weekends" || auths[1].ScheduleRules() != nil {
+		t.Errorf("expected schedule attribute on paid key only, got %q", auths[0].Attributes["schedule"])
+	}
+	if _, ok := auths[1].Attributes["priority"]; ok {
+		t.Error("expected no priority attribute for default key")
+	}
+	if auths[1].Priority() != 0 || auths[1].Weight() != 1 {
+		t.Errorf("expected default priority 0 weight 1, got priority %d weight %d", auths[1].Priority(), auths[1].Weight())
+	}
+	if auths[2].Attributes["priority"] != "5" {
+		t.Errorf("expected compat priority 5, got %q", auths[2].Attributes["priority"])
+	}
+}
+
 func TestConfigSynthesizer_CodexKeys(t *testing.T) {
 	synth := NewConfigSynthesizer()
 	ctx := &SynthesisContext{
@@ -66,6 +66,10 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 		if email, _ := metadata["email"].(string); email != "" {
 			label = email
 		}
+		if custom, _ := metadata[coreauth.MetadataLabelKey].(string); strings.TrimSpace(custom) != "" {
+			label = strings.TrimSpace(custom)
+		}
+		disabled, _ := metadata[coreauth.MetadataDisabledKey].(bool)
 		// Use relative path under authDir as ID to stay consistent with the file-based token store
 		id := full
 		if rel, errRel := filepath.Rel(ctx.AuthDir, full); errRel == nil && rel != "" {
@@ -101,10 +105,16 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 			CreatedAt: now,
 			UpdatedAt: now,
 		}
+		if disabled {
+			disableByOperator(a)
+		}
 		ApplyAuthExcludedModelsMeta(a, cfg, nil, "oauth")
 		if provider == "gemini-cli" {
 			if virtuals := SynthesizeGeminiVirtualAuths(a, metadata, now); len(virtuals) > 0 {
 				for _, v := range virtuals {
+					if disabled {
+						disableByOperator(v)
+					}
 					ApplyAuthExcludedModelsMeta(v, cfg, nil, "oauth")
 				}
 				out = append(out, a)
@@ -117,6 +127,13 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 	return out, nil
 }
 
+// disableByOperator marks an auth whose file carries the operator "disabled" flag.
+func disableByOperator(a *coreauth.Auth) {
+	a.Disabled = true
+	a.Status = coreauth.StatusDisabled
+	a.StatusMessage = "disabled via management API"
+}
+
 // SynthesizeGeminiVirtualAuths creates virtual Auth entries for multi-project Gemini credentials.
 // It disables the primary auth and creates one virtual auth per project.
 func SynthesizeGeminiVirtualAuths(primary *coreauth.Auth, metadata map[string]any, now time.Time) []*coreauth.Auth {
@@ -289,6 +289,36 @@ func TestFileSynthesizer_Synthesize_PrefixValidation(t *testing.T) {
 	}
 }
 
+func TestFileSynthesizer_Synthesize_OperatorOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	data, _ := json.Marshal(map[string]any{
+		"type":     "claude",
+		"email":    "user@example.com",
+		"label":    "team-a",
+		"disabled": true,
+	})
+	_ = os.WriteFile(filepath.Join(tempDir, "auth.json"), data, 0644)
+
+	auths, err := NewFileSynthesizer().Synthesize(&SynthesisContext{
+		Config:      &config.Config{},
+		AuthDir:     tempDir,
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auths) != 1 {
+		t.Fatalf("expected 1 auth, got %d", len(auths))
+	}
+	if auths[0].Label != "team-a" {
+		t.Errorf("expected label %q, got %q", "team-a", auths[0].Label)
+	}
+	if !auths[0].Disabled || auths[0].Status != coreauth.StatusDisabled {
+		t.Errorf("expected disabled auth, got disabled=%v status=%q", auths[0].Disabled, auths[0].Status)
+	}
+}
+
 func TestSynthesizeGeminiVirtualAuths_NilInputs(t *testing.T) {
 	now := time.Now()
 
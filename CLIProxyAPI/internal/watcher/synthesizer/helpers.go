@@ -5,11 +5,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
 )
 
 // StableIDGenerator generates stable, deterministic IDs for auth entries.
@@ -108,3 +110,41 @@ func addConfigHeadersToAttrs(headers map[string]string, attrs map[string]string)
 		attrs["header:"+key] = val
 	}
 }
+
+// addRoutingAttrs records the configured priority tier, selection weight and concurrency cap in auth
+// attributes. Zero values are omitted so credentials without routing hints keep the defaults.
+func addRoutingAttrs(priority, weight, maxConcurrency int, attrs map[string]string) {
+	if attrs == nil {
+		return
+	}
+	if priority != 0 {
+		attrs["priority"] = strconv.Itoa(priority)
+	}
+	if weight > 0 {
+		attrs["weight"] = strconv.Itoa(weight)
+	}
+	if maxConcurrency > 0 {
+		attrs["max_concurrency"] = strconv.Itoa(maxConcurrency)
+	}
+}
+
+// addScheduleAttrs records the configured active windows in auth attributes as ';' separated rules.
+// Invalid rules are recorded as well so the credential fails closed and stays out of rotation.
+func addScheduleAttrs(schedule []string, attrs map[string]string) {
+	if attrs == nil {
+		return
+	}
+	rules := make([]string, 0, len(schedule))
+	for _, rule := range schedule {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return
+	}
+	if _, err := coreauth.ParseSchedule(rules); err != nil {
+		log.Errorf("invalid schedule %q, the credential stays out of rotation: %v", rules, err)
+	}
+	attrs["schedule"] = strings.Join(rules, "; ")
+}
@@ -9,4 +9,10 @@ var (
 	ErrInvalidCredential = errors.New("access: invalid credential")
 	// ErrNotHandled tells the manager to continue trying other providers.
 	ErrNotHandled = errors.New("access: not handled")
+	// ErrCredentialExpired signals that the credential matched but is past its expiry.
+	ErrCredentialExpired = errors.New("access: credential expired")
+	// ErrRateLimited signals that the credential exceeded its requests-per-minute limit.
+	ErrRateLimited = errors.New("access: rate limit exceeded")
+	// ErrQuotaExceeded signals that the credential used up its daily token budget.
+	ErrQuotaExceeded = errors.New("access: token quota exceeded")
 )
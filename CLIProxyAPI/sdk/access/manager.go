@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"sync"
+	"time"
 )
 
 // Manager coordinates authentication providers.
@@ -63,6 +64,9 @@ func (m *Manager) Authenticate(ctx context.Context, r *http.Request) (*Result, e
 		}
 		res, err := provider.Authenticate(ctx, r)
 		if err == nil {
+			if errPolicy := enforcePolicy(res); errPolicy != nil {
+				return nil, errPolicy
+			}
 			return res, nil
 		}
 		if errors.Is(err, ErrNotHandled) {
@@ -87,3 +91,14 @@ func (m *Manager) Authenticate(ctx context.Context, r *http.Request) (*Result, e
 	}
 	return nil, ErrNoCredentials
 }
+
+// enforcePolicy applies the expiry and request-rate limits of the result's policy.
+func enforcePolicy(res *Result) error {
+	if res == nil || res.Policy == nil {
+		return nil
+	}
+	if res.Policy.Expired(time.Now()) {
+		return ErrCredentialExpired
+	}
+	return DefaultQuota().AllowRequest(res.Principal, res.Policy)
+}
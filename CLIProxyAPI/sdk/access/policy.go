@@ -0,0 +1,214 @@
+package access
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// Policy restricts what an authenticated client may do. A nil Policy allows everything.
+type Policy struct {
+	// Name is the display name of the client key.
+	Name string
+	// AllowedModels lists model globs ('*' matches any substring). Empty allows all models.
+	AllowedModels []string
+	// AllowedProviders lists provider identifiers. Empty allows all providers.
+	AllowedProviders []string
+	// ModelPrefix is forced onto every requested model.
+	ModelPrefix string
+	// RequestsPerMinute caps the request rate. Zero means unlimited.
+	RequestsPerMinute int
+	// TokensPerDay caps token usage per UTC day. Zero means unlimited.
+	TokensPerDay int64
+	// ExpiresAt is the time the credential stops working. Zero never expires.
+	ExpiresAt time.Time
+}
+
+// PolicyFromClientKey converts a configured client key into its policy.
+func PolicyFromClientKey(key *config.ClientAPIKey) *Policy {
+	if key == nil {
+		return nil
+	}
+	return &Policy{
+		Name:              strings.TrimSpace(key.Name),
+		AllowedModels:     append([]string(nil), key.AllowedModels...),
+		AllowedProviders:  append([]string(nil), key.AllowedProviders...),
+		ModelPrefix:       strings.Trim(strings.TrimSpace(key.ModelPrefix), "/"),
+		RequestsPerMinute: key.RequestsPerMinute,
+		TokensPerDay:      key.TokensPerDay,
+		ExpiresAt:         key.ExpiresAt,
+	}
+}
+
+// Expired reports whether the policy's credential is past its expiry at now.
+func (p *Policy) Expired(now time.Time) bool {
+	return p != nil && !p.ExpiresAt.IsZero() && !now.Before(p.ExpiresAt)
+}
+
+// ApplyModelPrefix returns model with the forced prefix applied.
+func (p *Policy) ApplyModelPrefix(model string) string {
+	if p == nil || p.ModelPrefix == "" || model == "" {
+		return model
+	}
+	if strings.HasPrefix(model, p.ModelPrefix+"/") {
+		return model
+	}
+	return p.ModelPrefix + "/" + model
+}
+
+// AllowsModel reports whether model matches the allowlist. A forced prefix on model is ignored
+// so allowlists are written against client-facing model names.
+func (p *Policy) AllowsModel(model string) bool {
+	if p == nil || len(p.AllowedModels) == 0 {
+		return true
+	}
+	if p.ModelPrefix != "" {
+		model = strings.TrimPrefix(model, p.ModelPrefix+"/")
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, pattern := range p.AllowedModels {
+		if util.MatchWildcard(strings.ToLower(strings.TrimSpace(pattern)), model) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsProvider reports whether provider is on the allowlist.
+func (p *Policy) AllowsProvider(provider string) bool {
+	if p == nil || len(p.AllowedProviders) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedProviders {
+		if strings.EqualFold(strings.TrimSpace(allowed), provider) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterProviders returns the providers the policy allows, preserving order.
+func (p *Policy) FilterProviders(providers []string) []string {
+	if p == nil || len(p.AllowedProviders) == 0 {
+		return providers
+	}
+	out := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if p.AllowsProvider(provider) {
+			out = append(out, provider)
+		}
+	}
+	return out
+}
+
+// QuotaTracker counts requests per minute and tokens per UTC day for each principal.
+type QuotaTracker struct {
+	mu        sync.Mutex
+	windows   map[string]*quotaWindow
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type quotaWindow struct {
+	minute   time.Time
+	requests int
+	day      time.Time
+	tokens   int64
+}
+
+var defaultQuota = NewQuotaTracker()
+
+// DefaultQuota returns the process-wide tracker used by the access manager and API handlers.
+func DefaultQuota() *QuotaTracker { return defaultQuota }
+
+// NewQuotaTracker creates an empty tracker.
+func NewQuotaTracker() *QuotaTracker {
+	return &QuotaTracker{windows: make(map[string]*quotaWindow), now: time.Now}
+}
+
+// AllowRequest counts one request for principal and returns ErrRateLimited when the policy's
+// requests-per-minute limit is already reached.
+func (q *QuotaTracker) AllowRequest(principal string, policy *Policy) error {
+	if q == nil || policy == nil || policy.RequestsPerMinute <= 0 {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	w := q.windowLocked(principal)
+	if w.requests >= policy.RequestsPerMinute {
+		return ErrRateLimited
+	}
+	w.requests++
+	return nil
+}
+
+// CheckTokens returns ErrQuotaExceeded when principal has used its daily token budget.
+func (q *QuotaTracker) CheckTokens(principal string, policy *Policy) error {
+	if q == nil || policy == nil || policy.TokensPerDay <= 0 {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.windowLocked(principal).tokens >= policy.TokensPerDay {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// AddTokens charges tokens to principal for the current UTC day.
+func (q *QuotaTracker) AddTokens(principal string, tokens int64) {
+	if q == nil || principal == "" || tokens <= 0 {
+		return
+	}
+	q.mu.Lock()
+	q.windowLocked(principal).tokens += tokens
+	q.mu.Unlock()
+}
+
+// TokensUsed returns the tokens principal consumed during the current UTC day.
+func (q *QuotaTracker) TokensUsed(principal string) int64 {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.windowLocked(principal).tokens
+}
+
+func (q *QuotaTracker) windowLocked(principal string) *quotaWindow {
+	now := q.now().UTC()
+	minute := now.Truncate(time.Minute)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if minute.Sub(q.lastSweep) >= quotaSweepInterval {
+		q.sweepLocked(minute, day)
+	}
+	w, ok := q.windows[principal]
+	if !ok {
+		w = &quotaWindow{minute: minute, day: day}
+		q.windows[principal] = w
+	}
+	if !w.minute.Equal(minute) {
+		w.minute, w.requests = minute, 0
+	}
+	if !w.day.Equal(day) {
+		w.day, w.tokens = day, 0
+	}
+	return w
+}
+
+// quotaSweepInterval is how often windows of idle principals are dropped.
+const quotaSweepInterval = time.Minute
+
+// sweepLocked drops windows whose minute and day have both passed. They hold no usage that is
+// still counted, so principals that stopped sending requests do not stay in memory.
+func (q *QuotaTracker) sweepLocked(minute, day time.Time) {
+	q.lastSweep = minute
+	for principal, w := range q.windows {
+		if !w.minute.Equal(minute) && !w.day.Equal(day) {
+			delete(q.windows, principal)
+		}
+	}
+}
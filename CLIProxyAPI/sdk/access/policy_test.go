@@ -0,0 +1,98 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPolicyAllowsModelAndProvider(t *testing.T) {
+	p := &Policy{
+		AllowedModels:    []string{"claude-sonnet-*", "GEMINI-2.5-PRO"},
+		AllowedProviders: []string{"claude", "gemini"},
+		ModelPrefix:      "teamA",
+	}
+	cases := map[string]bool{
+		"claude-sonnet-4-5":       true,
+		"teamA/claude-sonnet-4-5": true,
+		"gemini-2.5-pro":          true,
+		"claude-opus-4-1":         false,
+		"gemini-2.5-flash":        false,
+	}
+	for model, want := range cases {
+		if got := p.AllowsModel(model); got != want {
+			t.Errorf("AllowsModel(%q) = %v, want %v", model, got, want)
+		}
+	}
+	if got := p.FilterProviders([]string{"codex", "Claude"}); len(got) != 1 || got[0] != "Claude" {
+		t.Fatalf("FilterProviders() = %v", got)
+	}
+	if got := p.ApplyModelPrefix("claude-sonnet-4-5"); got != "teamA/claude-sonnet-4-5" {
+		t.Fatalf("ApplyModelPrefix() = %q", got)
+	}
+	if got := p.ApplyModelPrefix("teamA/claude-sonnet-4-5"); got != "teamA/claude-sonnet-4-5" {
+		t.Fatalf("ApplyModelPrefix() re-prefixed: %q", got)
+	}
+
+	var unrestricted *Policy
+	if !unrestricted.AllowsModel("anything") || !unrestricted.AllowsProvider("any") {
+		t.Fatal("nil policy must allow everything")
+	}
+}
+
+func TestQuotaTrackerWindows(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 59, 30, 0, time.UTC)
+	q := NewQuotaTracker()
+	q.now = func() time.Time { return now }
+	p := &Policy{RequestsPerMinute: 2, TokensPerDay: 100}
+
+	for i := 0; i < 2; i++ {
+		if err := q.AllowRequest("k", p); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if err := q.AllowRequest("k", p); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("third request error = %v, want ErrRateLimited", err)
+	}
+	if err := q.AllowRequest("other", p); err != nil {
+		t.Fatalf("other principal limited: %v", err)
+	}
+
+	q.AddTokens("k", 100)
+	if err := q.CheckTokens("k", p); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("CheckTokens() = %v, want ErrQuotaExceeded", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := q.AllowRequest("k", p); err != nil {
+		t.Fatalf("request after minute rollover: %v", err)
+	}
+	if err := q.CheckTokens("k", p); err != nil {
+		t.Fatalf("tokens after day rollover: %v", err)
+	}
+	if _, ok := q.windows["other"]; ok || len(q.windows) != 1 {
+		t.Fatalf("windows = %d, want the idle principal pruned", len(q.windows))
+	}
+}
+
+type staticProvider struct{ result *Result }
+
+func (p staticProvider) Identifier() string { return "static" }
+
+func (p staticProvider) Authenticate(context.Context, *http.Request) (*Result, error) {
+	return p.result, nil
+}
+
+func TestManagerRejectsExpiredCredential(t *testing.T) {
+	m := NewManager()
+	m.SetProviders([]Provider{staticProvider{result: &Result{
+		Principal: "expired-key",
+		Policy:    &Policy{ExpiresAt: time.Now().Add(-time.Hour)},
+	}}})
+	req, _ := http.NewRequest(http.MethodGet, "/v1/models", nil)
+	if _, err := m.Authenticate(context.Background(), req); !errors.Is(err, ErrCredentialExpired) {
+		t.Fatalf("Authenticate() error = %v, want ErrCredentialExpired", err)
+	}
+}
@@ -20,6 +20,8 @@ type Result struct {
 	Provider  string
 	Principal string
 	Metadata  map[string]string
+	// Policy restricts the authenticated client; nil allows everything.
+	Policy *Policy
 }
 
 // ProviderFactory builds a provider from configuration data.
@@ -75,7 +77,7 @@ func BuildProviders(root *config.SDKConfig) ([]Provider, error) {
 		providers = append(providers, provider)
 	}
 	if len(providers) == 0 {
-		if inline := config.MakeInlineAPIKeyProvider(root.APIKeys); inline != nil {
+		if inline := config.MakeInlineAPIKeyProvider(root.InlineAPIKeys()); inline != nil {
 			provider, err := BuildProvider(inline, root)
 			if err != nil {
 				return nil, err
@@ -83,5 +85,12 @@ func BuildProviders(root *config.SDKConfig) ([]Provider, error) {
 			providers = append(providers, provider)
 		}
 	}
+	for _, builtin := range root.BuiltinAccessProviders() {
+		provider, err := BuildProvider(builtin, root)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
 	return providers, nil
 }
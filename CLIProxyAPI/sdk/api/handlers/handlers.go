@@ -6,6 +6,7 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -16,10 +17,12 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
@@ -128,16 +131,47 @@ func StreamingBootstrapRetries(cfg *config.SDKConfig) int {
 func requestExecutionMetadata(ctx context.Context) map[string]any {
 	// Idempotency-Key is an optional client-supplied header used to correlate retries.
 	// It is forwarded as execution metadata; when absent we generate a UUID.
+	// X-Session-ID is forwarded as well so the auth manager can keep a session on one credential.
 	key := ""
+	sessionID := ""
 	if ctx != nil {
 		if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil && ginCtx.Request != nil {
 			key = strings.TrimSpace(ginCtx.GetHeader("Idempotency-Key"))
+			sessionID = strings.TrimSpace(ginCtx.GetHeader("X-Session-ID"))
 		}
 	}
 	if key == "" {
 		key = uuid.NewString()
 	}
-	return map[string]any{idempotencyKeyMetadataKey: key}
+	meta := map[string]any{idempotencyKeyMetadataKey: key}
+	if sessionID != "" {
+		meta[coreauth.SessionIDMetadataKey] = sessionID
+	}
+	if ctx != nil {
+		if index, ok := ctx.Value(pinnedAuthIndexContextKey{}).(string); ok && index != "" {
+			meta[coreauth.PinnedAuthIndexMetadataKey] = index
+		}
+	}
+	return meta
+}
+
+type pinnedAuthIndexContextKey struct{}
+
+// WithPinnedAuthIndex returns a context that restricts execution to the auth with the given index.
+func WithPinnedAuthIndex(ctx context.Context, authIndex string) context.Context {
+	authIndex = strings.TrimSpace(authIndex)
+	if authIndex == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, pinnedAuthIndexContextKey{}, authIndex)
+}
+
+type noModelFallbackContextKey struct{}
+
+// WithoutModelFallback returns a context that executes only the requested model, skipping the
+// configured model fallback chain.
+func WithoutModelFallback(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noModelFallbackContextKey{}, true)
 }
 
 func mergeMetadata(base, overlay map[string]any) map[string]any {
@@ -316,42 +350,80 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 }
 
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
-// This path is the only supported execution route.
+// This path is the only supported execution route. When every credential for the model is
+// unavailable, the configured model fallback chain is tried in order.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	policy, errMsg := clientPolicy(ctx)
 	if errMsg != nil {
 		return nil, errMsg
 	}
 	reqMeta := requestExecutionMetadata(ctx)
-	req := coreexecutor.Request{
-		Model:   normalizedModel,
-		Payload: cloneBytes(rawJSON),
+	chain := h.modelChain(ctx, modelName)
+	var lastErr *interfaces.ErrorMessage
+	for i, model := range chain {
+		providers, req, opts, errMsg := h.prepareExecution(handlerType, model, rawJSON, alt, false, reqMeta, policy)
+		if errMsg != nil {
+			if i == 0 {
+				return nil, errMsg
+			}
+			log.Debugf("model fallback %s skipped: %v", model, errMsg.Error)
+			continue
+		}
+		resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
+		if err == nil {
+			return cloneBytes(resp.Payload), nil
+		}
+		lastErr = errorMessageFromError(err)
+		if i < len(chain)-1 && modelFallbackEligible(err) {
+			log.Infof("model %s unavailable (status %d), falling back to %s", model, lastErr.StatusCode, chain[i+1])
+			continue
+		}
+		return nil, lastErr
 	}
-	if cloned := cloneMetadata(metadata); cloned != nil {
-		req.Metadata = cloned
+	return nil, lastErr
+}
+
+// chatOnlyProviders lists the built-in providers whose executors have no embeddings endpoint.
+// Gemini API keys, Vertex and OpenAI-compatible upstreams implement the embeddings action.
+var chatOnlyProviders = map[string]struct{}{
+	"aistudio":    {},
+	"antigravity": {},
+	"claude":      {},
+	"codex":       {},
+	"gemini-cli":  {},
+	"iflow":       {},
+	"qwen":        {},
+}
+
+// ExecuteEmbeddingsWithAuthManager executes an OpenAI embeddings request via the core auth manager.
+// The request carries the "embeddings" action so executors call the provider embedding endpoint
+// and return an OpenAI embeddings response. The model fallback chain is not used: vectors from
+// another model live in a different space and cannot stand in for the requested ones.
+func (h *BaseAPIHandler) ExecuteEmbeddingsWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	policy, errMsg := clientPolicy(ctx)
+	if errMsg != nil {
+		return nil, errMsg
 	}
-	opts := coreexecutor.Options{
-		Stream:          false,
-		Alt:             alt,
-		OriginalRequest: cloneBytes(rawJSON),
-		SourceFormat:    sdktranslator.FromString(handlerType),
+	providers, req, opts, errMsg := h.prepareExecution(handlerType, modelName, rawJSON, "", false, requestExecutionMetadata(ctx), policy)
+	if errMsg != nil {
+		return nil, errMsg
 	}
-	opts.Metadata = mergeMetadata(cloneMetadata(metadata), reqMeta)
-	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
-	if err != nil {
-		status := http.StatusInternalServerError
-		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
-			if code := se.StatusCode(); code > 0 {
-				status = code
-			}
+	embeddingProviders := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		if _, chatOnly := chatOnlyProviders[provider]; !chatOnly {
+			embeddingProviders = append(embeddingProviders, provider)
 		}
-		var addon http.Header
-		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
-			if hdr := he.Headers(); hdr != nil {
-				addon = hdr.Clone()
-			}
-		}
-		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+	if len(embeddingProviders) == 0 {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("model %s is not served by a provider that supports embeddings", modelName)}
+	}
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]any, 1)
+	}
+	req.Metadata["action"] = "embeddings"
+	resp, err := h.AuthManager.Execute(ctx, embeddingProviders, req, opts)
+	if err != nil {
+		return nil, errorMessageFromError(err)
 	}
 	return cloneBytes(resp.Payload), nil
 }
@@ -359,7 +431,11 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	policy, errMsg := clientPolicy(ctx)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	providers, normalizedModel, metadata, errMsg := h.resolveModel(modelName, policy)
 	if errMsg != nil {
 		return nil, errMsg
 	}
@@ -380,27 +456,16 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 	opts.Metadata = mergeMetadata(cloneMetadata(metadata), reqMeta)
 	resp, err := h.AuthManager.ExecuteCount(ctx, providers, req, opts)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
-			if code := se.StatusCode(); code > 0 {
-				status = code
-			}
-		}
-		var addon http.Header
-		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
-			if hdr := he.Headers(); hdr != nil {
-				addon = hdr.Clone()
-			}
-		}
-		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+		return nil, errorMessageFromError(err)
 	}
 	return cloneBytes(resp.Payload), nil
 }
 
 // ExecuteStreamWithAuthManager executes a streaming request via the core auth manager.
-// This path is the only supported execution route.
+// This path is the only supported execution route. Until the first payload byte is sent,
+// failures may move the request along the configured model fallback chain.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	policy, errMsg := clientPolicy(ctx)
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
@@ -408,36 +473,45 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		return nil, errChan
 	}
 	reqMeta := requestExecutionMetadata(ctx)
-	req := coreexecutor.Request{
-		Model:   normalizedModel,
-		Payload: cloneBytes(rawJSON),
-	}
-	if cloned := cloneMetadata(metadata); cloned != nil {
-		req.Metadata = cloned
-	}
-	opts := coreexecutor.Options{
-		Stream:          true,
-		Alt:             alt,
-		OriginalRequest: cloneBytes(rawJSON),
-		SourceFormat:    sdktranslator.FromString(handlerType),
-	}
-	opts.Metadata = mergeMetadata(cloneMetadata(metadata), reqMeta)
-	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
-	if err != nil {
-		errChan := make(chan *interfaces.ErrorMessage, 1)
-		status := http.StatusInternalServerError
-		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
-			if code := se.StatusCode(); code > 0 {
-				status = code
+	chain := h.modelChain(ctx, modelName)
+
+	var (
+		providers []string
+		req       coreexecutor.Request
+		opts      coreexecutor.Options
+		chunks    <-chan coreexecutor.StreamChunk
+	)
+	// startFrom opens a stream on the first model in chain[index:] that accepts the request.
+	// lastErr is reported when no remaining model can be tried.
+	startFrom := func(index int, lastErr *interfaces.ErrorMessage) (int, *interfaces.ErrorMessage) {
+		for i := index; i < len(chain); i++ {
+			nextProviders, nextReq, nextOpts, errMsg := h.prepareExecution(handlerType, chain[i], rawJSON, alt, true, reqMeta, policy)
+			if errMsg != nil {
+				if i == 0 {
+					return i, errMsg
+				}
+				log.Debugf("model fallback %s skipped: %v", chain[i], errMsg.Error)
+				continue
 			}
-		}
-		var addon http.Header
-		if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
-			if hdr := he.Headers(); hdr != nil {
-				addon = hdr.Clone()
+			nextChunks, err := h.AuthManager.ExecuteStream(ctx, nextProviders, nextReq, nextOpts)
+			if err == nil {
+				providers, req, opts, chunks = nextProviders, nextReq, nextOpts, nextChunks
+				return i, nil
 			}
+			lastErr = errorMessageFromError(err)
+			if i < len(chain)-1 && modelFallbackEligible(err) {
+				log.Infof("model %s unavailable (status %d), falling back to %s", chain[i], lastErr.StatusCode, chain[i+1])
+				continue
+			}
+			return i, lastErr
 		}
-		errChan <- &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+		return len(chain), lastErr
+	}
+
+	current, errMsg := startFrom(0, nil)
+	if errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
 		close(errChan)
 		return nil, errChan
 	}
@@ -495,26 +569,33 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 							}
 							streamErr = retryErr
 						}
-					}
-
-					status := http.StatusInternalServerError
-					if se, ok := streamErr.(interface{ StatusCode() int }); ok && se != nil {
-						if code := se.StatusCode(); code > 0 {
-							status = code
-						}
-					}
-					var addon http.Header
-					if he, ok := streamErr.(interface{ Headers() http.Header }); ok && he != nil {
-						if hdr := he.Headers(); hdr != nil {
-							addon = hdr.Clone()
+						if current < len(chain)-1 && modelFallbackEligible(streamErr) {
+							log.Infof("model %s unavailable (status %d), falling back to %s", chain[current], statusFromError(streamErr), chain[current+1])
+							next, fallbackErr := startFrom(current+1, errorMessageFromError(streamErr))
+							if fallbackErr == nil {
+								current = next
+								bootstrapRetries = 0
+								continue outer
+							}
+							errChan <- fallbackErr
+							return
 						}
 					}
-					errChan <- &interfaces.ErrorMessage{StatusCode: status, Error: streamErr, Addon: addon}
+
+					errChan <- errorMessageFromError(streamErr)
 					return
 				}
 				if len(chunk.Payload) > 0 {
 					sentPayload = true
-					dataChan <- cloneBytes(chunk.Payload)
+					if ctx == nil {
+						dataChan <- cloneBytes(chunk.Payload)
+						continue
+					}
+					select {
+					case dataChan <- cloneBytes(chunk.Payload):
+					case <-ctx.Done():
+						return
+					}
 				}
 			}
 		}
@@ -522,6 +603,141 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	return dataChan, errChan
 }
 
+// modelChain returns the requested model followed by its configured fallbacks, unless ctx
+// disables model fallback.
+func (h *BaseAPIHandler) modelChain(ctx context.Context, modelName string) []string {
+	chain := []string{modelName}
+	if disabled, _ := ctx.Value(noModelFallbackContextKey{}).(bool); disabled {
+		return chain
+	}
+	if h.Cfg != nil {
+		chain = append(chain, h.Cfg.ModelFallbackChain(modelName)...)
+	}
+	return chain
+}
+
+// prepareExecution resolves providers for model and builds the executor request and options.
+func (h *BaseAPIHandler) prepareExecution(handlerType, model string, rawJSON []byte, alt string, stream bool, reqMeta map[string]any, policy *sdkaccess.Policy) ([]string, coreexecutor.Request, coreexecutor.Options, *interfaces.ErrorMessage) {
+	providers, normalizedModel, metadata, errMsg := h.resolveModel(model, policy)
+	if errMsg != nil {
+		return nil, coreexecutor.Request{}, coreexecutor.Options{}, errMsg
+	}
+	req := coreexecutor.Request{
+		Model:   normalizedModel,
+		Payload: cloneBytes(rawJSON),
+	}
+	if cloned := cloneMetadata(metadata); cloned != nil {
+		req.Metadata = cloned
+	}
+	opts := coreexecutor.Options{
+		Stream:          stream,
+		Alt:             alt,
+		OriginalRequest: cloneBytes(rawJSON),
+		SourceFormat:    sdktranslator.FromString(handlerType),
+	}
+	opts.Metadata = mergeMetadata(cloneMetadata(metadata), reqMeta)
+	return providers, req, opts, nil
+}
+
+// resolveModel resolves providers for model and applies the client policy: the forced model
+// prefix, the model allowlist and the provider allowlist.
+func (h *BaseAPIHandler) resolveModel(model string, policy *sdkaccess.Policy) ([]string, string, map[string]any, *interfaces.ErrorMessage) {
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(policy.ApplyModelPrefix(model))
+	if errMsg != nil {
+		return nil, "", nil, errMsg
+	}
+	if !policy.AllowsModel(normalizedModel) {
+		return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("model %s is not allowed for this API key", model)}
+	}
+	if providers = policy.FilterProviders(providers); len(providers) == 0 {
+		return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("no provider allowed for this API key serves model %s", model)}
+	}
+	return providers, normalizedModel, metadata, nil
+}
+
+// clientPolicy returns the access policy of the authenticated client and rejects the request
+// once the client has used up its daily token budget.
+func clientPolicy(ctx context.Context) (*sdkaccess.Policy, *interfaces.ErrorMessage) {
+	var ginCtx *gin.Context
+	if ctx != nil {
+		ginCtx, _ = ctx.Value("gin").(*gin.Context)
+	}
+	policy := ClientPolicy(ginCtx)
+	if policy == nil {
+		return nil, nil
+	}
+	if err := sdkaccess.DefaultQuota().CheckTokens(ginCtx.GetString("apiKey"), policy); err != nil {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusTooManyRequests, Error: err}
+	}
+	return policy, nil
+}
+
+// ClientPolicy returns the access policy attached to the request by the auth middleware, or nil.
+func ClientPolicy(c *gin.Context) *sdkaccess.Policy {
+	if c == nil {
+		return nil
+	}
+	if value, exists := c.Get("accessPolicy"); exists {
+		if policy, ok := value.(*sdkaccess.Policy); ok {
+			return policy
+		}
+	}
+	return nil
+}
+
+// FilterModelsForClient drops models the client's policy does not allow. idKey names the
+// model identifier field ("id" for OpenAI/Claude listings, "name" for Gemini).
+func FilterModelsForClient(c *gin.Context, models []map[string]any, idKey string) []map[string]any {
+	policy := ClientPolicy(c)
+	if policy == nil {
+		return models
+	}
+	filtered := make([]map[string]any, 0, len(models))
+	for _, model := range models {
+		id, _ := model[idKey].(string)
+		id = strings.TrimPrefix(id, "models/")
+		if id == "" {
+			continue
+		}
+		if policy.ModelPrefix != "" && !strings.HasPrefix(id, policy.ModelPrefix+"/") {
+			continue
+		}
+		if !policy.AllowsModel(id) || len(policy.FilterProviders(util.GetProviderName(id))) == 0 {
+			continue
+		}
+		filtered = append(filtered, model)
+	}
+	return filtered
+}
+
+// modelFallbackEligible reports whether err means the model itself is unavailable:
+// all credentials cooling down or missing, or an upstream server error. A canceled request
+// is never retried on another model since nobody is waiting for the answer.
+func modelFallbackEligible(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	status := statusFromError(err)
+	return status == 0 || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// errorMessageFromError wraps an execution error with its HTTP status and response headers.
+func errorMessageFromError(err error) *interfaces.ErrorMessage {
+	status := http.StatusInternalServerError
+	if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
+		if code := se.StatusCode(); code > 0 {
+			status = code
+		}
+	}
+	var addon http.Header
+	if he, ok := err.(interface{ Headers() http.Header }); ok && he != nil {
+		if hdr := he.Headers(); hdr != nil {
+			addon = hdr.Clone()
+		}
+	}
+	return &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+}
+
 func statusFromError(err error) int {
 	if err == nil {
 		return 0
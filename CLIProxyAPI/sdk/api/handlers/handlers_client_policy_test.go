@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+)
+
+func policyContext(policy *sdkaccess.Policy, apiKey string) context.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Set("apiKey", apiKey)
+	c.Set("accessPolicy", policy)
+	return context.WithValue(context.Background(), "gin", c)
+}
+
+func TestExecuteWithAuthManager_EnforcesClientPolicy(t *testing.T) {
+	handler, primary, secondary := newModelFallbackHandler(t, 0)
+
+	ctx := policyContext(&sdkaccess.Policy{AllowedProviders: []string{"gemini"}}, "policy-provider-key")
+	_, errMsg := handler.ExecuteWithAuthManager(ctx, "claude", "fallback-primary-model", []byte(`{}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("error = %+v, want 403 for disallowed provider", errMsg)
+	}
+
+	ctx = policyContext(&sdkaccess.Policy{AllowedModels: []string{"*-secondary-*"}}, "policy-model-key")
+	if _, errMsg = handler.ExecuteWithAuthManager(ctx, "claude", "fallback-primary-model", []byte(`{}`), ""); errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("error = %+v, want 403 for disallowed model", errMsg)
+	}
+	payload, errMsg := handler.ExecuteWithAuthManager(ctx, "gemini", "fallback-secondary-model", []byte(`{}`), "")
+	if errMsg != nil || string(payload) != "gemini:fallback-secondary-model" {
+		t.Fatalf("allowed model = %q, %+v", payload, errMsg)
+	}
+	if got := primary.Models(); len(got) != 0 {
+		t.Fatalf("disallowed requests reached upstream: %v", got)
+	}
+
+	budget := &sdkaccess.Policy{TokensPerDay: 10}
+	sdkaccess.DefaultQuota().AddTokens("policy-budget-key", 10)
+	_, errMsg = handler.ExecuteWithAuthManager(policyContext(budget, "policy-budget-key"), "gemini", "fallback-secondary-model", []byte(`{}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("error = %+v, want 429 once the token budget is spent", errMsg)
+	}
+	if got := secondary.Models(); len(got) != 1 {
+		t.Fatalf("secondary attempts = %v, want 1", got)
+	}
+}
+
+func TestFilterModelsForClient(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	models := []map[string]any{{"id": "fallback-primary-model"}, {"id": "fallback-secondary-model"}}
+	if got := FilterModelsForClient(c, models, "id"); len(got) != 2 {
+		t.Fatalf("unrestricted client sees %d models, want 2", len(got))
+	}
+
+	newModelFallbackHandler(t, 0) // registers the test models with their providers
+	c.Set("accessPolicy", &sdkaccess.Policy{AllowedProviders: []string{"claude"}})
+	got := FilterModelsForClient(c, models, "id")
+	if len(got) != 1 || got[0]["id"] != "fallback-primary-model" {
+		t.Fatalf("filtered models = %v", got)
+	}
+}
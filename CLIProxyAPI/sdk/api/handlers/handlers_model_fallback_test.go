@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// modelFallbackExecutor fails every request with a fixed status, or succeeds when status is zero.
+type modelFallbackExecutor struct {
+	provider string
+	status   int
+
+	mu     sync.Mutex
+	models []string
+}
+
+func (e *modelFallbackExecutor) Identifier() string { return e.provider }
+
+func (e *modelFallbackExecutor) record(model string) {
+	e.mu.Lock()
+	e.models = append(e.models, model)
+	e.mu.Unlock()
+}
+
+func (e *modelFallbackExecutor) Models() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.models...)
+}
+
+func (e *modelFallbackExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	e.record(req.Model)
+	if e.status != 0 {
+		return coreexecutor.Response{}, &coreauth.Error{Code: "upstream", Message: "upstream failed", HTTPStatus: e.status}
+	}
+	return coreexecutor.Response{Payload: []byte(e.provider + ":" + req.Model)}, nil
+}
+
+func (e *modelFallbackExecutor) ExecuteStream(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	e.record(req.Model)
+	ch := make(chan coreexecutor.StreamChunk, 1)
+	if e.status != 0 {
+		ch <- coreexecutor.StreamChunk{Err: &coreauth.Error{Code: "upstream", Message: "upstream failed", HTTPStatus: e.status}}
+	} else {
+		ch <- coreexecutor.StreamChunk{Payload: []byte(e.provider + ":" + req.Model)}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (e *modelFallbackExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *modelFallbackExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *modelFallbackExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented"}
+}
+
+func newModelFallbackHandler(t *testing.T, primaryStatus int) (*BaseAPIHandler, *modelFallbackExecutor, *modelFallbackExecutor) {
+	t.Helper()
+	primary := &modelFallbackExecutor{provider: "claude", status: primaryStatus}
+	secondary := &modelFallbackExecutor{provider: "gemini"}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(primary)
+	manager.RegisterExecutor(secondary)
+
+	auths := []*coreauth.Auth{
+		{ID: "fallback-claude", Provider: "claude", Status: coreauth.StatusActive},
+		{ID: "fallback-gemini", Provider: "gemini", Status: coreauth.StatusActive},
+	}
+	models := map[string]string{"fallback-claude": "fallback-primary-model", "fallback-gemini": "fallback-secondary-model"}
+	for _, auth := range auths {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("manager.Register(%s): %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: models[auth.ID]}})
+	}
+	t.Cleanup(func() {
+		for _, auth := range auths {
+			registry.GetGlobalRegistry().UnregisterClient(auth.ID)
+		}
+	})
+
+	cfg := &sdkconfig.SDKConfig{
+		ModelFallbacks: []sdkconfig.ModelFallback{
+			{Model: "fallback-primary-model", Fallbacks: []string{"unknown-model", "fallback-secondary-model"}},
+		},
+	}
+	return NewBaseAPIHandlers(cfg, manager), primary, secondary
+}
+
+func TestExecuteWithAuthManager_FallsBackToNextModel(t *testing.T) {
+	handler, primary, secondary := newModelFallbackHandler(t, http.StatusServiceUnavailable)
+
+	payload, errMsg := handler.ExecuteWithAuthManager(context.Background(), "claude", "fallback-primary-model", []byte(`{"model":"fallback-primary-model"}`), "")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %+v", errMsg)
+	}
+	if string(payload) != "gemini:fallback-secondary-model" {
+		t.Fatalf("payload = %q, want fallback response", string(payload))
+	}
+	if got := primary.Models(); len(got) != 1 {
+		t.Fatalf("primary attempts = %v, want 1", got)
+	}
+	if got := secondary.Models(); len(got) != 1 || got[0] != "fallback-secondary-model" {
+		t.Fatalf("secondary attempts = %v, want [fallback-secondary-model]", got)
+	}
+}
+
+func TestExecuteWithAuthManager_DoesNotFallBackOnClientError(t *testing.T) {
+	handler, _, secondary := newModelFallbackHandler(t, http.StatusBadRequest)
+
+	_, errMsg := handler.ExecuteWithAuthManager(context.Background(), "claude", "fallback-primary-model", []byte(`{}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("error = %+v, want 400 from primary model", errMsg)
+	}
+	if got := secondary.Models(); len(got) != 0 {
+		t.Fatalf("secondary attempts = %v, want none", got)
+	}
+}
+
+func TestExecuteStreamWithAuthManager_FallsBackBeforeFirstByte(t *testing.T) {
+	handler, _, secondary := newModelFallbackHandler(t, http.StatusInternalServerError)
+
+	dataChan, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "claude", "fallback-primary-model", []byte(`{}`), "")
+	var got []byte
+	for chunk := range dataChan {
+		got = append(got, chunk...)
+	}
+	for msg := range errChan {
+		if msg != nil {
+			t.Fatalf("unexpected error: %+v", msg)
+		}
+	}
+	if string(got) != "gemini:fallback-secondary-model" {
+		t.Fatalf("payload = %q, want fallback stream", string(got))
+	}
+	if got := secondary.Models(); len(got) != 1 {
+		t.Fatalf("secondary attempts = %v, want 1", got)
+	}
+}
+
+func TestExecuteEmbeddingsWithAuthManager_SkipsFallbackAndChatOnlyProviders(t *testing.T) {
+	handler, primary, secondary := newModelFallbackHandler(t, http.StatusServiceUnavailable)
+
+	// The primary model is only served by claude, which has no embeddings endpoint.
+	_, errMsg := handler.ExecuteEmbeddingsWithAuthManager(context.Background(), "openai", "fallback-primary-model", []byte(`{"input":"hi"}`))
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("error = %+v, want 400 for a chat-only provider", errMsg)
+	}
+	if got := primary.Models(); len(got) != 0 {
+		t.Fatalf("primary attempts = %v, want none", got)
+	}
+	if got := secondary.Models(); len(got) != 0 {
+		t.Fatalf("secondary attempts = %v, want no fallback", got)
+	}
+
+	secondary.status = http.StatusServiceUnavailable
+	handler.Cfg.ModelFallbacks = []sdkconfig.ModelFallback{{Model: "fallback-secondary-model", Fallbacks: []string{"fallback-primary-model"}}}
+	if _, errMsg = handler.ExecuteEmbeddingsWithAuthManager(context.Background(), "openai", "fallback-secondary-model", []byte(`{"input":"hi"}`)); errMsg == nil || errMsg.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("error = %+v, want upstream 503 without fallback", errMsg)
+	}
+	if got := primary.Models(); len(got) != 0 {
+		t.Fatalf("primary attempts = %v, want no fallback", got)
+	}
+}
+
+func TestModelFallbackEligible(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &coreauth.Error{HTTPStatus: http.StatusServiceUnavailable}, true},
+		{"rate limited", &coreauth.Error{HTTPStatus: http.StatusTooManyRequests}, true},
+		{"no status", errors.New("connection reset"), true},
+		{"client error", &coreauth.Error{HTTPStatus: http.StatusBadRequest}, false},
+		{"client disconnected", fmt.Errorf("upstream request: %w", context.Canceled), false},
+	}
+	for _, tc := range cases {
+		if got := modelFallbackEligible(tc.err); got != tc.want {
+			t.Errorf("%s: modelFallbackEligible() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
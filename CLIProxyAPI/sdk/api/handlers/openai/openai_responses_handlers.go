@@ -9,21 +9,30 @@ package openai
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 )
 
 // OpenAIResponsesAPIHandler contains the handlers for OpenAIResponses API endpoints.
 // It holds a pool of clients to interact with the backend service.
 type OpenAIResponsesAPIHandler struct {
 	*handlers.BaseAPIHandler
+
+	// store keeps completed responses for previous_response_id and /v1/responses/{id}.
+	store ResponseStore
 }
 
 // NewOpenAIResponsesAPIHandler creates a new OpenAIResponses API handlers instance.
@@ -37,6 +46,7 @@ type OpenAIResponsesAPIHandler struct {
 func NewOpenAIResponsesAPIHandler(apiHandlers *handlers.BaseAPIHandler) *OpenAIResponsesAPIHandler {
 	return &OpenAIResponsesAPIHandler{
 		BaseAPIHandler: apiHandlers,
+		store:          GetResponseStore(),
 	}
 }
 
@@ -80,6 +90,11 @@ func (h *OpenAIResponsesAPIHandler) Responses(c *gin.Context) {
 		})
 		return
 	}
+	rawJSON, err = h.expandPreviousResponse(c.Request.Context(), responseOwner(c), rawJSON)
+	if err != nil {
+		h.writeResponseLookupError(c, gjson.GetBytes(rawJSON, "previous_response_id").String(), err)
+		return
+	}
 
 	// Check if the client requested a streaming response.
 	streamResult := gjson.GetBytes(rawJSON, "stream")
@@ -113,6 +128,7 @@ func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, r
 		return
 	}
 	_, _ = c.Writer.Write(resp)
+	h.storeResponse(c.Request.Context(), responseOwner(c), rawJSON, resp)
 	return
 
 	// no legacy fallback
@@ -191,15 +207,16 @@ func (h *OpenAIResponsesAPIHandler) handleStreamingResponse(c *gin.Context, rawJ
 			_, _ = c.Writer.Write(chunk)
 			_, _ = c.Writer.Write([]byte("\n"))
 			flusher.Flush()
+			h.observeResponsesChunk(c.Request.Context(), responseOwner(c), rawJSON, chunk)
 
 			// Continue
-			h.forwardResponsesStream(c, flusher, func(err error) { cliCancel(err) }, dataChan, errChan)
+			h.forwardResponsesStream(c, flusher, func(err error) { cliCancel(err) }, rawJSON, dataChan, errChan)
 			return
 		}
 	}
 }
 
-func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flusher http.Flusher, cancel func(error), rawJSON []byte, data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
 	h.ForwardStream(c, flusher, cancel, data, errs, handlers.StreamForwardOptions{
 		WriteChunk: func(chunk []byte) {
 			if bytes.HasPrefix(chunk, []byte("event:")) {
@@ -207,6 +224,7 @@ func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flush
 			}
 			_, _ = c.Writer.Write(chunk)
 			_, _ = c.Writer.Write([]byte("\n"))
+			h.observeResponsesChunk(c.Request.Context(), responseOwner(c), rawJSON, chunk)
 		},
 		WriteTerminalError: func(errMsg *interfaces.ErrorMessage) {
 			if errMsg == nil {
@@ -228,3 +246,183 @@ func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flush
 		},
 	})
 }
+
+// GetResponse handles GET /v1/responses/{id} by returning a stored response.
+func (h *OpenAIResponsesAPIHandler) GetResponse(c *gin.Context) {
+	id := c.Param("id")
+	stored, err := h.lookupResponse(c.Request.Context(), responseOwner(c), id)
+	if err != nil {
+		h.writeResponseLookupError(c, id, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", stored.Response)
+}
+
+// DeleteResponse handles DELETE /v1/responses/{id} by removing a stored response.
+func (h *OpenAIResponsesAPIHandler) DeleteResponse(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.lookupResponse(c.Request.Context(), responseOwner(c), id); err != nil {
+		h.writeResponseLookupError(c, id, err)
+		return
+	}
+	if err := h.store.Delete(c.Request.Context(), id); err != nil {
+		h.writeResponseLookupError(c, id, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "object": "response", "deleted": true})
+}
+
+// lookupResponse loads a stored response. Responses stored by another principal are reported
+// as not found so their ids cannot be probed.
+func (h *OpenAIResponsesAPIHandler) lookupResponse(ctx context.Context, owner, id string) (*StoredResponse, error) {
+	if h.store == nil || id == "" {
+		return nil, ErrResponseNotFound
+	}
+	stored, err := h.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Owner != owner {
+		return nil, errResponseNotOwned
+	}
+	return stored, nil
+}
+
+// errResponseNotOwned reports a response stored by another principal. It matches
+// ErrResponseNotFound so callers answer exactly as for an unknown id.
+var errResponseNotOwned = fmt.Errorf("%w: owned by another principal", ErrResponseNotFound)
+
+// responseOwner identifies the authenticated principal that owns stored responses.
+func responseOwner(c *gin.Context) string {
+	principal := c.GetString("apiKey")
+	if principal == "" {
+		return ""
+	}
+	return c.GetString("accessProvider") + ":" + principal
+}
+
+func (h *OpenAIResponsesAPIHandler) writeResponseLookupError(c *gin.Context, id string, err error) {
+	if errors.Is(err, ErrResponseNotFound) {
+		c.JSON(http.StatusNotFound, handlers.ErrorResponse{
+			Error: handlers.ErrorDetail{
+				Message: fmt.Sprintf("Response with id '%s' not found.", id),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, handlers.ErrorResponse{
+		Error: handlers.ErrorDetail{
+			Message: err.Error(),
+			Type:    "server_error",
+		},
+	})
+}
+
+// expandPreviousResponse replaces previous_response_id with the stored conversation so
+// providers without server-side state receive the full history. Unknown ids are left in
+// place for upstreams that track responses themselves; ids stored by another principal
+// are rejected so they are never forwarded upstream either.
+func (h *OpenAIResponsesAPIHandler) expandPreviousResponse(ctx context.Context, owner string, rawJSON []byte) ([]byte, error) {
+	prevID := gjson.GetBytes(rawJSON, "previous_response_id").String()
+	if prevID == "" {
+		return rawJSON, nil
+	}
+	prev, err := h.lookupResponse(ctx, owner, prevID)
+	if err != nil {
+		if errors.Is(err, errResponseNotOwned) {
+			return rawJSON, err
+		}
+		if !errors.Is(err, ErrResponseNotFound) {
+			log.Warnf("responses: load previous response %s: %v", prevID, err)
+		}
+		return rawJSON, nil
+	}
+	history := joinResponsesItems(gjson.ParseBytes(prev.Input), gjson.ParseBytes(prev.Output), responsesInputItems(rawJSON))
+	out, err := sjson.SetRawBytes(rawJSON, "input", []byte(history))
+	if err != nil {
+		return rawJSON, nil
+	}
+	out, _ = sjson.DeleteBytes(out, "previous_response_id")
+	return out, nil
+}
+
+// storeResponse saves a completed response for owner unless the client opted out with store=false.
+func (h *OpenAIResponsesAPIHandler) storeResponse(ctx context.Context, owner string, rawJSON, response []byte) {
+	if h.store == nil || gjson.GetBytes(rawJSON, "store").Type == gjson.False {
+		return
+	}
+	id := gjson.GetBytes(response, "id").String()
+	if id == "" {
+		return
+	}
+	output := gjson.GetBytes(response, "output").Raw
+	if output == "" {
+		output = "[]"
+	}
+	stored := &StoredResponse{
+		ID:        id,
+		Owner:     owner,
+		Model:     gjson.GetBytes(rawJSON, "model").String(),
+		Input:     json.RawMessage(joinResponsesItems(responsesInputItems(rawJSON))),
+		Output:    json.RawMessage(output),
+		Response:  append(json.RawMessage(nil), response...),
+		CreatedAt: time.Now(),
+	}
+	if err := h.store.Put(ctx, stored); err != nil {
+		log.Warnf("responses: store response %s: %v", id, err)
+	}
+}
+
+// observeResponsesChunk stores the final response carried by a response.completed event.
+func (h *OpenAIResponsesAPIHandler) observeResponsesChunk(ctx context.Context, owner string, rawJSON, chunk []byte) {
+	if h.store == nil || !bytes.Contains(chunk, []byte("response.completed")) {
+		return
+	}
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue
+		}
+		payload := bytes.TrimSpace(line[len("data:"):])
+		if gjson.GetBytes(payload, "type").String() != "response.completed" {
+			continue
+		}
+		if response := gjson.GetBytes(payload, "response"); response.IsObject() {
+			h.storeResponse(ctx, owner, rawJSON, []byte(response.Raw))
+		}
+		return
+	}
+}
+
+// responsesInputItems returns the request input as an item array, expanding the string shorthand.
+func responsesInputItems(rawJSON []byte) gjson.Result {
+	input := gjson.GetBytes(rawJSON, "input")
+	if input.Type == gjson.String {
+		item, _ := sjson.Set(`{"type":"message","role":"user"}`, "content", input.String())
+		return gjson.Parse("[" + item + "]")
+	}
+	return input
+}
+
+// joinResponsesItems concatenates the elements of several item arrays into one JSON array.
+func joinResponsesItems(arrays ...gjson.Result) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	first := true
+	for _, array := range arrays {
+		if !array.IsArray() {
+			continue
+		}
+		array.ForEach(func(_, item gjson.Result) bool {
+			if !first {
+				b.WriteByte(',')
+			}
+			b.WriteString(item.Raw)
+			first = false
+			return true
+		})
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package openai
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultResponseStoreTTL is how long the built-in store keeps a response.
+	DefaultResponseStoreTTL = time.Hour
+	// DefaultResponseStoreMaxEntries bounds the number of responses kept in memory.
+	DefaultResponseStoreMaxEntries = 1024
+)
+
+// ErrResponseNotFound is returned by a ResponseStore when the id is unknown or expired.
+var ErrResponseNotFound = errors.New("response not found")
+
+// StoredResponse is a completed Responses API result together with the conversation
+// history that produced it, so that follow-up turns can be rebuilt without the upstream.
+type StoredResponse struct {
+	// ID is the response id returned to the client.
+	ID string `json:"id"`
+	// Owner identifies the authenticated principal that created the response. Only the
+	// same principal may read, delete or chain off it.
+	Owner string `json:"owner,omitempty"`
+	// Model is the model the client requested.
+	Model string `json:"model,omitempty"`
+	// Input is the full input item array sent upstream, including earlier turns.
+	Input json.RawMessage `json:"input"`
+	// Output is the output item array of the response.
+	Output json.RawMessage `json:"output"`
+	// Response is the complete response object as returned to the client.
+	Response json.RawMessage `json:"response"`
+	// CreatedAt records when the response was stored.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResponseStore persists Responses API results for previous_response_id chaining and
+// the GET/DELETE /v1/responses/{id} endpoints.
+type ResponseStore interface {
+	Get(ctx context.Context, id string) (*StoredResponse, error)
+	Put(ctx context.Context, resp *StoredResponse) error
+	Delete(ctx context.Context, id string) error
+}
+
+var (
+	responseStoreMu         sync.RWMutex
+	registeredResponseStore ResponseStore
+)
+
+// RegisterResponseStore sets the store used by Responses API handlers created afterwards.
+func RegisterResponseStore(store ResponseStore) {
+	responseStoreMu.Lock()
+	registeredResponseStore = store
+	responseStoreMu.Unlock()
+}
+
+// GetResponseStore returns the registered store, creating an in-memory one on first use.
+func GetResponseStore() ResponseStore {
+	responseStoreMu.RLock()
+	s := registeredResponseStore
+	responseStoreMu.RUnlock()
+	if s != nil {
+		return s
+	}
+	responseStoreMu.Lock()
+	defer responseStoreMu.Unlock()
+	if registeredResponseStore == nil {
+		registeredResponseStore = NewMemoryResponseStore(DefaultResponseStoreTTL, DefaultResponseStoreMaxEntries)
+	}
+	return registeredResponseStore
+}
+
+// MemoryResponseStore keeps responses in process memory with a TTL and an entry cap.
+// When the cap is reached the oldest response is evicted.
+type MemoryResponseStore struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+type memoryResponseEntry struct {
+	resp      *StoredResponse
+	expiresAt time.Time
+}
+
+// NewMemoryResponseStore creates an in-memory store. Non-positive arguments select the defaults.
+func NewMemoryResponseStore(ttl time.Duration, maxEntries int) *MemoryResponseStore {
+	if ttl <= 0 {
+		ttl = DefaultResponseStoreTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultResponseStoreMaxEntries
+	}
+	return &MemoryResponseStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get implements ResponseStore.
+func (s *MemoryResponseStore) Get(_ context.Context, id string) (*StoredResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[id]
+	if !ok {
+		return nil, ErrResponseNotFound
+	}
+	entry := elem.Value.(*memoryResponseEntry)
+	if !s.now().Before(entry.expiresAt) {
+		s.removeLocked(elem)
+		return nil, ErrResponseNotFound
+	}
+	return entry.resp, nil
+}
+
+// Put implements ResponseStore.
+func (s *MemoryResponseStore) Put(_ context.Context, resp *StoredResponse) error {
+	if resp == nil || resp.ID == "" {
+		return errors.New("response store: id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[resp.ID]; ok {
+		s.removeLocked(elem)
+	}
+	s.entries[resp.ID] = s.order.PushBack(&memoryResponseEntry{resp: resp, expiresAt: s.now().Add(s.ttl)})
+	for s.order.Len() > s.maxEntries {
+		s.removeLocked(s.order.Front())
+	}
+	return nil
+}
+
+// Delete implements ResponseStore.
+func (s *MemoryResponseStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[id]
+	if !ok {
+		return ErrResponseNotFound
+	}
+	s.removeLocked(elem)
+	return nil
+}
+
+func (s *MemoryResponseStore) removeLocked(elem *list.Element) {
+	entry := s.order.Remove(elem).(*memoryResponseEntry)
+	delete(s.entries, entry.resp.ID)
+}
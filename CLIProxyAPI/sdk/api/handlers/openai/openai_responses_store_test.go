@@ -0,0 +1,149 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+func TestMemoryResponseStoreExpiresAndEvicts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	store := NewMemoryResponseStore(time.Minute, 2)
+	store.now = func() time.Time { return now }
+
+	for _, id := range []string{"resp_1", "resp_2", "resp_3"} {
+		if err := store.Put(ctx, &StoredResponse{ID: id}); err != nil {
+			t.Fatalf("Put(%s) error = %v", id, err)
+		}
+	}
+	if _, err := store.Get(ctx, "resp_1"); !errors.Is(err, ErrResponseNotFound) {
+		t.Fatalf("Get(resp_1) error = %v, want eviction", err)
+	}
+	if _, err := store.Get(ctx, "resp_3"); err != nil {
+		t.Fatalf("Get(resp_3) error = %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := store.Get(ctx, "resp_3"); !errors.Is(err, ErrResponseNotFound) {
+		t.Fatalf("Get(resp_3) after TTL error = %v, want expiry", err)
+	}
+}
+
+func TestResponsesPreviousResponseIDRebuildsHistory(t *testing.T) {
+	ctx := context.Background()
+	h := &OpenAIResponsesAPIHandler{store: NewMemoryResponseStore(0, 0)}
+
+	first := []byte(`{"model":"claude-sonnet-4-5","input":"hi"}`)
+	h.storeResponse(ctx, "", first, []byte(`{"id":"resp_1","object":"response","output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":"hello"}]}]}`))
+
+	second, _ := h.expandPreviousResponse(ctx, "", []byte(`{"model":"claude-sonnet-4-5","previous_response_id":"resp_1","input":[{"type":"message","role":"user","content":"again"}]}`))
+	if gjson.GetBytes(second, "previous_response_id").Exists() {
+		t.Fatalf("previous_response_id was not removed: %s", second)
+	}
+	input := gjson.GetBytes(second, "input")
+	if got := input.Get("#").Int(); got != 3 {
+		t.Fatalf("input.# = %d, want 3: %s", got, input.Raw)
+	}
+	if input.Get("0.content").String() != "hi" || input.Get("1.role").String() != "assistant" || input.Get("2.content").String() != "again" {
+		t.Fatalf("rebuilt history = %s", input.Raw)
+	}
+
+	stream := "event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_2\",\"output\":[]}}"
+	h.observeResponsesChunk(ctx, "", second, []byte(stream))
+	stored, err := h.store.Get(ctx, "resp_2")
+	if err != nil {
+		t.Fatalf("streamed response not stored: %v", err)
+	}
+	if got := gjson.GetBytes(stored.Input, "#").Int(); got != 3 {
+		t.Fatalf("stored input.# = %d, want full history", got)
+	}
+
+	h.storeResponse(ctx, "", []byte(`{"store":false,"input":"x"}`), []byte(`{"id":"resp_3"}`))
+	if _, err = h.store.Get(ctx, "resp_3"); !errors.Is(err, ErrResponseNotFound) {
+		t.Fatal("store=false response was persisted")
+	}
+}
+
+func TestResponsesGetAndDeleteEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &OpenAIResponsesAPIHandler{store: NewMemoryResponseStore(0, 0)}
+	h.storeResponse(context.Background(), "", []byte(`{"input":"hi"}`), []byte(`{"id":"resp_1","object":"response"}`))
+
+	router := gin.New()
+	router.GET("/v1/responses/:id", h.GetResponse)
+	router.DELETE("/v1/responses/:id", h.DeleteResponse)
+
+	do := func(method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, "/v1/responses/resp_1", nil))
+		return rec
+	}
+	if rec := do(http.MethodGet); rec.Code != http.StatusOK || gjson.Get(rec.Body.String(), "id").String() != "resp_1" {
+		t.Fatalf("GET = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodDelete); rec.Code != http.StatusOK || !gjson.Get(rec.Body.String(), "deleted").Bool() {
+		t.Fatalf("DELETE = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet); rec.Code != http.StatusNotFound {
+		t.Fatalf("GET after delete = %d, want 404", rec.Code)
+	}
+}
+
+func TestResponsesAreScopedToTheirOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &OpenAIResponsesAPIHandler{store: NewMemoryResponseStore(0, 0)}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("apiKey", c.GetHeader("X-Test-Key"))
+		c.Set("accessProvider", "config-inline")
+	})
+	router.GET("/v1/responses/:id", h.GetResponse)
+	router.DELETE("/v1/responses/:id", h.DeleteResponse)
+	router.POST("/store", func(c *gin.Context) {
+		h.storeResponse(c.Request.Context(), responseOwner(c), []byte(`{"input":"secret"}`), []byte(`{"id":"resp_a","object":"response","output":[]}`))
+	})
+	router.POST("/chain", func(c *gin.Context) {
+		out, err := h.expandPreviousResponse(c.Request.Context(), responseOwner(c), []byte(`{"previous_response_id":"resp_a","input":"next"}`))
+		if err != nil {
+			h.writeResponseLookupError(c, "resp_a", err)
+			return
+		}
+		c.Data(http.StatusOK, "application/json", out)
+	})
+
+	do := func(method, path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Test-Key", key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	do(http.MethodPost, "/store", "key-a")
+
+	if rec := do(http.MethodGet, "/v1/responses/resp_a", "key-b"); rec.Code != http.StatusNotFound {
+		t.Fatalf("GET by other key = %d, want 404", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/v1/responses/resp_a", "key-b"); rec.Code != http.StatusNotFound {
+		t.Fatalf("DELETE by other key = %d, want 404", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/chain", "key-b"); rec.Code != http.StatusNotFound {
+		t.Fatalf("chain by other key = %d %s, want 404", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/chain", "key-a"); gjson.Get(rec.Body.String(), "input.0.content").String() != "secret" {
+		t.Fatalf("chain by owner = %s", rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/v1/responses/resp_a", "key-a"); rec.Code != http.StatusOK {
+		t.Fatalf("GET by owner = %d, want 200", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/v1/responses/resp_a", "key-a"); rec.Code != http.StatusOK {
+		t.Fatalf("DELETE by owner = %d, want 200", rec.Code)
+	}
+}
@@ -199,6 +199,16 @@ func (s *FileTokenStore) readAuthFile(path, baseDir string) (*cliproxyauth.Auth,
 	if email, ok := metadata["email"].(string); ok && email != "" {
 		auth.Attributes["email"] = email
 	}
+	if prefix, ok := metadata[cliproxyauth.MetadataPrefixKey].(string); ok {
+		auth.Prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	}
+	if proxyURL, ok := metadata[cliproxyauth.MetadataProxyURLKey].(string); ok {
+		auth.ProxyURL = strings.TrimSpace(proxyURL)
+	}
+	if disabled, ok := metadata[cliproxyauth.MetadataDisabledKey].(bool); ok && disabled {
+		auth.Disabled = true
+		auth.Status = cliproxyauth.StatusDisabled
+	}
 	return auth, nil
 }
 
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/circuitbreaker"
+)
+
+// BreakerStatus summarises the circuit breakers that guard a single auth entry.
+type BreakerStatus struct {
+	// Auth is the breaker tracking failures of the credential itself.
+	Auth *circuitbreaker.Status `json:"auth,omitempty"`
+	// Models holds the provider+model breakers for models this auth has served.
+	Models map[string]circuitbreaker.Status `json:"models,omitempty"`
+}
+
+// SetCircuitBreakers installs the breaker manager consulted during credential selection.
+// Passing nil disables circuit breaking.
+func (m *Manager) SetCircuitBreakers(breakers *circuitbreaker.Manager) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.breakers = breakers
+	m.mu.Unlock()
+}
+
+// CircuitBreakerStatus reports the breaker state for the given auth, or nil when breakers are disabled
+// or none have been created for it yet.
+func (m *Manager) CircuitBreakerStatus(auth *Auth) *BreakerStatus {
+	if m == nil || auth == nil {
+		return nil
+	}
+	m.mu.RLock()
+	breakers := m.breakers
+	m.mu.RUnlock()
+	if breakers == nil {
+		return nil
+	}
+	status := &BreakerStatus{}
+	if breaker, ok := breakers.Get(authBreakerName(auth.ID)); ok {
+		snapshot := breaker.Status()
+		status.Auth = &snapshot
+	}
+	provider := strings.ToLower(strings.TrimSpace(auth.Provider))
+	for model := range auth.ModelStates {
+		breaker, ok := breakers.Get(modelBreakerName(provider, model))
+		if !ok {
+			continue
+		}
+		if status.Models == nil {
+			status.Models = make(map[string]circuitbreaker.Status)
+		}
+		status.Models[model] = breaker.Status()
+	}
+	if status.Auth == nil && len(status.Models) == 0 {
+		return nil
+	}
+	return status
+}
+
+func authBreakerName(authID string) string {
+	return "auth:" + authID
+}
+
+func modelBreakerName(provider, model string) string {
+	return "model:" + circuitbreaker.BreakerForUpstream(provider, model, "")
+}
+
+// breakerReady reports whether the named breaker admits requests; missing breakers are always ready.
+func breakerReady(breakers *circuitbreaker.Manager, name string) bool {
+	if breakers == nil {
+		return true
+	}
+	breaker, ok := breakers.Get(name)
+	if !ok {
+		return true
+	}
+	return breaker.Ready()
+}
+
+// admitBreakers claims an admission on the provider+model and auth breakers.
+// Both admissions are released when either breaker rejects the request.
+func admitBreakers(breakers *circuitbreaker.Manager, provider, model, authID string) bool {
+	if breakers == nil {
+		return true
+	}
+	var modelBreaker *circuitbreaker.CircuitBreaker
+	if model != "" {
+		modelBreaker = breakers.GetOrCreate(modelBreakerName(provider, model))
+		if modelBreaker.Allow() != nil {
+			return false
+		}
+	}
+	if breakers.GetOrCreate(authBreakerName(authID)).Allow() != nil {
+		if modelBreaker != nil {
+			modelBreaker.Release()
+		}
+		return false
+	}
+	return true
+}
+
+func newCircuitOpenError(provider, model string) *Error {
+	message := "circuit breaker open for provider " + provider
+	if model != "" {
+		message += " model " + model
+	}
+	return &Error{Code: "circuit_open", Message: message, Retryable: true, HTTPStatus: http.StatusServiceUnavailable}
+}
+
+// recordBreakerResult feeds an execution outcome into the breakers admitted for it.
+// Client-side errors still count as a healthy upstream so half-open probes are not left pending.
+// When the caller canceled the request the upstream outcome is unknown, so the admissions are
+// only released.
+func (m *Manager) recordBreakerResult(ctx context.Context, result Result) {
+	m.mu.RLock()
+	breakers := m.breakers
+	m.mu.RUnlock()
+	if breakers == nil || result.AuthID == "" {
+		return
+	}
+	canceled := !result.Success && callerCanceled(ctx, result.Error)
+	success := result.Success || !breakerCountsFailure(result.Error)
+	record := func(breaker *circuitbreaker.CircuitBreaker) {
+		if canceled {
+			breaker.Release()
+			return
+		}
+		breaker.Record(success)
+	}
+	provider := strings.ToLower(strings.TrimSpace(result.Provider))
+	if breaker, ok := breakers.Get(authBreakerName(result.AuthID)); ok {
+		record(breaker)
+	}
+	if result.Model != "" {
+		if breaker, ok := breakers.Get(modelBreakerName(provider, result.Model)); ok {
+			record(breaker)
+		}
+	}
+}
+
+// callerCanceled reports whether a failure without an upstream status came from the caller's
+// context being canceled or timing out, for example a client disconnect.
+func callerCanceled(ctx context.Context, err *Error) bool {
+	return ctx != nil && ctx.Err() != nil && statusCodeFromResult(err) == 0
+}
+
+// breakerCountsFailure reports whether an execution error indicates an unhealthy upstream.
+func breakerCountsFailure(err *Error) bool {
+	status := statusCodeFromResult(err)
+	switch {
+	case status == 0:
+		return true
+	case status == http.StatusRequestTimeout:
+		return true
+	case status >= http.StatusInternalServerError:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/circuitbreaker"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type stubExecutor struct {
+	provider string
+}
+
+func (e stubExecutor) Identifier() string { return e.provider }
+
+func (e stubExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e stubExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e stubExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e stubExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e stubExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newBreakerTestManager(t *testing.T, ids ...string) (*Manager, *circuitbreaker.Manager) {
+	t.Helper()
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.RegisterExecutor(stubExecutor{provider: "stub"})
+	for _, id := range ids {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "stub"}); err != nil {
+			t.Fatalf("Register(%s) error = %v", id, err)
+		}
+	}
+	cfg := circuitbreaker.DefaultConfig()
+	cfg.FailureThreshold = 1
+	cfg.Timeout = time.Hour
+	breakers := circuitbreaker.NewManager(cfg)
+	manager.SetCircuitBreakers(breakers)
+	return manager, breakers
+}
+
+func TestPickNextSkipsOpenAuthBreaker(t *testing.T) {
+	manager, breakers := newBreakerTestManager(t, "a", "b")
+	breakers.GetOrCreate(authBreakerName("a")).Record(false)
+
+	for i := 0; i < 4; i++ {
+		auth, _, err := manager.pickNext(context.Background(), "stub", "", cliproxyexecutor.Options{}, map[string]struct{}{})
+		if err != nil {
+			t.Fatalf("pickNext() #%d error = %v", i, err)
+		}
+		if auth.ID != "b" {
+			t.Fatalf("pickNext() #%d auth.ID = %q, want %q", i, auth.ID, "b")
+		}
+		manager.MarkResult(context.Background(), Result{AuthID: auth.ID, Provider: "stub", Success: true})
+	}
+
+	status := manager.CircuitBreakerStatus(&Auth{ID: "a", Provider: "stub"})
+	if status == nil || status.Auth == nil || status.Auth.State != "open" {
+		t.Fatalf("CircuitBreakerStatus(a) = %+v, want open auth breaker", status)
+	}
+}
+
+func TestPickNextReturnsCircuitOpenWhenAllBreakersOpen(t *testing.T) {
+	manager, breakers := newBreakerTestManager(t, "a")
+	breakers.GetOrCreate(authBreakerName("a")).Record(false)
+
+	_, _, err := manager.pickNext(context.Background(), "stub", "", cliproxyexecutor.Options{}, map[string]struct{}{})
+	var authErr *Error
+	if !errors.As(err, &authErr) || authErr.Code != "circuit_open" {
+		t.Fatalf("pickNext() error = %v, want circuit_open", err)
+	}
+	if authErr.StatusCode() != http.StatusServiceUnavailable {
+		t.Fatalf("pickNext() status = %d, want %d", authErr.StatusCode(), http.StatusServiceUnavailable)
+	}
+}
+
+func TestMarkResultIgnoresClientErrorsForBreaker(t *testing.T) {
+	manager, breakers := newBreakerTestManager(t, "a")
+	if _, _, err := manager.pickNext(context.Background(), "stub", "", cliproxyexecutor.Options{}, map[string]struct{}{}); err != nil {
+		t.Fatalf("pickNext() error = %v", err)
+	}
+	manager.MarkResult(context.Background(), Result{AuthID: "a", Provider: "stub", Error: &Error{HTTPStatus: http.StatusBadRequest}})
+
+	breaker, ok := breakers.Get(authBreakerName("a"))
+	if !ok {
+		t.Fatalf("auth breaker was not created on admission")
+	}
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Fatalf("breaker state = %s, want closed", state)
+	}
+}
+
+func TestMarkResultIgnoresCallerCancellationForBreaker(t *testing.T) {
+	manager, breakers := newBreakerTestManager(t, "a")
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, _, err := manager.pickNext(ctx, "stub", "", cliproxyexecutor.Options{}, map[string]struct{}{}); err != nil {
+		t.Fatalf("pickNext() error = %v", err)
+	}
+	cancel()
+	manager.MarkResult(ctx, Result{AuthID: "a", Provider: "stub", Error: &Error{Message: context.Canceled.Error()}})
+
+	breaker, _ := breakers.Get(authBreakerName("a"))
+	if state := breaker.State(); state != circuitbreaker.StateClosed {
+		t.Fatalf("breaker state after client disconnect = %s, want closed", state)
+	}
+
+	// The same error without a canceled caller context still trips the breaker.
+	if _, _, err := manager.pickNext(context.Background(), "stub", "", cliproxyexecutor.Options{}, map[string]struct{}{}); err != nil {
+		t.Fatalf("pickNext() error = %v", err)
+	}
+	manager.MarkResult(context.Background(), Result{AuthID: "a", Provider: "stub", Error: &Error{Message: "connection reset"}})
+	if state := breaker.State(); state != circuitbreaker.StateOpen {
+		t.Fatalf("breaker state after upstream failure = %s, want open", state)
+	}
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+const (
+	// defaultConcurrencyQueueSize bounds the requests waiting for a free credential when unset.
+	defaultConcurrencyQueueSize = 64
+	// defaultConcurrencyQueueTimeout bounds how long a queued request waits when unset.
+	defaultConcurrencyQueueTimeout = 30 * time.Second
+)
+
+// ConcurrencySettings configures the per-auth in-flight caps and the queue used when every
+// candidate auth is at capacity.
+type ConcurrencySettings struct {
+	// ProviderDefaults is the cap applied to auths of a provider that do not set max_concurrency.
+	ProviderDefaults map[string]int
+	// QueueSize bounds the requests waiting for a free auth; zero uses the default.
+	QueueSize int
+	// QueueTimeout bounds how long a request waits for a free auth; zero uses the default.
+	QueueTimeout time.Duration
+}
+
+// concurrencyLimiter counts the requests in flight on each auth and wakes queued requests when
+// a slot frees up. Counts survive settings changes so reloads never over-admit.
+type concurrencyLimiter struct {
+	mu           sync.Mutex
+	defaults     map[string]int
+	queueSize    int
+	queueTimeout time.Duration
+	inFlight     map[string]int
+	waiting      int
+	// released is closed and replaced whenever a slot frees up.
+	released chan struct{}
+}
+
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{
+		queueSize:    defaultConcurrencyQueueSize,
+		queueTimeout: defaultConcurrencyQueueTimeout,
+		inFlight:     make(map[string]int),
+		released:     make(chan struct{}),
+	}
+}
+
+// SetConcurrencyLimits replaces the provider default caps and queue settings. Requests already
+// in flight keep their slots.
+func (m *Manager) SetConcurrencyLimits(settings ConcurrencySettings) {
+	if m == nil || m.concurrency == nil {
+		return
+	}
+	defaults := make(map[string]int, len(settings.ProviderDefaults))
+	for provider, limit := range settings.ProviderDefaults {
+		if key := strings.ToLower(strings.TrimSpace(provider)); key != "" && limit > 0 {
+			defaults[key] = limit
+		}
+	}
+	queueSize := settings.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultConcurrencyQueueSize
+	}
+	queueTimeout := settings.QueueTimeout
+	if queueTimeout <= 0 {
+		queueTimeout = defaultConcurrencyQueueTimeout
+	}
+	l := m.concurrency
+	l.mu.Lock()
+	l.defaults = defaults
+	l.queueSize = queueSize
+	l.queueTimeout = queueTimeout
+	l.wakeLocked()
+	l.mu.Unlock()
+}
+
+// InFlight returns the number of requests currently executing on the auth.
+func (m *Manager) InFlight(id string) int {
+	if m == nil || m.concurrency == nil {
+		return 0
+	}
+	m.concurrency.mu.Lock()
+	defer m.concurrency.mu.Unlock()
+	return m.concurrency.inFlight[id]
+}
+
+// limitLocked returns the cap for auth, or 0 when it is unlimited.
+func (l *concurrencyLimiter) limitLocked(auth *Auth) int {
+	if limit := auth.MaxConcurrency(); limit > 0 {
+		return limit
+	}
+	return l.defaults[strings.ToLower(auth.Provider)]
+}
+
+// saturated reports whether auth has no free slot.
+func (l *concurrencyLimiter) saturated(auth *Auth) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit := l.limitLocked(auth)
+	return limit > 0 && l.inFlight[auth.ID] >= limit
+}
+
+// tryAcquire claims a slot on auth. Every successful call must be paired with release.
+func (l *concurrencyLimiter) tryAcquire(auth *Auth) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit := l.limitLocked(auth)
+	if limit > 0 && l.inFlight[auth.ID] >= limit {
+		return false
+	}
+	l.inFlight[auth.ID]++
+	return true
+}
+
+func (l *concurrencyLimiter) release(authID string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight[authID] <= 1 {
+		delete(l.inFlight, authID)
+	} else {
+		l.inFlight[authID]--
+	}
+	l.wakeLocked()
+}
+
+func (l *concurrencyLimiter) wakeLocked() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// releasedChan returns the channel closed on the next release. Callers take it before checking
+// for a free slot so a release in between is not missed.
+func (l *concurrencyLimiter) releasedChan() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.released
+}
+
+// enqueue takes a queue position, returning the wait timeout, or false when the queue is full.
+func (l *concurrencyLimiter) enqueue() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.waiting >= l.queueSize {
+		return 0, false
+	}
+	l.waiting++
+	return l.queueTimeout, true
+}
+
+func (l *concurrencyLimiter) dequeue() {
+	l.mu.Lock()
+	l.waiting--
+	l.mu.Unlock()
+}
+
+func newConcurrencySaturatedError(provider string) *Error {
+	return &Error{Code: "concurrency_saturated", Message: "all credentials for provider " + provider + " are at max concurrency", Retryable: true, HTTPStatus: http.StatusTooManyRequests}
+}
+
+func isConcurrencySaturated(err error) bool {
+	authErr, ok := err.(*Error)
+	return ok && authErr != nil && authErr.Code == "concurrency_saturated"
+}
+
+// acquireNext picks the next auth and claims one of its concurrency slots. When every candidate
+// is at capacity the request waits in a bounded queue until a slot frees up, the queue timeout
+// passes or ctx is done. The caller must release the slot of the returned auth.
+func (m *Manager) acquireNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
+	limiter := m.concurrency
+	var timer *time.Timer
+	for {
+		var released <-chan struct{}
+		if limiter != nil {
+			released = limiter.releasedChan()
+		}
+		auth, executor, err := m.pickNext(ctx, provider, model, opts, tried)
+		if err == nil || !isConcurrencySaturated(err) || limiter == nil {
+			if timer != nil {
+				timer.Stop()
+				limiter.dequeue()
+			}
+			return auth, executor, err
+		}
+		if timer == nil {
+			timeout, ok := limiter.enqueue()
+			if !ok {
+				return nil, nil, &Error{Code: "concurrency_queue_full", Message: "too many requests waiting for a credential of provider " + provider, Retryable: true, HTTPStatus: http.StatusTooManyRequests}
+			}
+			timer = time.NewTimer(timeout)
+		}
+		select {
+		case <-released:
+		case <-timer.C:
+			limiter.dequeue()
+			return nil, nil, &Error{Code: "concurrency_queue_timeout", Message: "timed out waiting for a credential of provider " + provider, Retryable: true, HTTPStatus: http.StatusTooManyRequests}
+		case <-ctx.Done():
+			timer.Stop()
+			limiter.dequeue()
+			return nil, nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// blockingExecutor holds every call until release is closed and records the peak concurrency per auth.
+type blockingExecutor struct {
+	stubExecutor
+	release chan struct{}
+	started chan string
+
+	mu      sync.Mutex
+	current map[string]int
+	peak    map[string]int
+}
+
+func (e *blockingExecutor) Execute(ctx context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	e.current[auth.ID]++
+	if e.current[auth.ID] > e.peak[auth.ID] {
+		e.peak[auth.ID] = e.current[auth.ID]
+	}
+	e.mu.Unlock()
+	e.started <- auth.ID
+	select {
+	case <-e.release:
+	case <-ctx.Done():
+	}
+	e.mu.Lock()
+	e.current[auth.ID]--
+	e.mu.Unlock()
+	return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+}
+
+func newConcurrencyTestManager(t *testing.T, executor *blockingExecutor, auths ...*Auth) *Manager {
+	t.Helper()
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	for _, auth := range auths {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, err)
+		}
+	}
+	return manager
+}
+
+func TestConcurrencyLimitQueuesUntilSlotFrees(t *testing.T) {
+	executor := &blockingExecutor{
+		stubExecutor: stubExecutor{provider: "cc"},
+		release:      make(chan struct{}),
+		started:      make(chan string, 8),
+		current:      map[string]int{},
+		peak:         map[string]int{},
+	}
+	manager := newConcurrencyTestManager(t, executor,
+		&Auth{ID: "a", Provider: "cc", Attributes: map[string]string{"max_concurrency": "2"}},
+		&Auth{ID: "b", Provider: "cc"},
+	)
+	manager.SetConcurrencyLimits(ConcurrencySettings{ProviderDefaults: map[string]int{"cc": 1}})
+
+	const requests = 6
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := manager.Execute(context.Background(), []string{"cc"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+			errs <- err
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		<-executor.started
+	}
+	select {
+	case id := <-executor.started:
+		t.Fatalf("request started on %s while every auth was saturated", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := manager.InFlight("a") + manager.InFlight("b"); got != 3 {
+		t.Fatalf("in flight = %d, want 3", got)
+	}
+
+	close(executor.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if executor.peak["a"] != 2 || executor.peak["b"] != 1 {
+		t.Fatalf("peak concurrency = %v, want a=2 b=1", executor.peak)
+	}
+	if manager.InFlight("a") != 0 || manager.InFlight("b") != 0 {
+		t.Fatal("slots were not released")
+	}
+}
+
+func TestConcurrencyQueueFullAndTimeout(t *testing.T) {
+	executor := &blockingExecutor{
+		stubExecutor: stubExecutor{provider: "cc"},
+		release:      make(chan struct{}),
+		started:      make(chan string, 4),
+		current:      map[string]int{},
+		peak:         map[string]int{},
+	}
+	manager := newConcurrencyTestManager(t, executor,
+		&Auth{ID: "a", Provider: "cc", Metadata: map[string]any{"max_concurrency": float64(1)}},
+	)
+	manager.SetConcurrencyLimits(ConcurrencySettings{QueueSize: 1, QueueTimeout: 100 * time.Millisecond})
+	defer close(executor.release)
+
+	go func() {
+		_, _ = manager.Execute(context.Background(), []string{"cc"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	}()
+	<-executor.started
+
+	queued := make(chan error, 1)
+	go func() {
+		_, err := manager.Execute(context.Background(), []string{"cc"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+		queued <- err
+	}()
+	deadline := time.Now().Add(time.Second)
+	for {
+		manager.concurrency.mu.Lock()
+		waiting := manager.concurrency.waiting
+		manager.concurrency.mu.Unlock()
+		if waiting == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("second request never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := manager.Execute(context.Background(), []string{"cc"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	var authErr *Error
+	if !errors.As(err, &authErr) || authErr.Code != "concurrency_queue_full" || authErr.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("Execute() with full queue error = %v, want concurrency_queue_full", err)
+	}
+
+	err = <-queued
+	if !errors.As(err, &authErr) || authErr.Code != "concurrency_queue_timeout" {
+		t.Fatalf("queued Execute() error = %v, want concurrency_queue_timeout", err)
+	}
+}
+
+// endlessStreamExecutor streams chunks until the request context is canceled.
+type endlessStreamExecutor struct {
+	stubExecutor
+	closed chan struct{}
+}
+
+func (e *endlessStreamExecutor) ExecuteStream(ctx context.Context, _ *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	chunks := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(e.closed)
+		defer close(chunks)
+		for {
+			select {
+			case chunks <- cliproxyexecutor.StreamChunk{Payload: []byte("data")}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return chunks, nil
+}
+
+func TestCanceledStreamReleasesSlot(t *testing.T) {
+	executor := &endlessStreamExecutor{stubExecutor: stubExecutor{provider: "cc"}, closed: make(chan struct{})}
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	if _, err := manager.Register(context.Background(), &Auth{ID: "a", Provider: "cc", Attributes: map[string]string{"max_concurrency": "1"}}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks, err := manager.ExecuteStream(ctx, []string{"cc"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	<-chunks
+	// The client disconnects and stops reading mid-stream.
+	cancel()
+
+	select {
+	case <-executor.closed:
+	case <-time.After(time.Second):
+		t.Fatal("executor stream never finished after cancel")
+	}
+	deadline := time.Now().Add(time.Second)
+	for manager.InFlight("a") != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("concurrency slot leaked by a canceled stream")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if auth, _ := manager.GetByID("a"); auth.LastError != nil || auth.Status == StatusError {
+		t.Fatalf("canceled stream marked the auth as failed: %+v", auth.LastError)
+	}
+}
+
+func TestCanceledStreamReleasesLeastLoadedSelection(t *testing.T) {
+	executor := &endlessStreamExecutor{stubExecutor: stubExecutor{provider: "cc"}, closed: make(chan struct{})}
+	selector := &LeastLoadedSelector{}
+	manager := NewManager(nil, selector, nil)
+	manager.RegisterExecutor(executor)
+	if _, err := manager.Register(context.Background(), &Auth{ID: "a", Provider: "cc"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chunks, err := manager.ExecuteStream(ctx, []string{"cc"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	<-chunks
+	cancel()
+	for range chunks {
+	}
+
+	selector.mu.Lock()
+	defer selector.mu.Unlock()
+	stats := selector.stats["a"]
+	if stats == nil || stats.inFlight != 0 {
+		t.Fatalf("least-loaded stats after a canceled stream = %+v, want nothing in flight", stats)
+	}
+	if stats.errorRate != 0 {
+		t.Fatalf("canceled stream counted as an error: %v", stats.errorRate)
+	}
+}
+
+// panickingExecutor panics on every call.
+type panickingExecutor struct{ stubExecutor }
+
+func (panickingExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	panic("executor bug")
+}
+
+func TestPanickingExecutorReleasesSlot(t *testing.T) {
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.RegisterExecutor(panickingExecutor{stubExecutor{provider: "cc"}})
+	if _, err := manager.Register(context.Background(), &Auth{ID: "a", Provider: "cc"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = manager.Execute(context.Background(), []string{"cc"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	}()
+	if got := manager.InFlight("a"); got != 0 {
+		t.Fatalf("in flight after panic = %d, want 0", got)
+	}
+}
+
+func TestPanickingExecutorReleasesLeastLoadedSelection(t *testing.T) {
+	selector := &LeastLoadedSelector{}
+	manager := NewManager(nil, selector, nil)
+	manager.RegisterExecutor(panickingExecutor{stubExecutor{provider: "cc"}})
+	if _, err := manager.Register(context.Background(), &Auth{ID: "a", Provider: "cc"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = manager.Execute(context.Background(), []string{"cc"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	}()
+	selector.mu.Lock()
+	defer selector.mu.Unlock()
+	if stats := selector.stats["a"]; stats == nil || stats.inFlight != 0 {
+		t.Fatalf("least-loaded stats after panic = %+v, want nothing in flight", stats)
+	}
+}
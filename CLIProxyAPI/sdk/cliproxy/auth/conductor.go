@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/circuitbreaker"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
@@ -52,6 +53,10 @@ const (
 	quotaBackoffMax       = 30 * time.Minute
 )
 
+// PinnedAuthIndexMetadataKey is the execution metadata key restricting selection to the auth
+// whose Index matches the value. Management replays use it to target one credential.
+const PinnedAuthIndexMetadataKey = "pinned_auth_index"
+
 var quotaCooldownDisabled atomic.Bool
 
 // SetQuotaCooldownDisabled toggles quota cooldown scheduling globally.
@@ -71,6 +76,10 @@ type Result struct {
 	Success bool
 	// RetryAfter carries a provider supplied retry hint (e.g. 429 retryDelay).
 	RetryAfter *time.Duration
+	// TTFB is the time until the first upstream byte (full response for non-streaming calls); zero when unknown.
+	TTFB time.Duration
+	// RateLimit is the rate limit snapshot the executor reported from response headers, if any.
+	RateLimit *RateLimitState
 	// Error describes the failure when Success is false.
 	Error *Error
 }
@@ -80,6 +89,18 @@ type Selector interface {
 	Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error)
 }
 
+// SelectionObserver is an optional Selector extension that tracks load per auth.
+// Every OnSelected is followed by exactly one OnResult or OnReleased for the same auth.
+type SelectionObserver interface {
+	// OnSelected fires once the manager commits to executing a request with auth.
+	OnSelected(provider, model string, auth *Auth)
+	// OnResult fires for every result recorded through MarkResult.
+	OnResult(result Result)
+	// OnReleased fires when a selected request ends without a result, for example a stream
+	// whose client went away. It must not count as a success or a failure.
+	OnReleased(authID string)
+}
+
 // Hook captures lifecycle callbacks for observing auth changes.
 type Hook interface {
 	// OnAuthRegistered fires when a new auth is registered.
@@ -123,8 +144,23 @@ type Manager struct {
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
 
+	// breakers guards credentials and provider+model pairs; nil disables circuit breaking.
+	breakers *circuitbreaker.Manager
+
+	// affinity pins client sessions to the auth that served them; nil disables session affinity.
+	affinity *sessionAffinity
+
+	// concurrency caps the requests in flight per auth and queues requests while all are saturated.
+	concurrency *concurrencyLimiter
+
+	// executionHook wraps provider calls with host middleware; nil disables it.
+	executionHook ExecutionHook
+
 	// Auto refresh state
 	refreshCancel context.CancelFunc
+
+	// probeCancel stops the background health probe loop.
+	probeCancel context.CancelFunc
 }
 
 // NewManager constructs a manager with optional custom selector and hook.
@@ -142,6 +178,7 @@ func NewManager(store Store, selector Selector, hook Hook) *Manager {
 		hook:            hook,
 		auths:           make(map[string]*Auth),
 		providerOffsets: make(map[string]int),
+		concurrency:     newConcurrencyLimiter(),
 	}
 }
 
@@ -386,7 +423,7 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNext(ctx, provider, routeModel, opts, tried)
+		auth, executor, errPick := m.acquireNext(ctx, provider, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -406,8 +443,17 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		execReq := req
 		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
 		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
-		resp, errExec := executor.Execute(execCtx, auth, execReq, opts)
-		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
+		execOpts := opts
+		trace := m.beginExecution(execCtx, auth, &execReq, &execOpts)
+		execCtx, rateLimits := withRateLimitReport(execCtx)
+		started := time.Now()
+		resp, errExec := func() (cliproxyexecutor.Response, error) {
+			defer m.concurrency.release(auth.ID)
+			defer m.releaseSelectionOnPanic(auth.ID)
+			return executor.Execute(execCtx, auth, execReq, execOpts)
+		}()
+		trace.Finish(execCtx, resp, errExec)
+		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil, TTFB: time.Since(started), RateLimit: rateLimits.snapshot()}
 		if errExec != nil {
 			result.Error = &Error{Message: errExec.Error()}
 			var se cliproxyexecutor.StatusError
@@ -434,7 +480,7 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNext(ctx, provider, routeModel, opts, tried)
+		auth, executor, errPick := m.acquireNext(ctx, provider, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -454,8 +500,17 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 		execReq := req
 		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
 		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
-		resp, errExec := executor.CountTokens(execCtx, auth, execReq, opts)
-		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
+		execOpts := opts
+		trace := m.beginExecution(execCtx, auth, &execReq, &execOpts)
+		execCtx, rateLimits := withRateLimitReport(execCtx)
+		started := time.Now()
+		resp, errExec := func() (cliproxyexecutor.Response, error) {
+			defer m.concurrency.release(auth.ID)
+			defer m.releaseSelectionOnPanic(auth.ID)
+			return executor.CountTokens(execCtx, auth, execReq, execOpts)
+		}()
+		trace.Finish(execCtx, resp, errExec)
+		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil, TTFB: time.Since(started), RateLimit: rateLimits.snapshot()}
 		if errExec != nil {
 			result.Error = &Error{Message: errExec.Error()}
 			var se cliproxyexecutor.StatusError
@@ -482,7 +537,7 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNext(ctx, provider, routeModel, opts, tried)
+		auth, executor, errPick := m.acquireNext(ctx, provider, routeModel, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return nil, lastErr
@@ -502,14 +557,31 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		execReq := req
 		execReq.Model, execReq.Metadata = rewriteModelForAuth(routeModel, req.Metadata, auth)
 		execReq.Model, execReq.Metadata = m.applyOAuthModelMapping(auth, execReq.Model, execReq.Metadata)
-		chunks, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
+		execOpts := opts
+		trace := m.beginExecution(execCtx, auth, &execReq, &execOpts)
+		execCtx, rateLimits := withRateLimitReport(execCtx)
+		started := time.Now()
+		chunks, errStream := func() (chunks <-chan cliproxyexecutor.StreamChunk, err error) {
+			// The stream goroutine takes over the slot; release it here on error or panic only.
+			handedOff := false
+			defer func() {
+				if !handedOff {
+					m.concurrency.release(auth.ID)
+				}
+			}()
+			defer m.releaseSelectionOnPanic(auth.ID)
+			chunks, err = executor.ExecuteStream(execCtx, auth, execReq, execOpts)
+			handedOff = err == nil
+			return chunks, err
+		}()
 		if errStream != nil {
+			trace.Finish(execCtx, cliproxyexecutor.Response{}, errStream)
 			rerr := &Error{Message: errStream.Error()}
 			var se cliproxyexecutor.StatusError
 			if errors.As(errStream, &se) && se != nil {
 				rerr.HTTPStatus = se.StatusCode()
 			}
-			result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: false, Error: rerr}
+			result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: false, Error: rerr, TTFB: time.Since(started), RateLimit: rateLimits.snapshot()}
 			result.RetryAfter = retryAfterFromError(errStream)
 			m.MarkResult(execCtx, result)
 			lastErr = errStream
@@ -518,22 +590,54 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		out := make(chan cliproxyexecutor.StreamChunk)
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
 			defer close(out)
+			defer m.concurrency.release(streamAuth.ID)
 			var failed bool
+			var streamErr error
+			var ttfb time.Duration
+			canceled := false
 			for chunk := range streamChunks {
+				if ttfb == 0 {
+					ttfb = time.Since(started)
+				}
+				trace.OnStreamChunk(streamCtx, chunk)
 				if chunk.Err != nil && !failed {
 					failed = true
+					streamErr = chunk.Err
 					rerr := &Error{Message: chunk.Err.Error()}
 					var se cliproxyexecutor.StatusError
 					if errors.As(chunk.Err, &se) && se != nil {
 						rerr.HTTPStatus = se.StatusCode()
 					}
-					m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: false, Error: rerr})
+					m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: false, Error: rerr, TTFB: ttfb, RateLimit: rateLimits.snapshot()})
+				}
+				select {
+				case out <- chunk:
+				case <-streamCtx.Done():
+					canceled = true
+				}
+				if canceled {
+					break
+				}
+			}
+			if canceled {
+				// The caller went away: drain the executor so it can finish, and leave the auth
+				// state alone since the upstream did not fail. Breaker admissions are released.
+				go func() {
+					for range streamChunks {
+					}
+				}()
+				m.recordBreakerResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Error: &Error{Message: streamCtx.Err().Error()}})
+				if !failed {
+					// No result was recorded, so the selector still counts the request in flight.
+					m.releaseSelection(streamAuth.ID)
 				}
-				out <- chunk
+				trace.Finish(streamCtx, cliproxyexecutor.Response{}, streamCtx.Err())
+				return
 			}
 			if !failed {
-				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true})
+				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true, TTFB: ttfb, RateLimit: rateLimits.snapshot()})
 			}
+			trace.Finish(streamCtx, cliproxyexecutor.Response{}, streamErr)
 		}(execCtx, auth.Clone(), provider, chunks)
 		return out, nil
 	}
@@ -821,6 +925,9 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 					backoffLevel := state.Quota.BackoffLevel
 					if result.RetryAfter != nil {
 						next = now.Add(*result.RetryAfter)
+					} else if until, exhausted := result.RateLimit.ExhaustedUntil(now); exhausted {
+						// The rate limit headers name the window reset; prefer it over blind backoff.
+						next = until
 					} else {
 						cooldown, nextLevel := nextQuotaCooldown(backoffLevel)
 						if cooldown > 0 {
@@ -852,11 +959,21 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				applyAuthFailureState(auth, result.Error, result.RetryAfter, now)
 			}
 		}
+		applyRateLimit(auth, result.Model, result.RateLimit)
 
 		_ = m.persist(ctx, auth)
 	}
 	m.mu.Unlock()
 
+	m.recordBreakerResult(ctx, result)
+
+	m.mu.RLock()
+	selector := m.selector
+	m.mu.RUnlock()
+	if observer, ok := selector.(SelectionObserver); ok && observer != nil {
+		observer.OnResult(result)
+	}
+
 	if clearModelQuota && result.Model != "" {
 		registry.GetGlobalRegistry().ClearModelQuotaExceeded(result.AuthID, result.Model)
 	}
@@ -872,6 +989,25 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	m.hook.OnResult(ctx, result)
 }
 
+// releaseSelection tells the selection observer that a request on authID ended without a result.
+func (m *Manager) releaseSelection(authID string) {
+	m.mu.RLock()
+	selector := m.selector
+	m.mu.RUnlock()
+	if observer, ok := selector.(SelectionObserver); ok && observer != nil {
+		observer.OnReleased(authID)
+	}
+}
+
+// releaseSelectionOnPanic releases the selection of a request whose executor panicked before a
+// result was recorded, then re-raises the panic. It must be deferred directly.
+func (m *Manager) releaseSelectionOnPanic(authID string) {
+	if r := recover(); r != nil {
+		m.releaseSelection(authID)
+		panic(r)
+	}
+}
+
 func ensureModelState(auth *Auth, model string) *ModelState {
 	if auth == nil || model == "" {
 		return nil
@@ -986,6 +1122,7 @@ func clearAuthStateOnSuccess(auth *Auth, now time.Time) {
 		return
 	}
 	auth.Unavailable = false
+	auth.Quarantined = false
 	auth.Status = StatusActive
 	auth.StatusMessage = ""
 	auth.Quota.Exceeded = false
@@ -1150,33 +1287,108 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 		m.mu.RUnlock()
 		return nil, nil, &Error{Code: "executor_not_found", Message: "executor not registered"}
 	}
-	candidates := make([]*Auth, 0, len(m.auths))
+	breakers := m.breakers
 	modelKey := strings.TrimSpace(model)
+	if modelKey != "" && !breakerReady(breakers, modelBreakerName(provider, modelKey)) {
+		m.mu.RUnlock()
+		return nil, nil, newCircuitOpenError(provider, modelKey)
+	}
+	candidates := make([]*Auth, 0, len(m.auths))
 	registryRef := registry.GetGlobalRegistry()
+	breakerSkipped := 0
+	saturatedSkipped := 0
+	limiter := m.concurrency
+	pinnedIndex, _ := opts.Metadata[PinnedAuthIndexMetadataKey].(string)
+	now := time.Now()
 	for _, candidate := range m.auths {
 		if candidate.Provider != provider || candidate.Disabled {
 			continue
 		}
+		if pinnedIndex != "" && candidate.Index != pinnedIndex {
+			continue
+		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
 		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
 			continue
 		}
+		if !breakerReady(breakers, authBreakerName(candidate.ID)) {
+			breakerSkipped++
+			continue
+		}
+		if limiter.saturated(candidate) {
+			// Only auths that could serve the request once a slot frees up are worth queueing for.
+			if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); !blocked {
+				saturatedSkipped++
+			}
+			continue
+		}
 		candidates = append(candidates, candidate)
 	}
-	if len(candidates) == 0 {
-		m.mu.RUnlock()
-		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
+	var selected *Auth
+	affinityKey := ""
+	if m.affinity != nil {
+		affinityKey = sessionAffinityKey(provider, model, opts)
+	}
+	if affinityKey != "" {
+		if pinned := m.affinity.lookup(affinityKey, now); pinned != "" {
+			if candidate := stickyCandidate(candidates, pinned, modelKey, now); candidate != nil && limiter.tryAcquire(candidate) {
+				if admitBreakers(breakers, provider, modelKey, candidate.ID) {
+					selected = candidate
+				} else {
+					limiter.release(candidate.ID)
+				}
+			}
+		}
 	}
-	selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
-	if errPick != nil {
-		m.mu.RUnlock()
-		return nil, nil, errPick
+	for selected == nil {
+		if len(candidates) == 0 {
+			m.mu.RUnlock()
+			if saturatedSkipped > 0 {
+				return nil, nil, newConcurrencySaturatedError(provider)
+			}
+			if breakerSkipped > 0 {
+				return nil, nil, newCircuitOpenError(provider, modelKey)
+			}
+			return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
+		}
+		picked, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
+		if errPick != nil {
+			m.mu.RUnlock()
+			if saturatedSkipped > 0 {
+				return nil, nil, newConcurrencySaturatedError(provider)
+			}
+			return nil, nil, errPick
+		}
+		if picked == nil {
+			m.mu.RUnlock()
+			return nil, nil, &Error{Code: "auth_not_found", Message: "selector returned no auth"}
+		}
+		if !limiter.tryAcquire(picked) {
+			// Another request took the last free slot; drop the candidate and pick again.
+			saturatedSkipped++
+		} else if admitBreakers(breakers, provider, modelKey, picked.ID) {
+			selected = picked
+			break
+		} else {
+			// Another request claimed the last half-open slot; drop the candidate and pick again.
+			limiter.release(picked.ID)
+			breakerSkipped++
+		}
+		remaining := candidates[:0:0]
+		for _, candidate := range candidates {
+			if candidate.ID != picked.ID {
+				remaining = append(remaining, candidate)
+			}
+		}
+		candidates = remaining
 	}
-	if selected == nil {
-		m.mu.RUnlock()
-		return nil, nil, &Error{Code: "auth_not_found", Message: "selector returned no auth"}
+	if affinityKey != "" {
+		m.affinity.bind(affinityKey, selected.ID, now)
+	}
+	if observer, ok := m.selector.(SelectionObserver); ok && observer != nil {
+		observer.OnSelected(provider, model, selected)
 	}
 	authCopy := selected.Clone()
 	m.mu.RUnlock()
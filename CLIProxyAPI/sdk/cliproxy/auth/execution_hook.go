@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ExecutionHook wraps every provider call issued by the manager.
+// The cliproxy builder adapts pipeline.Hook values to this interface, since the pipeline
+// package depends on auth and cannot be imported here.
+type ExecutionHook interface {
+	// BeginExecution runs once an auth is selected, before the executor is invoked.
+	// It may rewrite the request and options in place and returns the trace that receives
+	// stream chunks and the outcome of this call.
+	BeginExecution(ctx context.Context, auth *Auth, req *cliproxyexecutor.Request, opts *cliproxyexecutor.Options) ExecutionTrace
+}
+
+// ExecutionTrace observes a single provider call started by an ExecutionHook.
+type ExecutionTrace interface {
+	// OnStreamChunk fires for each chunk of a streaming call before it is forwarded. The chunk
+	// is passed by value, so the trace observes it but cannot change what the client receives.
+	OnStreamChunk(ctx context.Context, chunk cliproxyexecutor.StreamChunk)
+	// Finish fires once the call completes. Streaming calls report the first chunk error, if any.
+	Finish(ctx context.Context, resp cliproxyexecutor.Response, err error)
+}
+
+type noopExecutionTrace struct{}
+
+func (noopExecutionTrace) OnStreamChunk(context.Context, cliproxyexecutor.StreamChunk) {}
+
+func (noopExecutionTrace) Finish(context.Context, cliproxyexecutor.Response, error) {}
+
+// SetExecutionHook installs the hook that wraps provider calls; nil removes it.
+func (m *Manager) SetExecutionHook(hook ExecutionHook) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.executionHook = hook
+	m.mu.Unlock()
+}
+
+// beginExecution runs the execution hook for a selected auth and returns the trace for the call.
+func (m *Manager) beginExecution(ctx context.Context, auth *Auth, req *cliproxyexecutor.Request, opts *cliproxyexecutor.Options) ExecutionTrace {
+	m.mu.RLock()
+	hook := m.executionHook
+	m.mu.RUnlock()
+	if hook == nil {
+		return noopExecutionTrace{}
+	}
+	if trace := hook.BeginExecution(ctx, auth, req, opts); trace != nil {
+		return trace
+	}
+	return noopExecutionTrace{}
+}
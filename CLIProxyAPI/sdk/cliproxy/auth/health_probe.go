@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHealthProbeInterval = 10 * time.Minute
+	defaultHealthProbeTimeout  = 30 * time.Second
+	// healthProbeParallelism bounds the probes in flight during one round.
+	healthProbeParallelism = 4
+)
+
+// HealthProbeSettings configures the background credential health probes.
+type HealthProbeSettings struct {
+	// Interval is the time between probe rounds; zero uses the default.
+	Interval time.Duration
+	// Timeout bounds a single probe; zero uses the default.
+	Timeout time.Duration
+	// Models maps a provider to the model probed for its auths. Providers without an entry use
+	// the first model registered for the auth.
+	Models map[string]string
+}
+
+// ProbeResult describes the outcome of a single health probe.
+type ProbeResult struct {
+	AuthID     string
+	Model      string
+	HTTPStatus int
+	Err        error
+	// Quarantined reports whether the auth is quarantined after the probe.
+	Quarantined bool
+}
+
+// StartHealthProbes launches a background loop that sends a minimal one token request through
+// each enabled auth at the configured interval. Auths rejected with 401 or 403 are quarantined
+// and skipped during selection; a later successful probe restores them. Calling it again
+// replaces the running loop.
+func (m *Manager) StartHealthProbes(parent context.Context, settings HealthProbeSettings) {
+	if m == nil {
+		return
+	}
+	if settings.Interval <= 0 {
+		settings.Interval = defaultHealthProbeInterval
+	}
+	if settings.Timeout <= 0 {
+		settings.Timeout = defaultHealthProbeTimeout
+	}
+	ctx, cancel := context.WithCancel(parent)
+	m.mu.Lock()
+	if m.probeCancel != nil {
+		m.probeCancel()
+	}
+	m.probeCancel = cancel
+	m.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(settings.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.probeAll(ctx, settings)
+			}
+		}
+	}()
+}
+
+// StopHealthProbes cancels the background probe loop, if running.
+func (m *Manager) StopHealthProbes() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	if m.probeCancel != nil {
+		m.probeCancel()
+		m.probeCancel = nil
+	}
+	m.mu.Unlock()
+}
+
+func (m *Manager) probeAll(ctx context.Context, settings HealthProbeSettings) {
+	sem := make(chan struct{}, healthProbeParallelism)
+	var wg sync.WaitGroup
+	now := time.Now()
+	for _, auth := range m.snapshotAuths() {
+		if auth.Disabled || m.executorFor(auth.Provider) == nil {
+			continue
+		}
+		// Credentials outside their active windows must not send traffic, probes included.
+		if _, outside := scheduleBlock(auth, now); outside {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			probeCtx, cancel := context.WithTimeout(ctx, settings.Timeout)
+			defer cancel()
+			result := m.ProbeAuth(probeCtx, id, settings.Models)
+			if result.Err != nil && !errors.Is(result.Err, context.Canceled) {
+				log.Debugf("health probe for %s (model %s) failed: %v", id, result.Model, result.Err)
+			}
+		}(auth.ID)
+	}
+	wg.Wait()
+}
+
+// ProbeAuth sends a minimal one token request through the auth and updates its quarantine state.
+// models optionally maps a provider to the model to probe. Probes are skipped while the auth is
+// at max concurrency, since live traffic already exercises it.
+func (m *Manager) ProbeAuth(ctx context.Context, id string, models map[string]string) ProbeResult {
+	result := ProbeResult{AuthID: id}
+	m.mu.RLock()
+	auth := m.auths[id]
+	var executor ProviderExecutor
+	if auth != nil {
+		executor = m.executors[auth.Provider]
+		auth = auth.Clone()
+	}
+	m.mu.RUnlock()
+	if auth == nil {
+		result.Err = errAuthNotFound()
+		return result
+	}
+	result.Quarantined = auth.Quarantined
+	if executor == nil {
+		result.Err = &Error{Code: "executor_not_found", Message: "executor not registered"}
+		return result
+	}
+	result.Model = probeModelFor(auth, models)
+	if result.Model == "" {
+		result.Err = &Error{Code: "model_not_found", Message: "no model registered for auth"}
+		return result
+	}
+	if !m.concurrency.tryAcquire(auth) {
+		result.Err = newConcurrencySaturatedError(auth.Provider)
+		return result
+	}
+	defer m.concurrency.release(auth.ID)
+
+	// Probes are synthetic traffic and must not show up in usage statistics or costs.
+	execCtx := usage.WithoutRecording(ctx)
+	if rt := m.roundTripperFor(auth); rt != nil {
+		execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+		execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+	}
+	model, metadata := rewriteModelForAuth(result.Model, nil, auth)
+	model, metadata = m.applyOAuthModelMapping(auth, model, metadata)
+	payload := []byte(fmt.Sprintf(`{"model":%q,"messages":[{"role":"user","content":"ping"}],"max_tokens":1,"stream":false}`, model))
+	req := cliproxyexecutor.Request{Model: model, Payload: payload, Metadata: metadata}
+	opts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatOpenAI, OriginalRequest: payload}
+	_, result.Err = executor.Execute(execCtx, auth, req, opts)
+	if result.Err != nil {
+		var se cliproxyexecutor.StatusError
+		if errors.As(result.Err, &se) && se != nil {
+			result.HTTPStatus = se.StatusCode()
+		}
+	}
+
+	switch {
+	case result.HTTPStatus == http.StatusUnauthorized || result.HTTPStatus == http.StatusForbidden:
+		result.Quarantined = true
+		message := fmt.Sprintf("quarantined: health probe rejected with %d", result.HTTPStatus)
+		_, _ = m.Modify(ctx, id, func(a *Auth) {
+			a.Quarantined = true
+			a.Status = StatusError
+			a.StatusMessage = message
+			a.LastError = &Error{Code: "health_probe_failed", Message: result.Err.Error(), HTTPStatus: result.HTTPStatus}
+		})
+		if !auth.Quarantined {
+			log.Warnf("auth %s (%s) %s", id, auth.Provider, message)
+		}
+	case result.Err == nil && auth.Quarantined:
+		result.Quarantined = false
+		_, _ = m.Modify(ctx, id, func(a *Auth) {
+			a.Quarantined = false
+			if a.Status == StatusError {
+				a.Status = StatusActive
+			}
+			a.StatusMessage = ""
+			a.LastError = nil
+		})
+		log.Infof("auth %s (%s) passed its health probe and was restored", id, auth.Provider)
+	}
+	return result
+}
+
+// probeModelFor returns the configured probe model for the auth's provider, or the first model
+// registered for the auth.
+func probeModelFor(auth *Auth, models map[string]string) string {
+	if model := strings.TrimSpace(models[strings.ToLower(auth.Provider)]); model != "" {
+		return model
+	}
+	registered := registry.GetGlobalRegistry().GetModelsForClient(auth.ID)
+	ids := make([]string, 0, len(registered))
+	for _, info := range registered {
+		if info != nil && info.ID != "" {
+			ids = append(ids, info.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+	sort.Strings(ids)
+	return ids[0]
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// probeExecutor fails requests on auths listed in rejected with the configured status.
+type probeExecutor struct {
+	stubExecutor
+	mu       sync.Mutex
+	rejected map[string]int
+	models   []string
+}
+
+func (e *probeExecutor) Execute(_ context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.models = append(e.models, req.Model)
+	if status := e.rejected[auth.ID]; status != 0 {
+		return cliproxyexecutor.Response{}, &Error{Message: "rejected", HTTPStatus: status}
+	}
+	return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+}
+
+func TestProbeAuthQuarantinesAndRestores(t *testing.T) {
+	executor := &probeExecutor{stubExecutor: stubExecutor{provider: "hp"}, rejected: map[string]int{"a": http.StatusUnauthorized}}
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	for _, id := range []string{"a", "b"} {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "hp", Status: StatusActive}); err != nil {
+			t.Fatalf("Register(%s) error = %v", id, err)
+		}
+	}
+	models := map[string]string{"hp": "probe-model"}
+
+	result := manager.ProbeAuth(context.Background(), "a", models)
+	if !result.Quarantined || result.HTTPStatus != http.StatusUnauthorized || result.Model != "probe-model" {
+		t.Fatalf("ProbeAuth() = %+v, want quarantined after 401", result)
+	}
+	a, _ := manager.GetByID("a")
+	if !a.Quarantined || a.Status != StatusError || a.LastError == nil || a.LastError.HTTPStatus != http.StatusUnauthorized {
+		t.Fatalf("auth a after failed probe = %+v", a)
+	}
+	resp, err := manager.Execute(context.Background(), []string{"hp"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != "b" {
+		t.Fatalf("Execute() = %q, %v; want quarantined auth a skipped", resp.Payload, err)
+	}
+
+	// Transient failures leave the quarantine state alone.
+	executor.mu.Lock()
+	executor.rejected["a"] = http.StatusBadGateway
+	executor.mu.Unlock()
+	if result = manager.ProbeAuth(context.Background(), "a", models); !result.Quarantined {
+		t.Fatalf("ProbeAuth() after 502 = %+v, want still quarantined", result)
+	}
+
+	executor.mu.Lock()
+	delete(executor.rejected, "a")
+	executor.mu.Unlock()
+	if result = manager.ProbeAuth(context.Background(), "a", models); result.Quarantined || result.Err != nil {
+		t.Fatalf("ProbeAuth() after recovery = %+v", result)
+	}
+	a, _ = manager.GetByID("a")
+	if a.Quarantined || a.Status != StatusActive || a.LastError != nil {
+		t.Fatalf("auth a after recovery = %+v", a)
+	}
+	if resp, err = manager.Execute(context.Background(), []string{"hp"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{}); err != nil || string(resp.Payload) != "a" {
+		t.Fatalf("Execute() = %q, %v; want restored auth a selected", resp.Payload, err)
+	}
+}
+
+func TestProbeAuthWithoutModel(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(&probeExecutor{stubExecutor: stubExecutor{provider: "hp"}})
+	if _, err := manager.Register(context.Background(), &Auth{ID: "unregistered-models", Provider: "hp"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if result := manager.ProbeAuth(context.Background(), "unregistered-models", nil); result.Err == nil || result.Quarantined {
+		t.Fatalf("ProbeAuth() = %+v, want model_not_found without quarantine", result)
+	}
+}
+
+func TestProbeAllSkipsAuthsOutsideSchedule(t *testing.T) {
+	executor := &probeExecutor{stubExecutor: stubExecutor{provider: "hp"}}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auths := []*Auth{
+		{ID: "open", Provider: "hp"},
+		{ID: "closed", Provider: "hp", Attributes: map[string]string{"schedule": "never daily"}},
+		{ID: "invalid", Provider: "hp", Attributes: map[string]string{"schedule": "whenever"}},
+	}
+	for _, auth := range auths {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, err)
+		}
+	}
+	manager.probeAll(context.Background(), HealthProbeSettings{Timeout: time.Second, Models: map[string]string{"hp": "probe-model"}})
+	executor.mu.Lock()
+	defer executor.mu.Unlock()
+	if len(executor.models) != 1 {
+		t.Fatalf("probeAll() sent %d probes, want only the auth inside its schedule", len(executor.models))
+	}
+}
+
+// usageExecutor publishes a usage record for every call, as the provider executors do.
+type usageExecutor struct{ stubExecutor }
+
+func (e usageExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	usage.PublishRecord(ctx, usage.Record{Provider: e.provider, Model: req.Model, AuthID: auth.ID})
+	return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+}
+
+// usageRecorder forwards the records of one auth to a channel.
+type usageRecorder struct {
+	authID  string
+	records chan usage.Record
+}
+
+func (r usageRecorder) HandleUsage(_ context.Context, record usage.Record) {
+	if record.AuthID == r.authID {
+		r.records <- record
+	}
+}
+
+func TestProbeAuthPublishesNoUsage(t *testing.T) {
+	recorder := usageRecorder{authID: "usage-probe", records: make(chan usage.Record, 4)}
+	usage.RegisterPlugin(recorder)
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(usageExecutor{stubExecutor{provider: "hp"}})
+	if _, err := manager.Register(context.Background(), &Auth{ID: "usage-probe", Provider: "hp"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if result := manager.ProbeAuth(context.Background(), "usage-probe", map[string]string{"hp": "probe-model"}); result.Err != nil {
+		t.Fatalf("ProbeAuth() error = %v", result.Err)
+	}
+	if _, err := manager.Execute(context.Background(), []string{"hp"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	// Records are delivered in order, so a probe record would arrive before the live one.
+	select {
+	case record := <-recorder.records:
+		if record.Model == "probe-model" {
+			t.Fatalf("first usage record model = %q, want the live request only", record.Model)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("live request published no usage record")
+	}
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+)
+
+// Metadata keys mirroring operator edits, so file backed stores keep them across reloads.
+const (
+	MetadataDisabledKey       = "disabled"
+	MetadataLabelKey          = "label"
+	MetadataPrefixKey         = "prefix"
+	MetadataProxyURLKey       = "proxy_url"
+	MetadataMaxConcurrencyKey = "max_concurrency"
+)
+
+func errAuthNotFound() *Error {
+	return &Error{Code: "auth_not_found", Message: "auth not found", HTTPStatus: http.StatusNotFound}
+}
+
+// Modify applies fn to the auth registered under id, then stores and persists the result.
+// Unlike Update, a failure to persist is returned to the caller.
+func (m *Manager) Modify(ctx context.Context, id string, fn func(*Auth)) (*Auth, error) {
+	m.mu.Lock()
+	current, ok := m.auths[id]
+	if !ok || current == nil {
+		m.mu.Unlock()
+		return nil, errAuthNotFound()
+	}
+	updated := current.Clone()
+	fn(updated)
+	updated.UpdatedAt = time.Now()
+	m.auths[id] = updated.Clone()
+	m.mu.Unlock()
+
+	if err := m.persist(ctx, updated); err != nil {
+		return updated.Clone(), err
+	}
+	m.hook.OnAuthUpdated(ctx, updated.Clone())
+	return updated.Clone(), nil
+}
+
+// ResetCooldown clears the quota and retry cooldowns, the reported rate limits and any health
+// probe quarantine of the auth and all of its models, and closes its credential circuit breaker.
+// A disabled auth stays disabled.
+func (m *Manager) ResetCooldown(ctx context.Context, id string) (*Auth, error) {
+	var models []string
+	updated, err := m.Modify(ctx, id, func(auth *Auth) {
+		now := time.Now()
+		for model, state := range auth.ModelStates {
+			resetModelState(state, now)
+			models = append(models, model)
+		}
+		disabled := auth.Status == StatusDisabled
+		clearAuthStateOnSuccess(auth, now)
+		auth.Quota.RateLimit = nil
+		if disabled {
+			auth.Status = StatusDisabled
+		}
+	})
+	if updated == nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	breakers := m.breakers
+	m.mu.RUnlock()
+	if breakers != nil {
+		if breaker, ok := breakers.Get(authBreakerName(id)); ok {
+			breaker.Reset()
+		}
+	}
+	reg := registry.GetGlobalRegistry()
+	for _, model := range models {
+		reg.ClearModelQuotaExceeded(id, model)
+		reg.ResumeClientModel(id, model)
+	}
+	return updated, err
+}
+
+// RefreshNow refreshes the auth immediately instead of waiting for the auto refresh loop.
+func (m *Manager) RefreshNow(ctx context.Context, id string) (*Auth, error) {
+	m.mu.RLock()
+	auth := m.auths[id]
+	var exec ProviderExecutor
+	if auth != nil {
+		exec = m.executors[auth.Provider]
+	}
+	m.mu.RUnlock()
+	if auth == nil {
+		return nil, errAuthNotFound()
+	}
+	if exec == nil {
+		return auth.Clone(), &Error{Code: "executor_not_found", Message: "executor not registered"}
+	}
+
+	m.refreshAuth(ctx, id)
+
+	updated, ok := m.GetByID(id)
+	if !ok {
+		return nil, errAuthNotFound()
+	}
+	if !updated.LastRefreshedAt.After(auth.LastRefreshedAt) {
+		if updated.LastError != nil {
+			return updated, errors.New(updated.LastError.Message)
+		}
+		return updated, errors.New("refresh did not complete")
+	}
+	return updated, nil
+}
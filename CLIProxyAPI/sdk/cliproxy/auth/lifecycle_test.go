@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+type recordingStore struct {
+	saved []*Auth
+	err   error
+}
+
+func (s *recordingStore) List(context.Context) ([]*Auth, error) { return nil, nil }
+
+func (s *recordingStore) Save(_ context.Context, auth *Auth) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.saved = append(s.saved, auth.Clone())
+	return auth.ID, nil
+}
+
+func (s *recordingStore) Delete(context.Context, string) error { return nil }
+
+type failingRefreshExecutor struct{ stubExecutor }
+
+func (e failingRefreshExecutor) Refresh(context.Context, *Auth) (*Auth, error) {
+	return nil, errors.New("token revoked")
+}
+
+func TestModifyPersistsAndReportsStoreErrors(t *testing.T) {
+	store := &recordingStore{}
+	manager := NewManager(store, nil, nil)
+	if _, err := manager.Register(context.Background(), &Auth{ID: "a", Provider: "stub", Metadata: map[string]any{}}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	updated, err := manager.Modify(context.Background(), "a", func(a *Auth) { a.Disabled = true })
+	if err != nil || !updated.Disabled {
+		t.Fatalf("Modify() = %+v, %v", updated, err)
+	}
+	if last := store.saved[len(store.saved)-1]; !last.Disabled {
+		t.Fatalf("persisted auth not disabled: %+v", last)
+	}
+
+	store.err = errors.New("disk full")
+	if _, err = manager.Modify(context.Background(), "a", func(a *Auth) { a.Label = "x" }); err == nil {
+		t.Fatal("Modify() error = nil, want store error")
+	}
+	if _, err = manager.Modify(context.Background(), "missing", func(*Auth) {}); err == nil {
+		t.Fatal("Modify(missing) error = nil")
+	}
+}
+
+func TestResetCooldownMakesAuthSelectableAgain(t *testing.T) {
+	manager, breakers := newBreakerTestManager(t, "a")
+	manager.MarkResult(context.Background(), Result{
+		AuthID:   "a",
+		Provider: "stub",
+		Model:    "m",
+		Error:    &Error{Message: "rate limited", HTTPStatus: http.StatusTooManyRequests},
+	})
+	breakers.GetOrCreate(authBreakerName("a")).Record(false)
+	if _, _, err := manager.pickNext(context.Background(), "stub", "", cliproxyexecutor.Options{}, map[string]struct{}{}); err == nil {
+		t.Fatal("pickNext() before reset succeeded, want open breaker")
+	}
+
+	updated, err := manager.ResetCooldown(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("ResetCooldown() error = %v", err)
+	}
+	state := updated.ModelStates["m"]
+	if updated.Status != StatusActive || updated.Unavailable || state.Unavailable || state.Quota.Exceeded || !state.NextRetryAfter.IsZero() {
+		t.Fatalf("ResetCooldown() left cooldown state: %+v %+v", updated, state)
+	}
+	if _, _, err = manager.pickNext(context.Background(), "stub", "", cliproxyexecutor.Options{}, map[string]struct{}{}); err != nil {
+		t.Fatalf("pickNext() after reset error = %v", err)
+	}
+}
+
+func TestRefreshNow(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(stubExecutor{provider: "stub"})
+	manager.RegisterExecutor(failingRefreshExecutor{stubExecutor{provider: "broken"}})
+	for _, auth := range []*Auth{{ID: "ok", Provider: "stub"}, {ID: "bad", Provider: "broken"}} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, err)
+		}
+	}
+
+	before := time.Now()
+	updated, err := manager.RefreshNow(context.Background(), "ok")
+	if err != nil || updated.LastRefreshedAt.Before(before) {
+		t.Fatalf("RefreshNow(ok) = %+v, %v", updated, err)
+	}
+	if _, err = manager.RefreshNow(context.Background(), "bad"); err == nil || err.Error() != "token revoked" {
+		t.Fatalf("RefreshNow(bad) error = %v, want token revoked", err)
+	}
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimitWindow is one provider reported rate limit window. Remaining and UsedPercent are nil
+// when the provider does not report them. Windows are scoped to the model of the request unless
+// AccountWide is set, as for subscription usage windows shared by every model.
+type RateLimitWindow struct {
+	Limit       int64     `json:"limit,omitempty"`
+	Remaining   *int64    `json:"remaining,omitempty"`
+	UsedPercent *float64  `json:"used_percent,omitempty"`
+	ResetAt     time.Time `json:"reset_at"`
+	AccountWide bool      `json:"account_wide,omitempty"`
+}
+
+// RateLimitThreshold decides when a reported window counts as exhausted. The zero value skips a
+// credential only once a window has nothing left.
+type RateLimitThreshold struct {
+	// MinRemaining skips a credential once a window reports this many or fewer remaining units.
+	MinRemaining int64
+	// MaxUsedPercent skips a credential once a window reports at least this share used; zero
+	// means 100. Windows reporting a limit and a remaining count but no percentage derive it.
+	MaxUsedPercent float64
+}
+
+var rateLimitThreshold atomic.Pointer[RateLimitThreshold]
+
+// SetRateLimitThreshold sets the headroom kept on provider reported rate limit windows globally.
+func SetRateLimitThreshold(threshold RateLimitThreshold) {
+	if threshold.MinRemaining < 0 {
+		threshold.MinRemaining = 0
+	}
+	if threshold.MaxUsedPercent <= 0 || threshold.MaxUsedPercent > 100 {
+		threshold.MaxUsedPercent = 100
+	}
+	rateLimitThreshold.Store(&threshold)
+}
+
+func currentRateLimitThreshold() RateLimitThreshold {
+	if threshold := rateLimitThreshold.Load(); threshold != nil {
+		return *threshold
+	}
+	return RateLimitThreshold{MaxUsedPercent: 100}
+}
+
+// exhausted reports whether the window is at or past threshold until its reset.
+func (w RateLimitWindow) exhausted(now time.Time, threshold RateLimitThreshold) bool {
+	if !w.ResetAt.After(now) {
+		return false
+	}
+	if w.Remaining != nil && *w.Remaining <= threshold.MinRemaining {
+		return true
+	}
+	used := w.UsedPercent
+	if used == nil && w.Remaining != nil && w.Limit > 0 {
+		derived := float64(w.Limit-*w.Remaining) / float64(w.Limit) * 100
+		used = &derived
+	}
+	return used != nil && *used >= threshold.MaxUsedPercent
+}
+
+// RateLimitState is the latest rate limit snapshot taken from upstream response headers, keyed
+// by window name (for example "requests", "tokens" or "primary"). Snapshots are replaced as a
+// whole and never mutated once stored.
+type RateLimitState struct {
+	Windows   map[string]RateLimitWindow `json:"windows"`
+	UpdatedAt time.Time                  `json:"updated_at"`
+}
+
+// ExhaustedUntil returns the latest reset time among windows at or past the configured
+// threshold, or false when every window still has capacity.
+func (s *RateLimitState) ExhaustedUntil(now time.Time) (time.Time, bool) {
+	if s == nil {
+		return time.Time{}, false
+	}
+	threshold := currentRateLimitThreshold()
+	var until time.Time
+	for _, window := range s.Windows {
+		if window.exhausted(now, threshold) && window.ResetAt.After(until) {
+			until = window.ResetAt
+		}
+	}
+	return until, !until.IsZero()
+}
+
+// rateLimitBlock reports whether the auth should be skipped for model because the provider
+// reported an exhausted window, either on the model itself or on an account wide window.
+func rateLimitBlock(auth *Auth, model string, now time.Time) (time.Time, bool) {
+	until, blocked := auth.Quota.RateLimit.ExhaustedUntil(now)
+	if model != "" {
+		if state, ok := auth.ModelStates[model]; ok && state != nil {
+			if modelUntil, modelBlocked := state.Quota.RateLimit.ExhaustedUntil(now); modelBlocked && modelUntil.After(until) {
+				until, blocked = modelUntil, true
+			}
+		}
+	}
+	return until, blocked
+}
+
+type rateLimitReportKey struct{}
+
+// rateLimitReport collects the snapshot reported by an executor during one attempt.
+type rateLimitReport struct {
+	mu    sync.Mutex
+	state *RateLimitState
+}
+
+func withRateLimitReport(ctx context.Context) (context.Context, *rateLimitReport) {
+	report := &rateLimitReport{}
+	return context.WithValue(ctx, rateLimitReportKey{}, report), report
+}
+
+func (r *rateLimitReport) snapshot() *RateLimitState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state
+}
+
+// ReportRateLimit records the rate limit state parsed from an upstream response. Executors call
+// it with the execution context; the manager stores the snapshot on the auth and model state
+// when the attempt's result is recorded. Windows reported earlier in the same attempt are kept
+// unless overwritten.
+func ReportRateLimit(ctx context.Context, state *RateLimitState) {
+	if ctx == nil || state == nil || len(state.Windows) == 0 {
+		return
+	}
+	report, ok := ctx.Value(rateLimitReportKey{}).(*rateLimitReport)
+	if !ok || report == nil {
+		return
+	}
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	merged := &RateLimitState{Windows: make(map[string]RateLimitWindow), UpdatedAt: state.UpdatedAt}
+	if report.state != nil {
+		for name, window := range report.state.Windows {
+			merged.Windows[name] = window
+		}
+	}
+	for name, window := range state.Windows {
+		merged.Windows[name] = window
+	}
+	report.state = merged
+}
+
+// applyRateLimit stores a reported snapshot. Model scoped windows go to the model state so an
+// exhausted model does not block the others on the same credential; account wide windows, and
+// every window when the model is unknown, go to the auth.
+func applyRateLimit(auth *Auth, model string, state *RateLimitState) {
+	if auth == nil || state == nil {
+		return
+	}
+	if model == "" {
+		auth.Quota.RateLimit = state
+		return
+	}
+	account := &RateLimitState{Windows: make(map[string]RateLimitWindow), UpdatedAt: state.UpdatedAt}
+	scoped := &RateLimitState{Windows: make(map[string]RateLimitWindow), UpdatedAt: state.UpdatedAt}
+	for name, window := range state.Windows {
+		if window.AccountWide {
+			account.Windows[name] = window
+		} else {
+			scoped.Windows[name] = window
+		}
+	}
+	if len(account.Windows) > 0 {
+		auth.Quota.RateLimit = account
+	}
+	if len(scoped.Windows) > 0 {
+		if modelState := ensureModelState(auth, model); modelState != nil {
+			modelState.Quota.RateLimit = scoped
+		}
+	}
+}
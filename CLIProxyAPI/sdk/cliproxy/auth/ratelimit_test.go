@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// rateLimitExecutor reports the remaining requests configured per auth on every call.
+type rateLimitExecutor struct {
+	stubExecutor
+	remaining map[string]int64
+	calls     map[string]int
+}
+
+func (e *rateLimitExecutor) Execute(ctx context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.calls[auth.ID]++
+	remaining := e.remaining[auth.ID]
+	ReportRateLimit(ctx, &RateLimitState{
+		Windows:   map[string]RateLimitWindow{"requests": {Limit: 50, Remaining: &remaining, ResetAt: time.Now().Add(time.Hour)}},
+		UpdatedAt: time.Now(),
+	})
+	return cliproxyexecutor.Response{Payload: []byte(auth.ID)}, nil
+}
+
+func TestExhaustedRateLimitSkipsCredential(t *testing.T) {
+	executor := &rateLimitExecutor{
+		stubExecutor: stubExecutor{provider: "rl"},
+		remaining:    map[string]int64{"a": 0, "b": 10},
+		calls:        map[string]int{},
+	}
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	for _, id := range []string{"a", "b"} {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "rl"}); err != nil {
+			t.Fatalf("Register(%s) error = %v", id, err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := manager.Execute(context.Background(), []string{"rl"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{}); err != nil {
+			t.Fatalf("Execute() #%d error = %v", i, err)
+		}
+	}
+	if executor.calls["a"] != 1 || executor.calls["b"] != 2 {
+		t.Fatalf("calls = %v, want a once then b", executor.calls)
+	}
+	a, _ := manager.GetByID("a")
+	window := a.Quota.RateLimit.Windows["requests"]
+	if window.Remaining == nil || *window.Remaining != 0 || window.Limit != 50 {
+		t.Fatalf("auth a rate limit = %+v", a.Quota.RateLimit)
+	}
+
+	if _, err := manager.ResetCooldown(context.Background(), "a"); err != nil {
+		t.Fatalf("ResetCooldown() error = %v", err)
+	}
+	if _, err := manager.Execute(context.Background(), []string{"rl"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("Execute() after reset error = %v", err)
+	}
+	if executor.calls["a"] != 2 {
+		t.Fatalf("calls = %v, want a selected again after reset", executor.calls)
+	}
+}
+
+func TestExhaustedModelRateLimitKeepsOtherModels(t *testing.T) {
+	now := time.Now()
+	none, plenty := int64(0), int64(100)
+	auth := &Auth{ID: "a"}
+	applyRateLimit(auth, "opus", &RateLimitState{
+		Windows:   map[string]RateLimitWindow{"tokens": {Remaining: &none, ResetAt: now.Add(time.Minute)}},
+		UpdatedAt: now,
+	})
+	if _, blocked := rateLimitBlock(auth, "opus", now); !blocked {
+		t.Fatal("exhausted model opus should be blocked")
+	}
+	if _, blocked := rateLimitBlock(auth, "sonnet", now); blocked {
+		t.Fatal("an exhausted opus window blocked sonnet")
+	}
+
+	// Account wide windows block every model, and model windows reported later keep them.
+	used := 100.0
+	applyRateLimit(auth, "sonnet", &RateLimitState{
+		Windows: map[string]RateLimitWindow{
+			"tokens":     {Remaining: &plenty, ResetAt: now.Add(time.Minute)},
+			"unified_5h": {UsedPercent: &used, ResetAt: now.Add(time.Hour), AccountWide: true},
+		},
+		UpdatedAt: now,
+	})
+	applyRateLimit(auth, "sonnet", &RateLimitState{
+		Windows:   map[string]RateLimitWindow{"tokens": {Remaining: &plenty, ResetAt: now.Add(time.Minute)}},
+		UpdatedAt: now,
+	})
+	for _, model := range []string{"opus", "sonnet", "haiku"} {
+		if until, blocked := rateLimitBlock(auth, model, now); !blocked || !until.Equal(now.Add(time.Hour)) {
+			t.Fatalf("rateLimitBlock(%s) = %v, %v; want blocked by the account window", model, until, blocked)
+		}
+	}
+}
+
+func TestRateLimitExhaustedUntil(t *testing.T) {
+	now := time.Now()
+	zero, some := int64(0), int64(3)
+	full := 100.0
+	state := &RateLimitState{Windows: map[string]RateLimitWindow{
+		"requests": {Remaining: &some, ResetAt: now.Add(time.Minute)},
+		"tokens":   {Remaining: &zero, ResetAt: now.Add(2 * time.Minute)},
+		"primary":  {UsedPercent: &full, ResetAt: now.Add(time.Hour)},
+		"stale":    {Remaining: &zero, ResetAt: now.Add(-time.Minute)},
+	}}
+	until, exhausted := state.ExhaustedUntil(now)
+	if !exhausted || !until.Equal(now.Add(time.Hour)) {
+		t.Fatalf("ExhaustedUntil() = %v, %v", until, exhausted)
+	}
+	if _, exhausted = (&RateLimitState{Windows: map[string]RateLimitWindow{"requests": {Remaining: &some}}}).ExhaustedUntil(now); exhausted {
+		t.Fatal("window with remaining capacity reported exhausted")
+	}
+	var missing *RateLimitState
+	if _, exhausted = missing.ExhaustedUntil(now); exhausted {
+		t.Fatal("nil state reported exhausted")
+	}
+}
+
+func TestRateLimitThreshold(t *testing.T) {
+	t.Cleanup(func() { SetRateLimitThreshold(RateLimitThreshold{}) })
+	now := time.Now()
+	reset := now.Add(time.Minute)
+	few, plenty := int64(2), int64(40)
+	used := 96.0
+	windows := map[string]RateLimitWindow{
+		"few left":     {Remaining: &few, ResetAt: reset},
+		"derived used": {Limit: 50, Remaining: &few, ResetAt: reset},
+		"reported":     {UsedPercent: &used, ResetAt: reset},
+		"plenty":       {Limit: 50, Remaining: &plenty, ResetAt: reset},
+	}
+	cases := []struct {
+		threshold RateLimitThreshold
+		exhausted map[string]bool
+	}{
+		{RateLimitThreshold{}, map[string]bool{}},
+		{RateLimitThreshold{MinRemaining: 5}, map[string]bool{"few left": true, "derived used": true}},
+		{RateLimitThreshold{MaxUsedPercent: 95}, map[string]bool{"derived used": true, "reported": true}},
+	}
+	for _, tc := range cases {
+		SetRateLimitThreshold(tc.threshold)
+		for name, window := range windows {
+			state := &RateLimitState{Windows: map[string]RateLimitWindow{name: window}}
+			if _, got := state.ExhaustedUntil(now); got != tc.exhausted[name] {
+				t.Errorf("threshold %+v, window %q: exhausted = %v, want %v", tc.threshold, name, got, tc.exhausted[name])
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/schedule"
+	log "github.com/sirupsen/logrus"
+)
+
+// MetadataScheduleKey holds the active windows of a credential, either as a list of rules or
+// as a single string with rules separated by ';'. Config-backed auths carry it in Attributes.
+const MetadataScheduleKey = "schedule"
+
+// Schedule restricts when a credential may be selected. Rules starting with "never" exclude
+// time; all other rules are active windows, for example "weekdays 18:00-08:00 UTC",
+// "never on mondays", "sat,sun" or "mon-fri 09:00-17:00 Europe/Berlin".
+type Schedule = schedule.Schedule
+
+// ParseSchedule parses schedule rules. Empty rules are ignored; a schedule without rules is nil.
+func ParseSchedule(rules []string) (*Schedule, error) {
+	return schedule.Parse(rules)
+}
+
+// SplitScheduleRules splits a ';' separated schedule into its rules.
+func SplitScheduleRules(raw string) []string {
+	return schedule.SplitRules(raw)
+}
+
+// parsedSchedule is a cached parse result.
+type parsedSchedule struct {
+	schedule *Schedule
+	err      error
+}
+
+// scheduleCache memoizes parsed schedules by their source text.
+var scheduleCache sync.Map
+
+// Schedule returns the parsed active windows of the auth, or nil when it has none. An invalid
+// schedule is returned as an error and keeps the credential out of rotation until it is fixed,
+// since a typo must not put a credential to work outside the windows its owner meant.
+func (a *Auth) Schedule() (*Schedule, error) {
+	rules := a.ScheduleRules()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	key := strings.Join(rules, ";")
+	if cached, ok := scheduleCache.Load(key); ok {
+		parsed, _ := cached.(parsedSchedule)
+		return parsed.schedule, parsed.err
+	}
+	parsed, err := ParseSchedule(rules)
+	if err != nil {
+		log.Errorf("auth %s has an invalid schedule and stays out of rotation until it is fixed: %v", a.ID, err)
+	}
+	scheduleCache.Store(key, parsedSchedule{schedule: parsed, err: err})
+	return parsed, err
+}
+
+// ScheduleRules returns the schedule rules declared for the auth.
+func (a *Auth) ScheduleRules() []string {
+	if a == nil {
+		return nil
+	}
+	if raw := strings.TrimSpace(a.Attributes[MetadataScheduleKey]); raw != "" {
+		return SplitScheduleRules(raw)
+	}
+	switch v := a.Metadata[MetadataScheduleKey].(type) {
+	case string:
+		return SplitScheduleRules(v)
+	case []string:
+		return v
+	case []any:
+		rules := make([]string, 0, len(v))
+		for _, item := range v {
+			if rule, ok := item.(string); ok {
+				rules = append(rules, rule)
+			}
+		}
+		return rules
+	}
+	return nil
+}
+
+// scheduleBlock reports whether the auth is outside its active windows and when it opens next.
+// An invalid schedule blocks the auth with a zero next time.
+func scheduleBlock(auth *Auth, now time.Time) (time.Time, bool) {
+	schedule, err := auth.Schedule()
+	if err != nil {
+		return time.Time{}, true
+	}
+	if schedule.Active(now) {
+		return time.Time{}, false
+	}
+	next, _ := schedule.NextActive(now)
+	return next, true
+}
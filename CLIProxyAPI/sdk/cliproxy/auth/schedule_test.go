@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestScheduleActive(t *testing.T) {
+	// 2026-10-12 is a Monday.
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC) }
+	offHours, err := ParseSchedule([]string{"weekdays 18:00-08:00 UTC", "weekends"})
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	cases := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"monday working hours", at(12, 10, 0), false},
+		{"monday evening", at(12, 18, 0), true},
+		{"tuesday early morning", at(13, 7, 59), true},
+		{"tuesday 08:00", at(13, 8, 0), false},
+		{"saturday noon", at(17, 12, 0), true},
+		{"monday early morning after the weekend", at(12, 3, 0), false},
+	}
+	for _, tc := range cases {
+		if got := offHours.Active(tc.t); got != tc.want {
+			t.Errorf("%s: Active() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if next, ok := offHours.NextActive(at(12, 10, 0)); !ok || !next.Equal(at(12, 18, 0)) {
+		t.Fatalf("NextActive() = %v, %v; want monday 18:00", next, ok)
+	}
+
+	notMondays, err := ParseSchedule([]string{"never on Mondays"})
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	if notMondays.Active(at(12, 12, 0)) || !notMondays.Active(at(13, 0, 0)) {
+		t.Fatal("never on mondays should only exclude mondays")
+	}
+	if next, ok := notMondays.NextActive(at(12, 12, 0)); !ok || !next.Equal(at(13, 0, 0)) {
+		t.Fatalf("NextActive() = %v, %v; want tuesday midnight", next, ok)
+	}
+
+	berlin, err := ParseSchedule([]string{"mon-fri 09:00-17:00 Europe/Berlin"})
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	if !berlin.Active(at(12, 7, 30)) || berlin.Active(at(12, 15, 30)) {
+		t.Fatal("Europe/Berlin window evaluated in the wrong zone")
+	}
+}
+
+func TestScheduleNextActiveAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// Summer time in Berlin ends at 03:00 on 2026-10-25, so that day is 25 hours long.
+	schedule, err := ParseSchedule([]string{"sun 09:00-17:00 Europe/Berlin"})
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	now := time.Date(2026, 10, 25, 0, 30, 0, 0, berlin)
+	want := time.Date(2026, 10, 25, 9, 0, 0, 0, berlin)
+	if next, ok := schedule.NextActive(now); !ok || !next.Equal(want) {
+		t.Fatalf("NextActive() = %v, %v; want %v", next, ok, want)
+	}
+	// Summer time starts at 02:00 on 2026-03-29, a 23 hour day.
+	now = time.Date(2026, 3, 29, 0, 30, 0, 0, berlin)
+	want = time.Date(2026, 3, 29, 9, 0, 0, 0, berlin)
+	if next, ok := schedule.NextActive(now); !ok || !next.Equal(want) {
+		t.Fatalf("NextActive() = %v, %v; want %v", next, ok, want)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, rule := range []string{"never", "weekdays 25:00-08:00", "mon 18:00", "someday", "mon 09:00-10:00 11:00-12:00"} {
+		if _, err := ParseSchedule([]string{rule}); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want error", rule)
+		}
+	}
+	if schedule, err := ParseSchedule([]string{" ", ""}); err != nil || schedule != nil {
+		t.Fatalf("ParseSchedule(blank) = %v, %v; want nil", schedule, err)
+	}
+}
+
+func TestScheduledAuthSkippedOutsideWindow(t *testing.T) {
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.RegisterExecutor(&probeExecutor{stubExecutor: stubExecutor{provider: "sched"}})
+	// "a" is never active through its attributes, "b" reads its schedule from metadata.
+	auths := []*Auth{
+		{ID: "a", Provider: "sched", Attributes: map[string]string{"schedule": "never daily"}},
+		{ID: "b", Provider: "sched", Metadata: map[string]any{"schedule": []any{"daily"}}},
+	}
+	for _, auth := range auths {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("Register(%s) error = %v", auth.ID, err)
+		}
+	}
+	resp, err := manager.Execute(context.Background(), []string{"sched"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != "b" {
+		t.Fatalf("Execute() = %q, %v; want a skipped", resp.Payload, err)
+	}
+
+	if _, err = manager.Modify(context.Background(), "b", func(a *Auth) { a.Metadata["schedule"] = "never daily" }); err != nil {
+		t.Fatalf("Modify() error = %v", err)
+	}
+	_, err = manager.Execute(context.Background(), []string{"sched"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+	if err == nil {
+		t.Fatal("Execute() succeeded with every auth outside its schedule")
+	}
+
+	// An invalid schedule keeps the auth out of rotation instead of making it always active.
+	if _, err = manager.Register(context.Background(), &Auth{ID: "c", Provider: "sched", Attributes: map[string]string{"schedule": "whenever"}}); err != nil {
+		t.Fatalf("Register(c) error = %v", err)
+	}
+	if _, err = manager.Modify(context.Background(), "b", func(a *Auth) { delete(a.Metadata, "schedule") }); err != nil {
+		t.Fatalf("Modify() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		resp, err = manager.Execute(context.Background(), []string{"sched"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{})
+		if err != nil || string(resp.Payload) != "b" {
+			t.Fatalf("Execute() = %q, %v; want auth c with an invalid schedule skipped", resp.Payload, err)
+		}
+	}
+	invalid, _ := manager.GetByID("c")
+	if _, errSchedule := invalid.Schedule(); errSchedule == nil {
+		t.Fatal("Schedule() accepted an invalid rule")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"net/http"
 	"sort"
 	"strconv"
@@ -14,7 +15,8 @@ import (
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 )
 
-// RoundRobinSelector provides a simple provider scoped round-robin selection strategy.
+// RoundRobinSelector provides a simple provider scoped round-robin selection strategy. In a tier
+// where any auth sets a weight, each auth takes as many consecutive turns as its weight.
 type RoundRobinSelector struct {
 	mu      sync.Mutex
 	cursors map[string]int
@@ -22,9 +24,46 @@ type RoundRobinSelector struct {
 
 // FillFirstSelector selects the first available credential (deterministic ordering).
 // This "burns" one account before moving to the next, which can help stagger
-// rolling-window subscription caps (e.g. chat message limits).
+// rolling-window subscription caps (e.g. chat message limits). A tier where any auth
+// sets a weight is spread by weight instead, as with WeightedSelector.
 type FillFirstSelector struct{}
 
+// WeightedSelector picks randomly among the highest priority tier, in proportion to each auth's weight.
+type WeightedSelector struct {
+	// intn overrides the random source; used by tests.
+	intn func(n int) int
+}
+
+// LeastLoadedSelector picks the credential with the lowest expected cost. The cost combines a
+// moving average of time-to-first-byte, a decaying recent error rate and the number of requests
+// currently in flight, all fed back from Manager.MarkResult, and is divided by the auth's weight.
+type LeastLoadedSelector struct {
+	mu    sync.Mutex
+	stats map[string]*authLoadStats
+}
+
+// authLoadStats holds the load signals tracked for a single auth.
+type authLoadStats struct {
+	ttfb      time.Duration
+	samples   int
+	errorRate float64
+	inFlight  int
+	updatedAt time.Time
+}
+
+const (
+	// leastLoadedLatencyAlpha weights the newest TTFB sample in the moving average.
+	leastLoadedLatencyAlpha = 0.3
+	// leastLoadedErrorAlpha weights the newest success/failure sample in the error rate.
+	leastLoadedErrorAlpha = 0.2
+	// leastLoadedErrorHalfLife lets the error rate of idle auths fade so they get retried.
+	leastLoadedErrorHalfLife = 5 * time.Minute
+	// leastLoadedErrorPenalty multiplies the cost of an auth failing every request.
+	leastLoadedErrorPenalty = 4.0
+	// leastLoadedLatencyFloor keeps unmeasured auths cheap but still sensitive to in-flight load.
+	leastLoadedLatencyFloor = 100 * time.Millisecond
+)
+
 type blockReason int
 
 const (
@@ -142,7 +181,36 @@ func getAvailableAuths(auths []*Auth, provider, model string, now time.Time) ([]
 		return nil, &Error{Code: "auth_unavailable", Message: "no auth available"}
 	}
 
-	return available, nil
+	return highestPriorityTier(available), nil
+}
+
+// highestPriorityTier keeps only the auths sharing the highest priority, preserving their order.
+// Lower tiers are reached once every auth above them is blocked, cooling down or already tried.
+func highestPriorityTier(available []*Auth) []*Auth {
+	if len(available) < 2 {
+		return available
+	}
+	top := available[0].Priority()
+	mixed := false
+	for _, candidate := range available[1:] {
+		priority := candidate.Priority()
+		if priority != top {
+			mixed = true
+		}
+		if priority > top {
+			top = priority
+		}
+	}
+	if !mixed {
+		return available
+	}
+	tier := make([]*Auth, 0, len(available))
+	for _, candidate := range available {
+		if candidate.Priority() == top {
+			tier = append(tier, candidate)
+		}
+	}
+	return tier
 }
 
 // Pick selects the next available auth for the provider in a round-robin manner.
@@ -168,6 +236,9 @@ func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, o
 	s.cursors[key] = index + 1
 	s.mu.Unlock()
 	// log.Debugf("available: %d, index: %d, key: %d", len(available), index, index%len(available))
+	if hasExplicitWeight(available) {
+		return pickByWeight(available, index%totalWeight(available)), nil
+	}
 	return available[index%len(available)], nil
 }
 
@@ -180,9 +251,168 @@ func (s *FillFirstSelector) Pick(ctx context.Context, provider, model string, op
 	if err != nil {
 		return nil, err
 	}
+	if hasExplicitWeight(available) {
+		return pickByWeight(available, rand.IntN(totalWeight(available))), nil
+	}
 	return available[0], nil
 }
 
+// Pick selects an available auth from the highest priority tier using weighted random selection.
+func (s *WeightedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	available, err := getAvailableAuths(auths, provider, model, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(available) == 1 {
+		return available[0], nil
+	}
+	intn := rand.IntN
+	if s.intn != nil {
+		intn = s.intn
+	}
+	return pickByWeight(available, intn(totalWeight(available))), nil
+}
+
+// hasExplicitWeight reports whether any auth in the tier sets a weight, which makes every
+// strategy spread the tier's traffic in proportion to the weights.
+func hasExplicitWeight(available []*Auth) bool {
+	for _, candidate := range available {
+		if value, ok := candidate.routingInt("weight"); ok && value > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func totalWeight(available []*Auth) int {
+	total := 0
+	for _, candidate := range available {
+		total += candidate.Weight()
+	}
+	return total
+}
+
+// pickByWeight returns the auth whose share of [0, totalWeight) contains point.
+func pickByWeight(available []*Auth, point int) *Auth {
+	for _, candidate := range available {
+		point -= candidate.Weight()
+		if point < 0 {
+			return candidate
+		}
+	}
+	return available[len(available)-1]
+}
+
+// Pick selects the available auth with the lowest load cost; ties resolve by auth ID.
+func (s *LeastLoadedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	_ = opts
+	now := time.Now()
+	available, err := getAvailableAuths(auths, provider, model, now)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	best := available[0]
+	bestCost := s.costLocked(best.ID, now) / float64(best.Weight())
+	for _, candidate := range available[1:] {
+		// Compare IDs explicitly so ties do not depend on the order of the candidates.
+		if cost := s.costLocked(candidate.ID, now) / float64(candidate.Weight()); cost < bestCost || (cost == bestCost && candidate.ID < best.ID) {
+			best = candidate
+			bestCost = cost
+		}
+	}
+	return best, nil
+}
+
+// OnSelected implements SelectionObserver by counting the request as in flight.
+func (s *LeastLoadedSelector) OnSelected(provider, model string, auth *Auth) {
+	if auth == nil {
+		return
+	}
+	s.mu.Lock()
+	s.statsLocked(auth.ID).inFlight++
+	s.mu.Unlock()
+}
+
+// OnResult implements SelectionObserver by folding the result into the auth's load statistics.
+func (s *LeastLoadedSelector) OnResult(result Result) {
+	if result.AuthID == "" {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.statsLocked(result.AuthID)
+	if stats.inFlight > 0 {
+		stats.inFlight--
+	}
+	if result.TTFB > 0 {
+		if stats.samples == 0 {
+			stats.ttfb = result.TTFB
+		} else {
+			stats.ttfb = time.Duration(leastLoadedLatencyAlpha*float64(result.TTFB) + (1-leastLoadedLatencyAlpha)*float64(stats.ttfb))
+		}
+		stats.samples++
+	}
+	sample := 0.0
+	if !result.Success {
+		sample = 1.0
+	}
+	stats.errorRate = leastLoadedErrorAlpha*sample + (1-leastLoadedErrorAlpha)*decayedErrorRate(stats, now)
+	stats.updatedAt = now
+}
+
+// OnReleased implements SelectionObserver by dropping the request from the in-flight count.
+func (s *LeastLoadedSelector) OnReleased(authID string) {
+	if authID == "" {
+		return
+	}
+	s.mu.Lock()
+	if stats := s.stats[authID]; stats != nil && stats.inFlight > 0 {
+		stats.inFlight--
+	}
+	s.mu.Unlock()
+}
+
+func (s *LeastLoadedSelector) statsLocked(authID string) *authLoadStats {
+	if s.stats == nil {
+		s.stats = make(map[string]*authLoadStats)
+	}
+	stats, ok := s.stats[authID]
+	if !ok {
+		stats = &authLoadStats{}
+		s.stats[authID] = stats
+	}
+	return stats
+}
+
+func (s *LeastLoadedSelector) costLocked(authID string, now time.Time) float64 {
+	stats := s.stats[authID]
+	if stats == nil {
+		return leastLoadedLatencyFloor.Seconds()
+	}
+	latency := leastLoadedLatencyFloor
+	if stats.samples > 0 && stats.ttfb > latency {
+		latency = stats.ttfb
+	}
+	return latency.Seconds() * float64(1+stats.inFlight) * (1 + leastLoadedErrorPenalty*decayedErrorRate(stats, now))
+}
+
+func decayedErrorRate(stats *authLoadStats, now time.Time) float64 {
+	if stats == nil || stats.errorRate <= 0 || stats.updatedAt.IsZero() {
+		return 0
+	}
+	elapsed := now.Sub(stats.updatedAt)
+	if elapsed <= 0 {
+		return stats.errorRate
+	}
+	return stats.errorRate * math.Pow(0.5, float64(elapsed)/float64(leastLoadedErrorHalfLife))
+}
+
 func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, blockReason, time.Time) {
 	if auth == nil {
 		return true, blockReasonOther, time.Time{}
@@ -190,6 +420,21 @@ func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, block
 	if auth.Disabled || auth.Status == StatusDisabled {
 		return true, blockReasonDisabled, time.Time{}
 	}
+	if auth.Quarantined {
+		return true, blockReasonOther, time.Time{}
+	}
+	// Time outside the credential's active windows is treated like a cooldown. Invalid schedules
+	// and schedules that stay closed for more than a week have no next time.
+	if next, outside := scheduleBlock(auth, now); outside {
+		if next.IsZero() {
+			return true, blockReasonOther, time.Time{}
+		}
+		return true, blockReasonCooldown, next
+	}
+	// Skip credentials whose last response reported an exhausted window instead of spending a 429.
+	if until, exhausted := rateLimitBlock(auth, model, now); exhausted {
+		return true, blockReasonCooldown, until
+	}
 	if model != "" {
 		if len(auth.ModelStates) > 0 {
 			if state, ok := auth.ModelStates[model]; ok && state != nil {
@@ -3,8 +3,10 @@ package auth
 import (
 	"context"
 	"errors"
+	"reflect"
 	"sync"
 	"testing"
+	"time"
 
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 )
@@ -111,3 +113,172 @@ func TestRoundRobinSelectorPick_Concurrent(t *testing.T) {
 	default:
 	}
 }
+
+func TestLeastLoadedSelectorPick_PrefersLowerLatency(t *testing.T) {
+	t.Parallel()
+
+	selector := &LeastLoadedSelector{}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+	selector.OnResult(Result{AuthID: "a", Success: true, TTFB: 2 * time.Second})
+	selector.OnResult(Result{AuthID: "b", Success: true, TTFB: 300 * time.Millisecond})
+
+	got, err := selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "b" {
+		t.Fatalf("Pick() auth.ID = %q, want %q", got.ID, "b")
+	}
+}
+
+func TestLeastLoadedSelectorPick_AvoidsBusyAndFailingAuths(t *testing.T) {
+	t.Parallel()
+
+	selector := &LeastLoadedSelector{}
+	auths := []*Auth{{ID: "a"}, {ID: "b"}}
+
+	got, err := selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "a" {
+		t.Fatalf("Pick() with no stats auth.ID = %q, want %q", got.ID, "a")
+	}
+
+	selector.OnSelected("gemini", "", got)
+	got, err = selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "b" {
+		t.Fatalf("Pick() with a in flight auth.ID = %q, want %q", got.ID, "b")
+	}
+
+	// A released request frees the slot without counting as an error.
+	selector.OnSelected("gemini", "", got)
+	selector.OnReleased("b")
+	if got, err = selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths); err != nil || got.ID != "b" {
+		t.Fatalf("Pick() after release = %v, %v; want b", got, err)
+	}
+
+	selector.OnResult(Result{AuthID: "a", Success: true})
+	selector.OnResult(Result{AuthID: "b", Success: false})
+	got, err = selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "a" {
+		t.Fatalf("Pick() with b failing auth.ID = %q, want %q", got.ID, "a")
+	}
+}
+
+func TestLeastLoadedSelectorPick_BreaksTiesByID(t *testing.T) {
+	t.Parallel()
+
+	selector := &LeastLoadedSelector{}
+	auths := []*Auth{{ID: "c"}, {ID: "a"}, {ID: "b"}}
+	for i := 0; i < 3; i++ {
+		got, err := selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		if got.ID != "a" {
+			t.Fatalf("Pick() with equal costs auth.ID = %q, want %q", got.ID, "a")
+		}
+	}
+}
+
+func TestSelectorsDrainHighestPriorityTierFirst(t *testing.T) {
+	t.Parallel()
+
+	auths := []*Auth{
+		{ID: "a-paid", Attributes: map[string]string{"priority": "-10"}},
+		{ID: "b-oauth", Metadata: map[string]any{"priority": float64(0)}},
+		{ID: "c-oauth"},
+	}
+	selectors := map[string]Selector{
+		"round-robin":  &RoundRobinSelector{},
+		"fill-first":   &FillFirstSelector{},
+		"least-loaded": &LeastLoadedSelector{},
+		"weighted":     &WeightedSelector{},
+	}
+	for name, selector := range selectors {
+		for i := 0; i < 6; i++ {
+			got, err := selector.Pick(context.Background(), "claude", "", cliproxyexecutor.Options{}, auths)
+			if err != nil {
+				t.Fatalf("%s Pick() #%d error = %v", name, i, err)
+			}
+			if got.ID == "a-paid" {
+				t.Fatalf("%s Pick() #%d selected lower tier auth while higher tier was available", name, i)
+			}
+		}
+	}
+
+	blocked := []*Auth{auths[0], {ID: "b-oauth", Disabled: true}, {ID: "c-oauth", Status: StatusDisabled}}
+	for name, selector := range selectors {
+		got, err := selector.Pick(context.Background(), "claude", "", cliproxyexecutor.Options{}, blocked)
+		if err != nil {
+			t.Fatalf("%s Pick() with upper tier blocked error = %v", name, err)
+		}
+		if got.ID != "a-paid" {
+			t.Fatalf("%s Pick() with upper tier blocked auth.ID = %q, want %q", name, got.ID, "a-paid")
+		}
+	}
+}
+
+func TestWeightedSelectorPick_RespectsWeights(t *testing.T) {
+	t.Parallel()
+
+	auths := []*Auth{
+		{ID: "a", Attributes: map[string]string{"weight": "3"}},
+		{ID: "b"},
+	}
+	want := []string{"a", "a", "a", "b"}
+	for point, id := range want {
+		selector := &WeightedSelector{intn: func(n int) int {
+			if n != 4 {
+				t.Fatalf("intn(%d), want total weight 4", n)
+			}
+			return point
+		}}
+		got, err := selector.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		if got.ID != id {
+			t.Fatalf("Pick() at point %d auth.ID = %q, want %q", point, got.ID, id)
+		}
+	}
+}
+
+func TestDefaultSelectorsHonorWeights(t *testing.T) {
+	t.Parallel()
+
+	auths := []*Auth{
+		{ID: "a", Attributes: map[string]string{"weight": "3"}},
+		{ID: "b"},
+	}
+	roundRobin := &RoundRobinSelector{}
+	var got []string
+	for i := 0; i < 8; i++ {
+		picked, err := roundRobin.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		got = append(got, picked.ID)
+	}
+	if want := []string{"a", "a", "a", "b", "a", "a", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("round-robin picks = %v, want %v", got, want)
+	}
+
+	// A request in flight on a still leaves it cheaper per unit of weight than an idle b.
+	leastLoaded := &LeastLoadedSelector{}
+	leastLoaded.OnSelected("gemini", "", auths[0])
+	picked, err := leastLoaded.Pick(context.Background(), "gemini", "", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if picked.ID != "a" {
+		t.Fatalf("least-loaded with a busy picked %q, want the heavier a", picked.ID)
+	}
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+// SessionIDMetadataKey is the execution metadata key carrying a client supplied session identifier
+// (for example the X-Session-ID request header).
+const SessionIDMetadataKey = "session_id"
+
+// sessionAffinity remembers which auth served a client session so follow-up turns reuse it.
+type sessionAffinity struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	bindings  map[string]sessionBinding
+	lastSweep time.Time
+}
+
+type sessionBinding struct {
+	authID    string
+	expiresAt time.Time
+}
+
+// SetSessionAffinity enables sticky credential selection for requests carrying a session key.
+// Bindings expire after ttl without use; a non-positive ttl disables affinity and drops all bindings.
+func (m *Manager) SetSessionAffinity(ttl time.Duration) {
+	if m == nil {
+		return
+	}
+	var affinity *sessionAffinity
+	if ttl > 0 {
+		affinity = &sessionAffinity{ttl: ttl, bindings: make(map[string]sessionBinding)}
+	}
+	m.mu.Lock()
+	m.affinity = affinity
+	m.mu.Unlock()
+}
+
+// sessionAffinityKey derives the binding key for a request, or "" when the request carries no session.
+// An explicit session ID wins over the Codex prompt_cache_key, which wins over Claude metadata.user_id.
+func sessionAffinityKey(provider, model string, opts cliproxyexecutor.Options) string {
+	session := ""
+	if raw, ok := opts.Metadata[SessionIDMetadataKey].(string); ok {
+		session = strings.TrimSpace(raw)
+	}
+	if session == "" && len(opts.OriginalRequest) > 0 {
+		for _, path := range []string{"prompt_cache_key", "metadata.user_id"} {
+			if value := strings.TrimSpace(gjson.GetBytes(opts.OriginalRequest, path).String()); value != "" {
+				session = value
+				break
+			}
+		}
+	}
+	if session == "" {
+		return ""
+	}
+	return provider + ":" + strings.TrimSpace(model) + ":" + session
+}
+
+// lookup returns the auth bound to key, if the binding has not expired.
+func (s *sessionAffinity) lookup(key string, now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	binding, ok := s.bindings[key]
+	if !ok {
+		return ""
+	}
+	if now.After(binding.expiresAt) {
+		delete(s.bindings, key)
+		return ""
+	}
+	return binding.authID
+}
+
+// bind records that key is served by authID and extends the binding by the TTL.
+func (s *sessionAffinity) bind(key, authID string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bindings[key] = sessionBinding{authID: authID, expiresAt: now.Add(s.ttl)}
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	s.lastSweep = now
+	for k, binding := range s.bindings {
+		if now.After(binding.expiresAt) {
+			delete(s.bindings, k)
+		}
+	}
+}
+
+// stickyCandidate returns the pinned auth when it is still a valid choice for the request:
+// not yet tried, not blocked or cooling down for the model, and within the preferred priority tier.
+func stickyCandidate(candidates []*Auth, authID, model string, now time.Time) *Auth {
+	available, _, _ := collectAvailable(candidates, model, now)
+	for _, candidate := range highestPriorityTier(available) {
+		if candidate.ID == authID {
+			return candidate
+		}
+	}
+	return nil
+}
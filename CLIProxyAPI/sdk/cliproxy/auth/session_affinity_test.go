@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func newAffinityTestManager(t *testing.T, ids ...string) *Manager {
+	t.Helper()
+	manager := NewManager(nil, &RoundRobinSelector{}, nil)
+	manager.RegisterExecutor(stubExecutor{provider: "stub"})
+	for _, id := range ids {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "stub"}); err != nil {
+			t.Fatalf("Register(%s) error = %v", id, err)
+		}
+	}
+	manager.SetSessionAffinity(time.Hour)
+	return manager
+}
+
+func TestPickNextKeepsSessionOnSameAuth(t *testing.T) {
+	manager := newAffinityTestManager(t, "a", "b", "c")
+	opts := cliproxyexecutor.Options{OriginalRequest: []byte(`{"metadata":{"user_id":"user_1_session_abc"}}`)}
+
+	first, _, err := manager.pickNext(context.Background(), "stub", "", opts, map[string]struct{}{})
+	if err != nil {
+		t.Fatalf("pickNext() error = %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		got, _, errPick := manager.pickNext(context.Background(), "stub", "", opts, map[string]struct{}{})
+		if errPick != nil {
+			t.Fatalf("pickNext() #%d error = %v", i, errPick)
+		}
+		if got.ID != first.ID {
+			t.Fatalf("pickNext() #%d auth.ID = %q, want pinned %q", i, got.ID, first.ID)
+		}
+	}
+
+	other := cliproxyexecutor.Options{Metadata: map[string]any{SessionIDMetadataKey: "other-session"}}
+	got, _, err := manager.pickNext(context.Background(), "stub", "", other, map[string]struct{}{})
+	if err != nil {
+		t.Fatalf("pickNext() other session error = %v", err)
+	}
+	if got.ID == first.ID {
+		t.Fatalf("pickNext() other session reused %q; round-robin should have advanced", got.ID)
+	}
+}
+
+func TestPickNextFailsOverPinnedAuthInCooldown(t *testing.T) {
+	manager := newAffinityTestManager(t, "a", "b")
+	opts := cliproxyexecutor.Options{OriginalRequest: []byte(`{"prompt_cache_key":"conv-1"}`)}
+
+	pinned, _, err := manager.pickNext(context.Background(), "stub", "", opts, map[string]struct{}{})
+	if err != nil {
+		t.Fatalf("pickNext() error = %v", err)
+	}
+	manager.MarkResult(context.Background(), Result{AuthID: pinned.ID, Provider: "stub", Error: &Error{HTTPStatus: http.StatusTooManyRequests, Message: "quota"}})
+
+	failover, _, err := manager.pickNext(context.Background(), "stub", "", opts, map[string]struct{}{})
+	if err != nil {
+		t.Fatalf("pickNext() after cooldown error = %v", err)
+	}
+	if failover.ID == pinned.ID {
+		t.Fatalf("pickNext() after cooldown auth.ID = %q, want failover", failover.ID)
+	}
+	again, _, err := manager.pickNext(context.Background(), "stub", "", opts, map[string]struct{}{})
+	if err != nil {
+		t.Fatalf("pickNext() after failover error = %v", err)
+	}
+	if again.ID != failover.ID {
+		t.Fatalf("pickNext() after failover auth.ID = %q, want rebound %q", again.ID, failover.ID)
+	}
+}
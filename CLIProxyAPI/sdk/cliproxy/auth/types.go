@@ -36,6 +36,8 @@ type Auth struct {
 	Disabled bool `json:"disabled"`
 	// Unavailable flags transient provider unavailability (e.g. quota exceeded).
 	Unavailable bool `json:"unavailable"`
+	// Quarantined is set while health probes find the credential rejected by the provider (401/403).
+	Quarantined bool `json:"quarantined,omitempty"`
 	// ProxyURL overrides the global proxy setting for this auth if provided.
 	ProxyURL string `json:"proxy_url,omitempty"`
 	// Attributes stores provider specific metadata needed by executors (immutable configuration).
@@ -75,6 +77,8 @@ type QuotaState struct {
 	NextRecoverAt time.Time `json:"next_recover_at"`
 	// BackoffLevel stores the progressive cooldown exponent used for rate limits.
 	BackoffLevel int `json:"backoff_level,omitempty"`
+	// RateLimit is the latest rate limit snapshot reported in upstream response headers.
+	RateLimit *RateLimitState `json:"rate_limit,omitempty"`
 }
 
 // ModelState captures the execution state for a specific model under an auth entry.
@@ -180,6 +184,66 @@ func (m *ModelState) Clone() *ModelState {
 	return &copyState
 }
 
+// Priority returns the routing tier of the auth. Higher tiers are preferred; the default is 0.
+// Config-backed auths carry it in Attributes, file-backed auths may set it in their metadata.
+func (a *Auth) Priority() int {
+	value, _ := a.routingInt("priority")
+	return value
+}
+
+// Weight returns the relative selection weight of the auth within its priority tier.
+// Missing or non-positive values fall back to 1.
+func (a *Auth) Weight() int {
+	value, ok := a.routingInt("weight")
+	if !ok || value <= 0 {
+		return 1
+	}
+	return value
+}
+
+// MaxConcurrency returns the cap on requests in flight on the auth, or 0 when the provider
+// default applies.
+func (a *Auth) MaxConcurrency() int {
+	value, ok := a.routingInt("max_concurrency")
+	if !ok || value <= 0 {
+		return 0
+	}
+	return value
+}
+
+func (a *Auth) routingInt(key string) (int, bool) {
+	if a == nil {
+		return 0, false
+	}
+	if a.Attributes != nil {
+		if raw := strings.TrimSpace(a.Attributes[key]); raw != "" {
+			if value, err := strconv.Atoi(raw); err == nil {
+				return value, true
+			}
+		}
+	}
+	if a.Metadata == nil {
+		return 0, false
+	}
+	switch v := a.Metadata[key].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case json.Number:
+		if value, err := v.Int64(); err == nil {
+			return int(value), true
+		}
+	case string:
+		if value, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
 func (a *Auth) ProxyInfo() string {
 	if a == nil {
 		return ""
@@ -11,6 +11,7 @@ import (
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/pipeline"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 )
 
@@ -47,6 +48,9 @@ type Builder struct {
 
 	// serverOptions contains additional server configuration options.
 	serverOptions []api.ServerOption
+
+	// pipelineHooks run around every provider call made by the core manager.
+	pipelineHooks []pipeline.Hook
 }
 
 // Hooks allows callers to plug into service lifecycle stages.
@@ -137,6 +141,14 @@ func (b *Builder) WithCoreAuthManager(mgr *coreauth.Manager) *Builder {
 	return b
 }
 
+// WithPipelineHooks appends hooks that run around every provider execution.
+// BeforeExecute sees the selected auth and the request handed to the executor and may rewrite it;
+// OnStreamChunk sees each streamed chunk and AfterExecute sees the outcome.
+func (b *Builder) WithPipelineHooks(hooks ...pipeline.Hook) *Builder {
+	b.pipelineHooks = append(b.pipelineHooks, hooks...)
+	return b
+}
+
 // WithServerOptions appends server configuration options used during construction.
 func (b *Builder) WithServerOptions(opts ...api.ServerOption) *Builder {
 	b.serverOptions = append(b.serverOptions, opts...)
@@ -207,6 +219,10 @@ func (b *Builder) Build() (*Service, error) {
 		switch strategy {
 		case "fill-first", "fillfirst", "ff":
 			selector = &coreauth.FillFirstSelector{}
+		case "least-loaded", "leastloaded", "least-load", "ll":
+			selector = &coreauth.LeastLoadedSelector{}
+		case "weighted", "weighted-random", "wr":
+			selector = &coreauth.WeightedSelector{}
 		default:
 			selector = &coreauth.RoundRobinSelector{}
 		}
@@ -216,6 +232,9 @@ func (b *Builder) Build() (*Service, error) {
 	// Attach a default RoundTripper provider so providers can opt-in per-auth transports.
 	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
 	coreManager.SetOAuthModelMappings(b.cfg.OAuthModelMappings)
+	if hook := newPipelineExecutionHook(b.pipelineHooks); hook != nil {
+		coreManager.SetExecutionHook(hook)
+	}
 
 	service := &Service{
 		cfg:            b.cfg,
@@ -17,13 +17,18 @@ type Context struct {
 	Options cliproxyexecutor.Options
 	// Auth references the credential selected for execution.
 	Auth *cliproxyauth.Auth
-	// Translator represents the pipeline responsible for schema adaptation.
+	// Translator represents the pipeline responsible for schema adaptation. It is backed by the
+	// default translator registry, so hooks can convert payloads between source formats.
 	Translator *sdktranslator.Pipeline
-	// HTTPClient allows middleware to customise the outbound transport per request.
+	// HTTPClient is never populated and executors do not read it.
+	//
+	// Deprecated: customise the outbound transport with a RoundTripperProvider or the
+	// per-credential proxy-url instead.
 	HTTPClient *http.Client
 }
 
-// Hook captures middleware callbacks around execution.
+// Hook captures middleware callbacks around execution. BeforeExecute may rewrite Request and
+// Options; OnStreamChunk only observes chunks and cannot rewrite or drop them.
 type Hook interface {
 	BeforeExecute(ctx context.Context, execCtx *Context)
 	AfterExecute(ctx context.Context, execCtx *Context, resp cliproxyexecutor.Response, err error)
@@ -0,0 +1,61 @@
+package cliproxy
+
+import (
+	"context"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/pipeline"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// pipelineExecutionHook adapts pipeline hooks to coreauth.ExecutionHook.
+// Hooks run in registration order and share one pipeline.Context per provider call.
+type pipelineExecutionHook struct {
+	hooks      []pipeline.Hook
+	translator *sdktranslator.Pipeline
+}
+
+func newPipelineExecutionHook(hooks []pipeline.Hook) *pipelineExecutionHook {
+	filtered := make([]pipeline.Hook, 0, len(hooks))
+	for _, hook := range hooks {
+		if hook != nil {
+			filtered = append(filtered, hook)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	return &pipelineExecutionHook{hooks: filtered, translator: sdktranslator.NewPipeline(sdktranslator.Default())}
+}
+
+// BeginExecution implements coreauth.ExecutionHook. Request and option changes made by
+// BeforeExecute are handed to the executor.
+func (h *pipelineExecutionHook) BeginExecution(ctx context.Context, auth *coreauth.Auth, req *cliproxyexecutor.Request, opts *cliproxyexecutor.Options) coreauth.ExecutionTrace {
+	execCtx := &pipeline.Context{Request: *req, Options: *opts, Auth: auth, Translator: h.translator}
+	for _, hook := range h.hooks {
+		hook.BeforeExecute(ctx, execCtx)
+	}
+	*req = execCtx.Request
+	*opts = execCtx.Options
+	return &pipelineExecutionTrace{hooks: h.hooks, execCtx: execCtx}
+}
+
+type pipelineExecutionTrace struct {
+	hooks   []pipeline.Hook
+	execCtx *pipeline.Context
+}
+
+// OnStreamChunk implements coreauth.ExecutionTrace.
+func (t *pipelineExecutionTrace) OnStreamChunk(ctx context.Context, chunk cliproxyexecutor.StreamChunk) {
+	for _, hook := range t.hooks {
+		hook.OnStreamChunk(ctx, t.execCtx, chunk)
+	}
+}
+
+// Finish implements coreauth.ExecutionTrace.
+func (t *pipelineExecutionTrace) Finish(ctx context.Context, resp cliproxyexecutor.Response, err error) {
+	for _, hook := range t.hooks {
+		hook.AfterExecute(ctx, t.execCtx, resp, err)
+	}
+}
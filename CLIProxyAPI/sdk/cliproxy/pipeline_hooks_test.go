@@ -0,0 +1,123 @@
+package cliproxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/pipeline"
+)
+
+// echoExecutor returns the request payload as the response and as two stream chunks.
+type echoExecutor struct{}
+
+func (echoExecutor) Identifier() string { return "echo" }
+
+func (echoExecutor) Execute(_ context.Context, _ *coreauth.Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{Payload: req.Payload}, nil
+}
+
+func (echoExecutor) ExecuteStream(_ context.Context, _ *coreauth.Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	ch := make(chan cliproxyexecutor.StreamChunk, 2)
+	ch <- cliproxyexecutor.StreamChunk{Payload: req.Payload}
+	ch <- cliproxyexecutor.StreamChunk{Payload: []byte("done")}
+	close(ch)
+	return ch, nil
+}
+
+func (echoExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (echoExecutor) CountTokens(context.Context, *coreauth.Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, errors.New("count failed")
+}
+
+func (echoExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newPipelineHookManager(t *testing.T, hooks ...pipeline.Hook) *coreauth.Manager {
+	t.Helper()
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(echoExecutor{})
+	if _, err := manager.Register(context.Background(), &coreauth.Auth{ID: "echo-1", Provider: "echo"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	manager.SetExecutionHook(newPipelineExecutionHook(hooks))
+	return manager
+}
+
+func TestPipelineHooksRewriteRequestAndObserveResult(t *testing.T) {
+	var authID string
+	var afterPayload string
+	translatorSet := false
+	rewrite := pipeline.HookFunc{
+		Before: func(_ context.Context, execCtx *pipeline.Context) {
+			authID = execCtx.Auth.ID
+			translatorSet = execCtx.Translator != nil
+			execCtx.Request.Payload = []byte("redacted")
+		},
+		After: func(_ context.Context, _ *pipeline.Context, resp cliproxyexecutor.Response, err error) {
+			if err == nil {
+				afterPayload = string(resp.Payload)
+			}
+		},
+	}
+	manager := newPipelineHookManager(t, rewrite)
+
+	resp, err := manager.Execute(context.Background(), []string{"echo"}, cliproxyexecutor.Request{Payload: []byte("secret")}, cliproxyexecutor.Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if string(resp.Payload) != "redacted" {
+		t.Fatalf("executor payload = %q, want rewritten request", string(resp.Payload))
+	}
+	if authID != "echo-1" {
+		t.Fatalf("BeforeExecute auth = %q, want echo-1", authID)
+	}
+	if afterPayload != "redacted" {
+		t.Fatalf("AfterExecute payload = %q, want redacted", afterPayload)
+	}
+	if !translatorSet {
+		t.Fatal("BeforeExecute context has no translator")
+	}
+
+	var countErr error
+	manager = newPipelineHookManager(t, pipeline.HookFunc{
+		After: func(_ context.Context, _ *pipeline.Context, _ cliproxyexecutor.Response, err error) { countErr = err },
+	})
+	if _, err = manager.ExecuteCount(context.Background(), []string{"echo"}, cliproxyexecutor.Request{}, cliproxyexecutor.Options{}); err == nil {
+		t.Fatal("ExecuteCount() error = nil, want executor error")
+	}
+	if countErr == nil {
+		t.Fatal("AfterExecute did not receive the CountTokens error")
+	}
+}
+
+func TestPipelineHooksObserveStreamChunks(t *testing.T) {
+	var chunks []string
+	finished := false
+	manager := newPipelineHookManager(t, pipeline.HookFunc{
+		Stream: func(_ context.Context, _ *pipeline.Context, chunk cliproxyexecutor.StreamChunk) {
+			chunks = append(chunks, string(chunk.Payload))
+		},
+		After: func(context.Context, *pipeline.Context, cliproxyexecutor.Response, error) { finished = true },
+	})
+
+	stream, err := manager.ExecuteStream(context.Background(), []string{"echo"}, cliproxyexecutor.Request{Payload: []byte("hello")}, cliproxyexecutor.Options{Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream() error = %v", err)
+	}
+	for range stream {
+	}
+	if len(chunks) != 2 || chunks[0] != "hello" || chunks[1] != "done" {
+		t.Fatalf("observed chunks = %v, want [hello done]", chunks)
+	}
+	if !finished {
+		t.Fatal("AfterExecute was not called after the stream ended")
+	}
+}
@@ -8,14 +8,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/circuitbreaker"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/transport"
 	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/wsrelay"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -317,6 +321,110 @@ func (s *Service) applyRetryConfig(cfg *config.Config) {
 	s.coreManager.SetRetryConfig(cfg.RequestRetry, maxInterval)
 }
 
+// applyCircuitBreakerConfig installs fresh credential and model breakers when circuit breaking is enabled.
+// Replacing the manager resets all breaker state, so callers should only invoke it when settings change.
+func (s *Service) applyCircuitBreakerConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	if !cfg.CircuitBreaker.Enabled {
+		s.coreManager.SetCircuitBreakers(nil)
+		return
+	}
+	s.coreManager.SetCircuitBreakers(circuitbreaker.NewManager(circuitbreaker.ConfigFromSettings(cfg.CircuitBreaker)))
+}
+
+// defaultSessionAffinityTTL bounds how long an idle session stays pinned when no TTL is configured.
+const defaultSessionAffinityTTL = time.Hour
+
+// applySessionAffinityConfig enables or disables sticky session routing.
+// Re-applying drops existing bindings, so callers should only invoke it when settings change.
+func (s *Service) applySessionAffinityConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	settings := cfg.Routing.SessionAffinity
+	if !settings.Enabled {
+		s.coreManager.SetSessionAffinity(0)
+		return
+	}
+	ttl := defaultSessionAffinityTTL
+	if raw := strings.TrimSpace(settings.TTL); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil && parsed > 0 {
+			ttl = parsed
+		} else {
+			log.Warnf("invalid routing.session-affinity.ttl %q, using %s", raw, defaultSessionAffinityTTL)
+		}
+	}
+	s.coreManager.SetSessionAffinity(ttl)
+}
+
+// applyHealthProbeConfig starts, restarts or stops the background credential health probes.
+func (s *Service) applyHealthProbeConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	settings := cfg.HealthProbe
+	if !settings.Enabled {
+		s.coreManager.StopHealthProbes()
+		return
+	}
+	probe := coreauth.HealthProbeSettings{Models: make(map[string]string, len(settings.Models))}
+	for provider, model := range settings.Models {
+		probe.Models[strings.ToLower(strings.TrimSpace(provider))] = strings.TrimSpace(model)
+	}
+	if raw := strings.TrimSpace(settings.Interval); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil && parsed > 0 {
+			probe.Interval = parsed
+		} else {
+			log.Warnf("invalid health-probe.interval %q, using the default", raw)
+		}
+	}
+	if raw := strings.TrimSpace(settings.Timeout); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil && parsed > 0 {
+			probe.Timeout = parsed
+		} else {
+			log.Warnf("invalid health-probe.timeout %q, using the default", raw)
+		}
+	}
+	s.coreManager.StartHealthProbes(context.Background(), probe)
+}
+
+// applyRateLimitHeadroom sets when provider reported rate limit windows take a credential out of
+// rotation.
+func applyRateLimitHeadroom(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	headroom := cfg.Routing.RateLimitHeadroom
+	coreauth.SetRateLimitThreshold(coreauth.RateLimitThreshold{
+		MinRemaining:   headroom.MinRemaining,
+		MaxUsedPercent: headroom.MaxUsedPercent,
+	})
+}
+
+// applyConcurrencyConfig updates the per-provider concurrency caps and the wait queue settings.
+// In-flight counts are kept, so it is safe to re-apply on every reload.
+func (s *Service) applyConcurrencyConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	settings := cfg.Routing.Concurrency
+	var timeout time.Duration
+	if raw := strings.TrimSpace(settings.QueueTimeout); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil && parsed > 0 {
+			timeout = parsed
+		} else {
+			log.Warnf("invalid routing.concurrency.queue-timeout %q, using the default", raw)
+		}
+	}
+	s.coreManager.SetConcurrencyLimits(coreauth.ConcurrencySettings{
+		ProviderDefaults: settings.ProviderDefaults,
+		QueueSize:        settings.QueueSize,
+		QueueTimeout:     timeout,
+	})
+}
+
 func openAICompatInfoFromAuth(a *coreauth.Auth) (providerKey string, compatName string, ok bool) {
 	if a == nil {
 		return "", "", false
@@ -431,6 +539,11 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	s.applyCircuitBreakerConfig(s.cfg)
+	transport.Default().Reload(s.cfg.Transport)
+	s.applySessionAffinityConfig(s.cfg)
+	s.applyConcurrencyConfig(s.cfg)
+	applyRateLimitHeadroom(s.cfg)
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
@@ -507,9 +620,15 @@ func (s *Service) Run(ctx context.Context) error {
 	var watcherWrapper *WatcherWrapper
 	reloadCallback := func(newCfg *config.Config) {
 		previousStrategy := ""
+		var previousBreaker config.CircuitBreakerConfig
+		var previousAffinity config.SessionAffinityConfig
+		var previousProbe config.HealthProbeConfig
 		s.cfgMu.RLock()
 		if s.cfg != nil {
 			previousStrategy = strings.ToLower(strings.TrimSpace(s.cfg.Routing.Strategy))
+			previousBreaker = s.cfg.CircuitBreaker
+			previousAffinity = s.cfg.Routing.SessionAffinity
+			previousProbe = s.cfg.HealthProbe
 		}
 		s.cfgMu.RUnlock()
 
@@ -527,6 +646,10 @@ func (s *Service) Run(ctx context.Context) error {
 			switch strategy {
 			case "fill-first", "fillfirst", "ff":
 				return "fill-first"
+			case "least-loaded", "leastloaded", "least-load", "ll":
+				return "least-loaded"
+			case "weighted", "weighted-random", "wr":
+				return "weighted"
 			default:
 				return "round-robin"
 			}
@@ -538,6 +661,10 @@ func (s *Service) Run(ctx context.Context) error {
 			switch nextStrategy {
 			case "fill-first":
 				selector = &coreauth.FillFirstSelector{}
+			case "least-loaded":
+				selector = &coreauth.LeastLoadedSelector{}
+			case "weighted":
+				selector = &coreauth.WeightedSelector{}
 			default:
 				selector = &coreauth.RoundRobinSelector{}
 			}
@@ -546,6 +673,22 @@ func (s *Service) Run(ctx context.Context) error {
 		}
 
 		s.applyRetryConfig(newCfg)
+		// Reload drops transports whose settings changed or that went unused since the last reload.
+		transport.Default().Reload(newCfg.Transport)
+		if previousBreaker != newCfg.CircuitBreaker {
+			s.applyCircuitBreakerConfig(newCfg)
+			log.Infof("circuit breaker settings updated (enabled=%t)", newCfg.CircuitBreaker.Enabled)
+		}
+		if previousAffinity != newCfg.Routing.SessionAffinity {
+			s.applySessionAffinityConfig(newCfg)
+			log.Infof("session affinity settings updated (enabled=%t)", newCfg.Routing.SessionAffinity.Enabled)
+		}
+		s.applyConcurrencyConfig(newCfg)
+		applyRateLimitHeadroom(newCfg)
+		if !reflect.DeepEqual(previousProbe, newCfg.HealthProbe) {
+			s.applyHealthProbeConfig(newCfg)
+			log.Infof("health probe settings updated (enabled=%t)", newCfg.HealthProbe.Enabled)
+		}
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -581,6 +724,9 @@ func (s *Service) Run(ctx context.Context) error {
 		interval := 15 * time.Minute
 		s.coreManager.StartAutoRefresh(context.Background(), interval)
 		log.Infof("core auth auto-refresh started (interval=%s)", interval)
+		s.cfgMu.RLock()
+		s.applyHealthProbeConfig(s.cfg)
+		s.cfgMu.RUnlock()
 	}
 
 	select {
@@ -618,6 +764,7 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		}
 		if s.coreManager != nil {
 			s.coreManager.StopAutoRefresh()
+			s.coreManager.StopHealthProbes()
 		}
 		if s.watcher != nil {
 			if err := s.watcher.Stop(); err != nil {
@@ -708,7 +855,8 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 	var models []*ModelInfo
 	switch provider {
 	case "gemini":
-		models = registry.GetGeminiModels()
+		// Embedding models are registered for /v1/embeddings routing next to the chat models.
+		models = append(registry.GetGeminiModels(), registry.GetGeminiEmbeddingModels()...)
 		if entry := s.resolveConfigGeminiKey(a); entry != nil {
 			if len(entry.Models) > 0 {
 				models = buildGeminiConfigModels(entry)
@@ -720,7 +868,7 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 		models = applyExcludedModels(models, excluded)
 	case "vertex":
 		// Vertex AI Gemini supports the same model identifiers as Gemini.
-		models = registry.GetGeminiVertexModels()
+		models = append(registry.GetGeminiVertexModels(), registry.GetGeminiVertexEmbeddingModels()...)
 		if authKind == "apikey" {
 			if entry := s.resolveConfigVertexCompatKey(a); entry != nil && len(entry.Models) > 0 {
 				models = buildVertexCompatConfigModels(entry)
@@ -1021,7 +1169,7 @@ func applyExcludedModels(models []*ModelInfo, excluded []string) []*ModelInfo {
 		modelID := strings.ToLower(strings.TrimSpace(model.ID))
 		blocked := false
 		for _, pattern := range patterns {
-			if matchWildcard(pattern, modelID) {
+			if util.MatchWildcard(pattern, modelID) {
 				blocked = true
 				break
 			}
@@ -1075,50 +1223,6 @@ func applyModelPrefixes(models []*ModelInfo, prefix string, forceModelPrefix boo
 	return out
 }
 
-// matchWildcard performs case-insensitive wildcard matching where '*' matches any substring.
-func matchWildcard(pattern, value string) bool {
-	if pattern == "" {
-		return false
-	}
-
-	// Fast path for exact match (no wildcard present).
-	if !strings.Contains(pattern, "*") {
-		return pattern == value
-	}
-
-	parts := strings.Split(pattern, "*")
-	// Handle prefix.
-	if prefix := parts[0]; prefix != "" {
-		if !strings.HasPrefix(value, prefix) {
-			return false
-		}
-		value = value[len(prefix):]
-	}
-
-	// Handle suffix.
-	if suffix := parts[len(parts)-1]; suffix != "" {
-		if !strings.HasSuffix(value, suffix) {
-			return false
-		}
-		value = value[:len(value)-len(suffix)]
-	}
-
-	// Handle middle segments in order.
-	for i := 1; i < len(parts)-1; i++ {
-		segment := parts[i]
-		if segment == "" {
-			continue
-		}
-		idx := strings.Index(value, segment)
-		if idx < 0 {
-			return false
-		}
-		value = value[idx+len(segment):]
-	}
-
-	return true
-}
-
 type modelEntry interface {
 	GetName() string
 	GetAlias() string
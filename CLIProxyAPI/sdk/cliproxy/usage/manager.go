@@ -104,9 +104,10 @@ func (m *Manager) Register(plugin Plugin) {
 }
 
 // Publish enqueues a usage record for processing. If no plugin is registered
-// the record will be discarded downstream.
+// the record will be discarded downstream. Records published with a context
+// marked by WithoutRecording are dropped.
 func (m *Manager) Publish(ctx context.Context, record Record) {
-	if m == nil {
+	if m == nil || recordingDisabled(ctx) {
 		return
 	}
 	// ensure worker is running even if Start was not called explicitly
@@ -163,6 +164,22 @@ func safeInvoke(plugin Plugin, ctx context.Context, record Record) {
 	plugin.HandleUsage(ctx, record)
 }
 
+type withoutRecordingKey struct{}
+
+// WithoutRecording marks ctx so that usage records published with it are dropped. It keeps
+// synthetic traffic, such as credential health probes, out of the request statistics.
+func WithoutRecording(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutRecordingKey{}, true)
+}
+
+func recordingDisabled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	disabled, _ := ctx.Value(withoutRecordingKey{}).(bool)
+	return disabled
+}
+
 var defaultManager = NewManager(512)
 
 // DefaultManager returns the global usage manager instance.
@@ -20,6 +20,17 @@ type ModelNameMapping = internalconfig.ModelNameMapping
 type PayloadConfig = internalconfig.PayloadConfig
 type PayloadRule = internalconfig.PayloadRule
 type PayloadModelRule = internalconfig.PayloadModelRule
+type CircuitBreakerConfig = internalconfig.CircuitBreakerConfig
+type ModelFallback = internalconfig.ModelFallback
+type RoutingConfig = internalconfig.RoutingConfig
+type SessionAffinityConfig = internalconfig.SessionAffinityConfig
+type HealthProbeConfig = internalconfig.HealthProbeConfig
+type TransportConfig = internalconfig.TransportConfig
+type ClientAPIKey = internalconfig.ClientAPIKey
+type DBAPIKeyConfig = internalconfig.DBAPIKeyConfig
+type JWTAccessConfig = internalconfig.JWTAccessConfig
+type OIDCAccessConfig = internalconfig.OIDCAccessConfig
+type MTLSAccessConfig = internalconfig.MTLSAccessConfig
 
 type GeminiKey = internalconfig.GeminiKey
 type CodexKey = internalconfig.CodexKey
@@ -34,6 +45,10 @@ type TLS = internalconfig.TLSConfig
 
 const (
 	AccessProviderTypeConfigAPIKey = internalconfig.AccessProviderTypeConfigAPIKey
+	AccessProviderTypeDBAPIKey     = internalconfig.AccessProviderTypeDBAPIKey
+	AccessProviderTypeJWT          = internalconfig.AccessProviderTypeJWT
+	AccessProviderTypeOIDC         = internalconfig.AccessProviderTypeOIDC
+	AccessProviderTypeMTLS         = internalconfig.AccessProviderTypeMTLS
 	DefaultAccessProviderName      = internalconfig.DefaultAccessProviderName
 	DefaultPanelGitHubRepository   = internalconfig.DefaultPanelGitHubRepository
 )
@@ -42,6 +57,14 @@ func MakeInlineAPIKeyProvider(keys []string) *AccessProvider {
 	return internalconfig.MakeInlineAPIKeyProvider(keys)
 }
 
+func MakeDBAPIKeyProvider(cfg DBAPIKeyConfig) *AccessProvider {
+	return internalconfig.MakeDBAPIKeyProvider(cfg)
+}
+
+func MakeOIDCAccessProvider(cfg OIDCAccessConfig) *AccessProvider {
+	return internalconfig.MakeOIDCAccessProvider(cfg)
+}
+
 func LoadConfig(configFile string) (*Config, error) { return internalconfig.LoadConfig(configFile) }
 
 func LoadConfigOptional(configFile string, optional bool) (*Config, error) {